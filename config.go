@@ -0,0 +1,103 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// envPrefix starts the environment variables that set options, e.g.
+// REQPARSER_PORT for -port.
+const envPrefix = "REQPARSER_"
+
+// configOnlyFlags cannot be set from a config file.
+var configOnlyFlags = map[string]bool{"config": true, "version": true}
+
+// loadConfig reads a YAML config file mapping names of flags in fs, without
+// the leading dash, to their values. Lists are joined with commas. Keys that
+// are not flags are an error, to catch typos.
+func loadConfig(fs *flag.FlagSet, path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var raw map[string]interface{}
+	if err := yaml.NewDecoder(f).Decode(&raw); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(raw))
+	for key, value := range raw {
+		if configOnlyFlags[key] || fs.Lookup(key) == nil {
+			return nil, fmt.Errorf("unknown option %q", key)
+		}
+		switch v := value.(type) {
+		case nil:
+			return nil, fmt.Errorf("option %q has no value", key)
+		case map[string]interface{}:
+			return nil, fmt.Errorf("option %q must be a single value or a list", key)
+		case []interface{}:
+			items := make([]string, len(v))
+			for i, item := range v {
+				items[i] = fmt.Sprint(item)
+			}
+			values[key] = strings.Join(items, ",")
+		default:
+			values[key] = fmt.Sprint(v)
+		}
+	}
+	return values, nil
+}
+
+// envConfig collects the options set through REQPARSER_ environment
+// variables. Dashes in flag names become underscores, so -rust-derives is
+// REQPARSER_RUST_DERIVES. REQPARSER_CONFIG names a config file.
+func envConfig(fs *flag.FlagSet) map[string]string {
+	values := make(map[string]string)
+	fs.VisitAll(func(f *flag.Flag) {
+		if f.Name == "version" {
+			return
+		}
+		name := envPrefix + strings.ToUpper(strings.ReplaceAll(f.Name, "-", "_"))
+		if value, ok := os.LookupEnv(name); ok {
+			values[f.Name] = value
+		}
+	})
+	return values
+}
+
+// applyConfig sets every flag that has not been set yet to its configured
+// value. Applying sources from the most to the least important gives command
+// line flags precedence over the environment, and both over config files.
+func applyConfig(fs *flag.FlagSet, values map[string]string) error {
+	// Visit covers flags given on the command line and set by earlier
+	// sources
+	explicit := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = true
+	})
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if explicit[name] {
+			continue
+		}
+		if err := fs.Set(name, values[name]); err != nil {
+			return fmt.Errorf("option %q: %v", name, err)
+		}
+	}
+	return nil
+}
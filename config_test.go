@@ -0,0 +1,182 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testFlags defines a few flags like those of main, to load config into.
+func testFlags() *flag.FlagSet {
+	fs := flag.NewFlagSet("reqparser", flag.ContinueOnError)
+	fs.Int("port", 8080, "")
+	fs.String("format", "", "")
+	fs.Bool("pretty", false, "")
+	fs.String("paths", "", "")
+	fs.String("config", "", "")
+	fs.Bool("version", false, "")
+	return fs
+}
+
+func TestLoadConfig(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		expected  map[string]string
+		expectErr string
+	}{
+		{
+			name:     "Valid file",
+			content:  "port: 9090\nformat: rust\npretty: true\npaths:\n  - /api/*\n  - /hooks\n",
+			expected: map[string]string{"port": "9090", "format": "rust", "pretty": "true", "paths": "/api/*,/hooks"},
+		},
+		{
+			name:     "Empty file",
+			content:  "",
+			expected: map[string]string{},
+		},
+		{
+			name:      "Unknown key",
+			content:   "port: 9090\nfromat: rust\n",
+			expectErr: `unknown option "fromat"`,
+		},
+		{
+			name:      "Config only flag",
+			content:   "version: true\n",
+			expectErr: `unknown option "version"`,
+		},
+		{
+			name:      "Missing value",
+			content:   "format:\n",
+			expectErr: `option "format" has no value`,
+		},
+		{
+			name:      "Nested value",
+			content:   "format:\n  name: rust\n",
+			expectErr: `option "format" must be a single value or a list`,
+		},
+		{
+			name:      "Invalid YAML",
+			content:   "port: [9090\n",
+			expectErr: "yaml:",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "reqparser.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			values, err := loadConfig(testFlags(), path)
+			if tt.expectErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectErr) {
+					t.Fatalf("loadConfig() error = %v, want %q", err, tt.expectErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("loadConfig() error = %v", err)
+			}
+			if !reflect.DeepEqual(values, tt.expected) {
+				t.Errorf("loadConfig() = %v, want %v", values, tt.expected)
+			}
+		})
+	}
+
+	if _, err := loadConfig(testFlags(), filepath.Join(t.TempDir(), "missing.yaml")); !os.IsNotExist(err) {
+		t.Errorf("loadConfig() of a missing file error = %v, want not exist", err)
+	}
+}
+
+func TestApplyConfig(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		env       map[string]string
+		file      map[string]string
+		expected  map[string]string
+		expectErr string
+	}{
+		{
+			name:     "Defaults",
+			expected: map[string]string{"port": "8080", "format": "", "pretty": "false"},
+		},
+		{
+			name:     "File sets unset flags",
+			file:     map[string]string{"port": "9090", "format": "rust"},
+			expected: map[string]string{"port": "9090", "format": "rust"},
+		},
+		{
+			name:     "Environment beats the file",
+			env:      map[string]string{"REQPARSER_PORT": "7070"},
+			file:     map[string]string{"port": "9090", "format": "rust"},
+			expected: map[string]string{"port": "7070", "format": "rust"},
+		},
+		{
+			name:     "Flags beat the environment and the file",
+			args:     []string{"-port", "6060", "-pretty"},
+			env:      map[string]string{"REQPARSER_PORT": "7070", "REQPARSER_PRETTY": "false"},
+			file:     map[string]string{"port": "9090"},
+			expected: map[string]string{"port": "6060", "pretty": "true"},
+		},
+		{
+			name:     "Flags set to their defaults still win",
+			args:     []string{"-format="},
+			env:      map[string]string{"REQPARSER_FORMAT": "go"},
+			file:     map[string]string{"format": "rust"},
+			expected: map[string]string{"format": ""},
+		},
+		{
+			name:     "Version is not read from the environment",
+			env:      map[string]string{"REQPARSER_VERSION": "true"},
+			expected: map[string]string{"version": "false"},
+		},
+		{
+			name:      "Bad environment value",
+			env:       map[string]string{"REQPARSER_PORT": "eighty"},
+			expectErr: `option "port": parse error`,
+		},
+		{
+			name:      "Bad file value",
+			file:      map[string]string{"pretty": "maybe"},
+			expectErr: `option "pretty": parse error`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			fs := testFlags()
+			if err := fs.Parse(tt.args); err != nil {
+				t.Fatal(err)
+			}
+
+			// Apply the sources in the order main does
+			err := applyConfig(fs, envConfig(fs))
+			if err == nil {
+				err = applyConfig(fs, tt.file)
+			}
+			if tt.expectErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectErr) {
+					t.Fatalf("applyConfig() error = %v, want %q", err, tt.expectErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyConfig() error = %v", err)
+			}
+			for name, want := range tt.expected {
+				if got := fs.Lookup(name).Value.String(); got != want {
+					t.Errorf("-%s = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
@@ -4,20 +4,98 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"go/token"
+	"io"
 	"log"
 	"os"
 	"os/signal"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 	"syscall"
+	"text/tabwriter"
+	"time"
 
 	"github.com/stackloklabs/reqparser/server"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 )
 
 var (
-	port        = flag.Int("port", 8080, "Port to run the server on")
-	formatType  = flag.String("format", "", "Output format type (go, rust) - if not provided, no struct will be generated")
-	pretty      = flag.Bool("pretty", false, "Pretty print JSON with delimiters")
-	headers     = flag.Bool("headers", false, "Show HTTP headers in output")
-	showVersion = flag.Bool("version", false, "Show version information")
+	port                 = flag.String("port", "8080", "Port to run the server on, or a comma-separated list of ports")
+	formatType           = flag.String("format", "", "Output format type ("+strings.Join(server.FormatNames(), ", ")+") - if not provided, no struct will be generated")
+	pretty               = flag.Bool("pretty", false, "Pretty print JSON with delimiters")
+	headers              = flag.Bool("headers", false, "Show HTTP headers in output")
+	quiet                = flag.Bool("quiet", false, "Suppress informational logs, keeping only the JSON and struct output")
+	httpFile             = flag.String("http-file", "", "Process a raw HTTP request saved in a file instead of starting the server")
+	readTimeout          = flag.Duration("read-timeout", 30*time.Second, "Maximum duration for reading an entire request")
+	writeTimeout         = flag.Duration("write-timeout", 30*time.Second, "Maximum duration for writing a response")
+	showVersion          = flag.Bool("version", false, "Show version information")
+	detectUUID           = flag.Bool("detect-uuid", false, "Type UUID strings as uuid.UUID (Go) and uuid::Uuid (Rust)")
+	detectEnums          = flag.Bool("detect-enums", false, "Generate enums for string fields with few distinct values")
+	color                = flag.Bool("color", false, "Colorize generated Go and Rust structs (disabled when not a terminal or NO_COLOR is set)")
+	indent               = flag.String("indent", "4", "Indentation for pretty printed JSON (number of spaces, or tab)")
+	formatHeaders        = flag.Bool("format-headers", false, "Also generate a struct for the request headers (requires -format)")
+	generatedComment     = flag.Bool("generated-comment", false, "Start generated code with a \"Code generated ... DO NOT EDIT.\" comment")
+	detectBase64         = flag.Bool("detect-base64", false, "Type base64 encoded binary strings as []byte (Go) and Vec<u8> (Rust)")
+	pointers             = flag.Bool("pointers", false, "Make every generated Go field a pointer with omitempty")
+	rustDerives          = flag.String("rust-derives", "", "Comma-separated derives to add to generated Rust structs (e.g. Clone,PartialEq,Default)")
+	rustPub              = flag.Bool("rust-pub", false, "Make generated Rust structs and fields pub")
+	maxDepth             = flag.Int("max-depth", 64, "Maximum nesting of arrays and objects to type before falling back to a catch-all type (0 for no limit)")
+	nestedNaming         = flag.String("nested-naming", "key", "Naming of nested types: key (Address) or path (GeneratedStructAddress)")
+	otelEnabled          = flag.Bool("otel", false, "Export an OpenTelemetry trace span per request over OTLP/HTTP")
+	otelEndpoint         = flag.String("otel-endpoint", "localhost:4318", "OTLP/HTTP endpoint (host:port) that -otel exports spans to")
+	rateLimit            = flag.Float64("rate-limit", 0, "Maximum requests per second before replying 429 Too Many Requests (0 for no limit)")
+	rateLimitPerIP       = flag.Bool("rate-limit-per-ip", false, "Apply -rate-limit to each remote IP separately")
+	jsonLiteral          = flag.String("json", "", "Print the struct generated for a JSON literal and exit (requires -format)")
+	openAPISpec          = flag.Bool("openapi-spec", false, "Accumulate an OpenAPI document of every request seen, served at /openapi.json")
+	coerceNumericStrings = flag.Bool("coerce-numeric-strings", false, "Type strings holding numbers, such as \"42\", as numbers read from strings")
+	configPath           = flag.String("config", "", "Read options from a YAML file (flags given on the command line take precedence)")
+	concurrency          = flag.Int("concurrency", 0, "Maximum number of requests formatted at once; others wait briefly, then get 503 (0 for no limit)")
+	cacheSize            = flag.Int("cache-size", 0, "Number of distinct bodies whose generated code is cached (0 to disable)")
+	rubySorbet           = flag.Bool("ruby-sorbet", false, "Generate Sorbet T::Struct classes for -format ruby")
+	comments             = flag.Bool("comments", false, "End generated Go and Rust fields with a comment showing an example value")
+	h2cEnabled           = flag.Bool("h2c", false, "Accept HTTP/2 over cleartext (h2c) alongside HTTP/1.1")
+	once                 = flag.Bool("once", false, "Exit after the first successfully processed request")
+	detectDurations      = flag.Bool("detect-durations", false, "Type duration strings such as 5m30s as a time.Duration wrapper (Go) and std::time::Duration (Rust)")
+	kotlinStyle          = flag.String("kotlin-style", "kotlinx", "Kotlin annotations: kotlinx (@Serializable, @SerialName), jackson (@JsonProperty) or moshi (@Json)")
+	maxBodySize          = flag.Int64("max-body-size", 0, "Maximum request body size in bytes, counted as the body is read so chunked bodies are capped too (0 for no limit)")
+	flatten              = flag.Bool("flatten", false, "Inline nested objects in Go output as anonymous structs instead of named types")
+	outPath              = flag.String("out", "", "Write the code generated for each request to this file, replacing it (requires -format)")
+	appendOut            = flag.Bool("append", false, "With -out, append the code of each distinct body shape instead of replacing the file")
+	repl                 = flag.Bool("repl", false, "Read JSON values from stdin and print the code generated for each, without starting the server")
+	goTags               = flag.String("go-tags", "json", "Comma-separated struct tag keys to write for every Go field (e.g. json,bson,yaml)")
+	narrowInts           = flag.Bool("narrow-ints", false, "Type whole number fields in Go and Rust as int32/i32 when every value fits, int64/i64 otherwise")
+	listFormats          = flag.Bool("list-formats", false, "List the output formats with a description of each, then exit")
+	validate             = flag.Bool("validate", false, "Report the inferred schema of each body, with warnings about ambiguous types, instead of generating code")
+	gzipResponses        = flag.Bool("gzip", false, "Compress responses of at least 1 KiB for clients that send Accept-Encoding: gzip")
+	maxFields            = flag.Int("max-fields", 10000, "Maximum number of fields across the generated types of one body before it is refused (0 for no limit)")
+	meta                 = flag.Bool("meta", false, "Add a meta object with field, depth, optional field and byte counts to the JSON acknowledgement")
+	inFiles              = flag.String("in", "", "Comma-separated JSON files or globs to merge into one generated type, printed without starting the server (requires -format)")
+	verbose              = flag.Bool("verbose", false, "Log extra detail, such as the -in files each field was seen in")
+	numberType           = flag.String("number-type", "", "Type every number field as this type instead of inferring it: int, int32, int64, uint64, float32, float64 or json.Number for Go, i32, i64, u64, f32, f64 or serde_json::Number for Rust")
+	logFile              = flag.String("log-file", "", "Also write everything logged, such as requests and generated structs, to this file")
+	logMaxSize           = flag.Int("log-max-size", 10, "Megabytes a -log-file or -capture file may reach before it is rotated, or 0 to never rotate")
+	strictKeys           = flag.Bool("strict-keys", false, "Refuse JSON bodies that repeat a key within an object with 400 instead of logging a warning")
+	pathPatterns         = flag.String("paths", "", "Comma-separated path patterns, such as /api/*, of the requests to process; others are only acknowledged")
+	websocketEnabled     = flag.Bool("ws", false, "Serve a /ws WebSocket endpoint replying to each JSON message with the generated code (requires -format)")
+	annotateTypes        = flag.Bool("annotate-types", false, "Also log each JSON body with a comment naming the type of every leaf value")
+	typeOverride         = flag.String("type-override", "", "Comma-separated path=type pairs forcing the Go or Rust type of fields, such as user_id=int64,owner.created_at=time.Time")
+	goAny                = flag.Bool("go-any", false, "Write any instead of interface{} for untyped Go values (Go 1.18+)")
+	detectNet            = flag.Bool("detect-net", false, "Type IP address strings as net.IP (Go) and std::net::IpAddr (Rust), and CIDR strings as *net.IPNet and ipnet::IpNet")
+	watchDir             = flag.String("watch", "", "Watch a directory of JSON fixtures and regenerate the code of each as it changes, written next to it as name_gen.<ext> (requires -format)")
+	goPackage            = flag.String("package", "", "Start Go output with a package clause naming the package and an import block for the packages its field types use")
+	graphqlKind          = flag.String("graphql-kind", "type", "GraphQL declarations: type (object types) or input (input types for mutation arguments)")
+	replayFiles          = flag.String("replay", "", "Comma-separated NDJSON captures or globs, such as those of -capture, whose records are merged into one generated type, printed without starting the server (requires -format)")
+	graphqlScalars       = flag.String("graphql-scalars", "", "Comma-separated kind=Scalar mappings typing detected dates, ids or UUIDs in GraphQL output with custom scalars, such as date=DateTime,id=ID")
+	captureFile          = flag.String("capture", "", "Append the records of every JSON body received to this file as NDJSON, one per line, for -replay")
+	strictDepth          = flag.Bool("strict-depth", false, "Refuse bodies nested deeper than -max-depth with 400 instead of typing the deeper values with a catch-all type")
 )
 
 const version = "0.1.0"
@@ -27,16 +105,142 @@ func main() {
 		fmt.Fprintf(os.Stderr, "Usage of reqparser:\n")
 		fmt.Fprintf(os.Stderr, "\nreqparser is a HTTP request parsing and formatting tool\n\n")
 		fmt.Fprintf(os.Stderr, "Options:\n")
-		fmt.Fprintf(os.Stderr, "  -port int\n")
-		fmt.Fprintf(os.Stderr, "        Port to run the server on (default 8080)\n")
+		fmt.Fprintf(os.Stderr, "  -port string\n")
+		fmt.Fprintf(os.Stderr, "        Port to run the server on, or a comma-separated list of ports (default \"8080\")\n")
 		fmt.Fprintf(os.Stderr, "  -format string\n")
-		fmt.Fprintf(os.Stderr, "        Output format type (go, rust) - if not provided, no struct will be generated\n")
+		fmt.Fprintf(os.Stderr, "        Output format type (%s) - if not provided, no struct will be generated\n", strings.Join(server.FormatNames(), ", "))
 		fmt.Fprintf(os.Stderr, "  -pretty\n")
 		fmt.Fprintf(os.Stderr, "        Pretty print JSON with delimiters (if not provided, shows compact JSON-Body)\n")
 		fmt.Fprintf(os.Stderr, "  -headers\n")
 		fmt.Fprintf(os.Stderr, "        Show HTTP headers in output\n")
+		fmt.Fprintf(os.Stderr, "  -read-timeout duration\n")
+		fmt.Fprintf(os.Stderr, "        Maximum duration for reading an entire request (default 30s)\n")
+		fmt.Fprintf(os.Stderr, "  -write-timeout duration\n")
+		fmt.Fprintf(os.Stderr, "        Maximum duration for writing a response (default 30s)\n")
+		fmt.Fprintf(os.Stderr, "  -http-file string\n")
+		fmt.Fprintf(os.Stderr, "        Process a raw HTTP request saved in a file instead of starting the server\n")
+		fmt.Fprintf(os.Stderr, "  -quiet\n")
+		fmt.Fprintf(os.Stderr, "        Suppress informational logs, keeping only the JSON and struct output\n")
 		fmt.Fprintf(os.Stderr, "  -version\n")
 		fmt.Fprintf(os.Stderr, "        Show version information\n")
+		fmt.Fprintf(os.Stderr, "  -color\n")
+		fmt.Fprintf(os.Stderr, "        Colorize generated Go and Rust structs (disabled when not a terminal or NO_COLOR is set)\n")
+		fmt.Fprintf(os.Stderr, "  -indent string\n")
+		fmt.Fprintf(os.Stderr, "        Indentation for pretty printed JSON (number of spaces, or tab) (default \"4\")\n")
+		fmt.Fprintf(os.Stderr, "  -detect-uuid\n")
+		fmt.Fprintf(os.Stderr, "        Type UUID strings as uuid.UUID (Go) and uuid::Uuid (Rust)\n")
+		fmt.Fprintf(os.Stderr, "  -detect-enums\n")
+		fmt.Fprintf(os.Stderr, "        Generate enums for string fields with few distinct values\n")
+		fmt.Fprintf(os.Stderr, "  -format-headers\n")
+		fmt.Fprintf(os.Stderr, "        Also generate a struct for the request headers (requires -format)\n")
+		fmt.Fprintf(os.Stderr, "  -generated-comment\n")
+		fmt.Fprintf(os.Stderr, "        Start generated code with a \"Code generated ... DO NOT EDIT.\" comment\n")
+		fmt.Fprintf(os.Stderr, "  -detect-base64\n")
+		fmt.Fprintf(os.Stderr, "        Type base64 encoded binary strings as []byte (Go) and Vec<u8> (Rust)\n")
+		fmt.Fprintf(os.Stderr, "  -pointers\n")
+		fmt.Fprintf(os.Stderr, "        Make every generated Go field a pointer with omitempty\n")
+		fmt.Fprintf(os.Stderr, "  -rust-derives string\n")
+		fmt.Fprintf(os.Stderr, "        Comma-separated derives to add to generated Rust structs (e.g. Clone,PartialEq,Default)\n")
+		fmt.Fprintf(os.Stderr, "  -rust-pub\n")
+		fmt.Fprintf(os.Stderr, "        Make generated Rust structs and fields pub\n")
+		fmt.Fprintf(os.Stderr, "  -max-depth int\n")
+		fmt.Fprintf(os.Stderr, "        Maximum nesting of arrays and objects to type before falling back to a catch-all type (0 for no limit) (default 64)\n")
+		fmt.Fprintf(os.Stderr, "  -nested-naming string\n")
+		fmt.Fprintf(os.Stderr, "        Naming of nested types: key (Address) or path (GeneratedStructAddress) (default \"key\")\n")
+		fmt.Fprintf(os.Stderr, "  -otel\n")
+		fmt.Fprintf(os.Stderr, "        Export an OpenTelemetry trace span per request over OTLP/HTTP\n")
+		fmt.Fprintf(os.Stderr, "  -otel-endpoint string\n")
+		fmt.Fprintf(os.Stderr, "        OTLP/HTTP endpoint (host:port) that -otel exports spans to (default \"localhost:4318\")\n")
+		fmt.Fprintf(os.Stderr, "  -rate-limit float\n")
+		fmt.Fprintf(os.Stderr, "        Maximum requests per second before replying 429 Too Many Requests (0 for no limit)\n")
+		fmt.Fprintf(os.Stderr, "  -rate-limit-per-ip\n")
+		fmt.Fprintf(os.Stderr, "        Apply -rate-limit to each remote IP separately\n")
+		fmt.Fprintf(os.Stderr, "  -json string\n")
+		fmt.Fprintf(os.Stderr, "        Print the struct generated for a JSON literal and exit (requires -format)\n")
+		fmt.Fprintf(os.Stderr, "  -openapi-spec\n")
+		fmt.Fprintf(os.Stderr, "        Accumulate an OpenAPI document of every request seen, served at /openapi.json\n")
+		fmt.Fprintf(os.Stderr, "  -coerce-numeric-strings\n")
+		fmt.Fprintf(os.Stderr, "        Type strings holding numbers, such as \"42\", as numbers read from strings\n")
+		fmt.Fprintf(os.Stderr, "  -config string\n")
+		fmt.Fprintf(os.Stderr, "        Read options from a YAML file (flags given on the command line take precedence)\n")
+		fmt.Fprintf(os.Stderr, "  -concurrency int\n")
+		fmt.Fprintf(os.Stderr, "        Maximum number of requests formatted at once; others wait briefly, then get 503 (0 for no limit)\n")
+		fmt.Fprintf(os.Stderr, "  -cache-size int\n")
+		fmt.Fprintf(os.Stderr, "        Number of distinct bodies whose generated code is cached (0 to disable)\n")
+		fmt.Fprintf(os.Stderr, "  -ruby-sorbet\n")
+		fmt.Fprintf(os.Stderr, "        Generate Sorbet T::Struct classes for -format ruby\n")
+		fmt.Fprintf(os.Stderr, "  -comments\n")
+		fmt.Fprintf(os.Stderr, "        End generated Go and Rust fields with a comment showing an example value\n")
+		fmt.Fprintf(os.Stderr, "  -h2c\n")
+		fmt.Fprintf(os.Stderr, "        Accept HTTP/2 over cleartext (h2c) alongside HTTP/1.1\n")
+		fmt.Fprintf(os.Stderr, "  -once\n")
+		fmt.Fprintf(os.Stderr, "        Exit after the first successfully processed request\n")
+		fmt.Fprintf(os.Stderr, "  -detect-durations\n")
+		fmt.Fprintf(os.Stderr, "        Type duration strings such as 5m30s as a time.Duration wrapper (Go) and std::time::Duration (Rust)\n")
+		fmt.Fprintf(os.Stderr, "  -kotlin-style string\n")
+		fmt.Fprintf(os.Stderr, "        Kotlin annotations: kotlinx (@Serializable, @SerialName), jackson (@JsonProperty) or moshi (@Json) (default \"kotlinx\")\n")
+		fmt.Fprintf(os.Stderr, "  -max-body-size int\n")
+		fmt.Fprintf(os.Stderr, "        Maximum request body size in bytes, counted as the body is read so chunked bodies are capped too (0 for no limit)\n")
+		fmt.Fprintf(os.Stderr, "  -flatten\n")
+		fmt.Fprintf(os.Stderr, "        Inline nested objects in Go output as anonymous structs instead of named types\n")
+		fmt.Fprintf(os.Stderr, "  -out string\n")
+		fmt.Fprintf(os.Stderr, "        Write the code generated for each request to this file, replacing it (requires -format)\n")
+		fmt.Fprintf(os.Stderr, "  -append\n")
+		fmt.Fprintf(os.Stderr, "        With -out, append the code of each distinct body shape instead of replacing the file\n")
+		fmt.Fprintf(os.Stderr, "  -repl\n")
+		fmt.Fprintf(os.Stderr, "        Read JSON values from stdin and print the code generated for each, without starting the server\n")
+		fmt.Fprintf(os.Stderr, "  -go-tags string\n")
+		fmt.Fprintf(os.Stderr, "        Comma-separated struct tag keys to write for every Go field (e.g. json,bson,yaml) (default \"json\")\n")
+		fmt.Fprintf(os.Stderr, "  -narrow-ints\n")
+		fmt.Fprintf(os.Stderr, "        Type whole number fields in Go and Rust as int32/i32 when every value fits, int64/i64 otherwise\n")
+		fmt.Fprintf(os.Stderr, "  -list-formats\n")
+		fmt.Fprintf(os.Stderr, "        List the output formats with a description of each, then exit\n")
+		fmt.Fprintf(os.Stderr, "  -validate\n")
+		fmt.Fprintf(os.Stderr, "        Report the inferred schema of each body, with warnings about ambiguous types, instead of generating code\n")
+		fmt.Fprintf(os.Stderr, "  -gzip\n")
+		fmt.Fprintf(os.Stderr, "        Compress responses of at least 1 KiB for clients that send Accept-Encoding: gzip\n")
+		fmt.Fprintf(os.Stderr, "  -max-fields int\n")
+		fmt.Fprintf(os.Stderr, "        Maximum number of fields across the generated types of one body before it is refused (0 for no limit) (default 10000)\n")
+		fmt.Fprintf(os.Stderr, "  -meta\n")
+		fmt.Fprintf(os.Stderr, "        Add a meta object with field, depth, optional field and byte counts to the JSON acknowledgement\n")
+		fmt.Fprintf(os.Stderr, "  -in string\n")
+		fmt.Fprintf(os.Stderr, "        Comma-separated JSON files or globs to merge into one generated type, printed without starting the server (requires -format)\n")
+		fmt.Fprintf(os.Stderr, "  -verbose\n")
+		fmt.Fprintf(os.Stderr, "        Log extra detail, such as the -in files each field was seen in\n")
+		fmt.Fprintf(os.Stderr, "  -number-type string\n")
+		fmt.Fprintf(os.Stderr, "        Type every number field as this type instead of inferring it: int, int32, int64, uint64, float32, float64 or json.Number for Go, i32, i64, u64, f32, f64 or serde_json::Number for Rust\n")
+		fmt.Fprintf(os.Stderr, "  -log-file string\n")
+		fmt.Fprintf(os.Stderr, "        Also write everything logged, such as requests and generated structs, to this file\n")
+		fmt.Fprintf(os.Stderr, "  -log-max-size int\n")
+		fmt.Fprintf(os.Stderr, "        Megabytes a -log-file or -capture file may reach before it is rotated, or 0 to never rotate (default 10)\n")
+		fmt.Fprintf(os.Stderr, "  -strict-keys\n")
+		fmt.Fprintf(os.Stderr, "        Refuse JSON bodies that repeat a key within an object with 400 instead of logging a warning\n")
+		fmt.Fprintf(os.Stderr, "  -paths string\n")
+		fmt.Fprintf(os.Stderr, "        Comma-separated path patterns, such as /api/*, of the requests to process; others are only acknowledged\n")
+		fmt.Fprintf(os.Stderr, "  -ws\n")
+		fmt.Fprintf(os.Stderr, "        Serve a /ws WebSocket endpoint replying to each JSON message with the generated code (requires -format)\n")
+		fmt.Fprintf(os.Stderr, "  -annotate-types\n")
+		fmt.Fprintf(os.Stderr, "        Also log each JSON body with a comment naming the type of every leaf value\n")
+		fmt.Fprintf(os.Stderr, "  -type-override string\n")
+		fmt.Fprintf(os.Stderr, "        Comma-separated path=type pairs forcing the Go or Rust type of fields, such as user_id=int64,owner.created_at=time.Time\n")
+		fmt.Fprintf(os.Stderr, "  -go-any\n")
+		fmt.Fprintf(os.Stderr, "        Write any instead of interface{} for untyped Go values (Go 1.18+)\n")
+		fmt.Fprintf(os.Stderr, "  -detect-net\n")
+		fmt.Fprintf(os.Stderr, "        Type IP address strings as net.IP (Go) and std::net::IpAddr (Rust), and CIDR strings as *net.IPNet and ipnet::IpNet\n")
+		fmt.Fprintf(os.Stderr, "  -watch string\n")
+		fmt.Fprintf(os.Stderr, "        Watch a directory of JSON fixtures and regenerate the code of each as it changes, written next to it as name_gen.<ext> (requires -format)\n")
+		fmt.Fprintf(os.Stderr, "  -package string\n")
+		fmt.Fprintf(os.Stderr, "        Start Go output with a package clause naming the package and an import block for the packages its field types use\n")
+		fmt.Fprintf(os.Stderr, "  -graphql-kind string\n")
+		fmt.Fprintf(os.Stderr, "        GraphQL declarations: type (object types) or input (input types for mutation arguments) (default \"type\")\n")
+		fmt.Fprintf(os.Stderr, "  -replay string\n")
+		fmt.Fprintf(os.Stderr, "        Comma-separated NDJSON captures or globs, such as those of -capture, whose records are merged into one generated type, printed without starting the server (requires -format)\n")
+		fmt.Fprintf(os.Stderr, "  -graphql-scalars string\n")
+		fmt.Fprintf(os.Stderr, "        Comma-separated kind=Scalar mappings typing detected dates, ids or UUIDs in GraphQL output with custom scalars, such as date=DateTime,id=ID\n")
+		fmt.Fprintf(os.Stderr, "  -capture string\n")
+		fmt.Fprintf(os.Stderr, "        Append the records of every JSON body received to this file as NDJSON, one per line, for -replay\n")
+		fmt.Fprintf(os.Stderr, "  -strict-depth\n")
+		fmt.Fprintf(os.Stderr, "        Refuse bodies nested deeper than -max-depth with 400 instead of typing the deeper values with a catch-all type\n")
 		fmt.Fprintf(os.Stderr, "\nBehavior:\n")
 		fmt.Fprintf(os.Stderr, "  - Without -format: Shows only JSON (pretty or compact)\n")
 		fmt.Fprintf(os.Stderr, "  - With -format: Shows struct and JSON (pretty or compact)\n")
@@ -44,29 +248,298 @@ func main() {
 		fmt.Fprintf(os.Stderr, "  - Without -pretty: Shows compact JSON-Body\n")
 		fmt.Fprintf(os.Stderr, "  - With -headers: Shows HTTP headers\n")
 		fmt.Fprintf(os.Stderr, "  - Without -headers: Headers are hidden\n")
+		fmt.Fprintf(os.Stderr, "\nEnvironment:\n")
+		fmt.Fprintf(os.Stderr, "  Every option can be set with a REQPARSER_ variable, dashes becoming\n")
+		fmt.Fprintf(os.Stderr, "  underscores: REQPARSER_PORT=9090, REQPARSER_RUST_DERIVES=Clone,PartialEq\n")
+		fmt.Fprintf(os.Stderr, "  Precedence: command line flags > environment > -config file > defaults\n")
 	}
 
 	flag.Parse()
 
+	if err := applyConfig(flag.CommandLine, envConfig(flag.CommandLine)); err != nil {
+		log.Fatalf("Error in environment: %v", err)
+	}
+	if *configPath != "" {
+		values, err := loadConfig(flag.CommandLine, *configPath)
+		if err != nil {
+			log.Fatalf("Error reading config %s: %v", *configPath, err)
+		}
+		if err := applyConfig(flag.CommandLine, values); err != nil {
+			log.Fatalf("Error in config %s: %v", *configPath, err)
+		}
+	}
+
 	if *showVersion {
 		fmt.Printf("reqparser version %s\n", version)
 		return
 	}
 
-	// Validate format type if provided
-	if *formatType != "" {
-		validFormats := map[string]bool{
-			"go":   true,
-			"rust": true,
+	if *listFormats {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, f := range server.Formats() {
+			fmt.Fprintf(w, "%s\t%s\n", f.Name, f.Description)
+		}
+		w.Flush()
+		return
+	}
+
+	// Only a server run opens files, so -version and -list-formats leave
+	// them untouched
+	if *logFile != "" {
+		file, err := server.OpenRotatingFile(*logFile, int64(*logMaxSize)<<20)
+		if err != nil {
+			log.Fatalf("Error opening log file: %v", err)
+		}
+		defer file.Close()
+		log.SetOutput(io.MultiWriter(os.Stderr, file))
+	}
+
+	var capture io.Writer
+	if *captureFile != "" {
+		file, err := server.OpenRotatingFile(*captureFile, int64(*logMaxSize)<<20)
+		if err != nil {
+			log.Fatalf("Error opening capture file: %v", err)
+		}
+		defer file.Close()
+		capture = file
+	}
+
+	if *formatType != "" && !server.IsFormat(*formatType) {
+		log.Fatalf("Invalid format type: %s. Valid formats are: %s", *formatType, strings.Join(server.FormatNames(), ", "))
+	}
+
+	if *nestedNaming != "key" && *nestedNaming != "path" {
+		log.Fatalf("Invalid nested naming: %s. Valid values are: key, path", *nestedNaming)
+	}
+
+	if *websocketEnabled && *formatType == "" {
+		log.Fatal("-ws requires -format")
+	}
+
+	if *goPackage != "" {
+		if *formatType != "go" {
+			log.Fatal("-package requires -format go")
+		}
+		if !token.IsIdentifier(*goPackage) || *goPackage == "_" {
+			log.Fatalf("Invalid package name: %s", *goPackage)
+		}
+		if *appendOut {
+			log.Fatal("-package cannot be combined with -append")
+		}
+	}
+
+	if *watchDir != "" && *formatType == "" {
+		log.Fatal("-watch requires -format")
+	}
+
+	if *outPath != "" && *formatType == "" {
+		log.Fatal("-out requires -format")
+	}
+	if *appendOut && *outPath == "" {
+		log.Fatal("-append requires -out")
+	}
+
+	if *flatten && *nestedNaming == "path" {
+		log.Fatal("-flatten inlines nested objects and cannot be combined with -nested-naming path")
+	}
+
+	if *graphqlKind != "type" && *graphqlKind != "input" {
+		log.Fatalf("Invalid GraphQL kind: %s. Valid values are: type, input", *graphqlKind)
+	}
+	if *kotlinStyle != "kotlinx" && *kotlinStyle != "jackson" && *kotlinStyle != "moshi" {
+		log.Fatalf("Invalid Kotlin style: %s. Valid values are: kotlinx, jackson, moshi", *kotlinStyle)
+	}
+
+	if *numberType != "" {
+		allowed := server.NumberTypes(*formatType)
+		if allowed == nil {
+			log.Fatal("-number-type requires -format go or rust")
+		}
+		if !slices.Contains(allowed, *numberType) {
+			log.Fatalf("Invalid number type for %s: %s. Valid values are: %s", *formatType, *numberType, strings.Join(allowed, ", "))
+		}
+	}
+
+	typeOverrides, err := server.ParseTypeOverrides(*typeOverride)
+	if err != nil {
+		log.Fatalf("Invalid -type-override: %v", err)
+	}
+	if len(typeOverrides) > 0 && !server.TypeOverrideFormats(*formatType) {
+		log.Fatal("-type-override requires -format go or rust")
+	}
+
+	graphqlScalarTypes, err := server.ParseGraphQLScalars(*graphqlScalars)
+	if err != nil {
+		log.Fatalf("Invalid -graphql-scalars: %v", err)
+	}
+	if len(graphqlScalarTypes) > 0 && *formatType != "graphql" {
+		log.Fatal("-graphql-scalars requires -format graphql")
+	}
+
+	for _, pattern := range parseList(*pathPatterns) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			log.Fatalf("Invalid path pattern: %s", pattern)
 		}
+	}
 
-		if !validFormats[*formatType] {
-			log.Fatalf("Invalid format type: %s. Valid formats are: go, rust", *formatType)
+	for _, tag := range parseList(*goTags) {
+		if strings.ContainsAny(tag, " :\"`") {
+			log.Fatalf("Invalid Go tag key: %s", tag)
 		}
 	}
 
+	indentStr, err := parseIndent(*indent)
+	if err != nil {
+		log.Fatalf("Invalid indent: %v", err)
+	}
+
+	ports, err := parsePorts(*port)
+	if err != nil {
+		log.Fatalf("Invalid port: %v", err)
+	}
+
 	// Create server instance
-	srv := server.New(*port, *formatType, *pretty, *headers)
+	opts := []server.Option{
+		server.WithDetectEnums(*detectEnums),
+		server.WithDetectUUID(*detectUUID),
+		server.WithIndent(indentStr),
+		server.WithQuiet(*quiet),
+		server.WithReadTimeout(*readTimeout),
+		server.WithWriteTimeout(*writeTimeout),
+		server.WithColor(*color && useColor(os.Stderr)),
+		server.WithGeneratedHeader(*generatedComment),
+		server.WithFormatHeaders(*formatHeaders),
+		server.WithDetectBase64(*detectBase64),
+		server.WithPointers(*pointers),
+		server.WithRustDerives(parseList(*rustDerives)),
+		server.WithRustPub(*rustPub),
+		server.WithMaxDepth(*maxDepth),
+		server.WithNestedNaming(*nestedNaming),
+		server.WithRateLimit(*rateLimit, *rateLimitPerIP),
+		server.WithOpenAPISpec(*openAPISpec),
+		server.WithCoerceNumericStrings(*coerceNumericStrings),
+		server.WithConcurrency(*concurrency),
+		server.WithCacheSize(*cacheSize),
+		server.WithRubySorbet(*rubySorbet),
+		server.WithComments(*comments),
+		server.WithH2C(*h2cEnabled),
+		server.WithOnce(*once),
+		server.WithDetectDurations(*detectDurations),
+		server.WithExtraPorts(ports[1:]...),
+		server.WithKotlinStyle(*kotlinStyle),
+		server.WithMaxBodySize(*maxBodySize),
+		server.WithFlatten(*flatten),
+		server.WithOutputFile(*outPath, *appendOut),
+		server.WithGoTags(parseList(*goTags)),
+		server.WithNarrowInts(*narrowInts),
+		server.WithValidate(*validate),
+		server.WithGzip(*gzipResponses),
+		server.WithMaxFields(*maxFields),
+		server.WithMeta(*meta),
+		server.WithVerbose(*verbose),
+		server.WithNumberType(*numberType),
+		server.WithStrictKeys(*strictKeys),
+		server.WithPaths(parseList(*pathPatterns)),
+		server.WithWebSocket(*websocketEnabled),
+		server.WithAnnotateTypes(*annotateTypes),
+		server.WithTypeOverrides(typeOverrides),
+		server.WithGoAny(*goAny),
+		server.WithDetectNet(*detectNet),
+		server.WithPackage(*goPackage),
+		server.WithGraphQLKind(*graphqlKind),
+		server.WithGraphQLScalars(graphqlScalarTypes),
+		server.WithCapture(capture),
+		server.WithStrictDepth(*strictDepth),
+		server.WithVersion(version),
+	}
+	if *otelEnabled {
+		provider, err := setupTracing(*otelEndpoint)
+		if err != nil {
+			log.Fatalf("Error setting up tracing: %v", err)
+		}
+		defer provider.Shutdown(context.Background())
+		opts = append(opts, server.WithTracerProvider(provider))
+	}
+	srv := server.New(ports[0], *formatType, *pretty, *headers, opts...)
+
+	// Format a JSON literal without starting the server
+	if *jsonLiteral != "" && *validate {
+		report, err := srv.Validate([]byte(*jsonLiteral))
+		if err != nil {
+			log.Fatalf("Error validating -json: %v", err)
+		}
+		fmt.Println(report)
+		return
+	}
+	if *jsonLiteral != "" {
+		if *formatType == "" {
+			log.Fatalf("-json requires -format")
+		}
+		formatted, err := srv.Format([]byte(*jsonLiteral), "-json")
+		if err != nil {
+			log.Fatalf("Error formatting -json: %v", err)
+		}
+		fmt.Println(formatted)
+		return
+	}
+
+	// Merge the schemas of several files without starting the server
+	if *inFiles != "" {
+		if *formatType == "" {
+			log.Fatal("-in requires -format")
+		}
+		paths, err := expandPaths(parseList(*inFiles))
+		if err != nil {
+			log.Fatalf("Error in -in: %v", err)
+		}
+		formatted, err := srv.FormatFiles(paths)
+		if err != nil {
+			log.Fatalf("Error formatting -in: %v", err)
+		}
+		fmt.Println(formatted)
+		return
+	}
+
+	// Regenerate types from the bodies of a captured request log
+	if *replayFiles != "" {
+		if *formatType == "" {
+			log.Fatal("-replay requires -format")
+		}
+		paths, err := expandPaths(parseList(*replayFiles))
+		if err != nil {
+			log.Fatalf("Error in -replay: %v", err)
+		}
+		formatted, err := srv.Replay(paths)
+		if err != nil {
+			log.Fatalf("Error replaying: %v", err)
+		}
+		fmt.Println(formatted)
+		return
+	}
+
+	// Generate code for pasted JSON without starting the server
+	if *repl {
+		if err := srv.RunREPL(os.Stdin, os.Stdout); err != nil {
+			log.Fatalf("Error reading REPL input: %v", err)
+		}
+		return
+	}
+
+	// Process a saved request without starting the server
+	if *httpFile != "" {
+		raw, err := os.ReadFile(*httpFile)
+		if err != nil {
+			log.Fatalf("Error reading HTTP file: %v", err)
+		}
+		req, err := server.ParseRawRequest(raw)
+		if err != nil {
+			log.Fatalf("Error parsing HTTP file: %v", err)
+		}
+		if err := srv.ProcessRequest(req, *httpFile); err != nil {
+			log.Fatalf("Error processing HTTP file: %v", err)
+		}
+		return
+	}
 
 	// Setup context with cancellation
 	ctx, cancel := context.WithCancel(context.Background())
@@ -77,22 +550,143 @@ func main() {
 		sigChan := make(chan os.Signal, 1)
 		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 		<-sigChan
-		log.Println("Shutting down server...")
+		logInfo("Shutting down server...")
 		cancel()
 	}()
 
-	log.Printf("Starting server on port %d...", *port)
+	// Regenerate the code of fixtures as they change instead of serving
+	if *watchDir != "" {
+		logInfo("Watching %s...", *watchDir)
+		if err := srv.Watch(ctx, *watchDir); err != nil {
+			log.Fatalf("Error watching %s: %v", *watchDir, err)
+		}
+		return
+	}
+
+	if len(ports) > 1 {
+		logInfo("Starting server on ports %s...", strings.Join(parseList(*port), ", "))
+	} else {
+		logInfo("Starting server on port %d...", ports[0])
+	}
 	if *formatType != "" {
-		log.Printf("Format type: %s", *formatType)
+		logInfo("Format type: %s", *formatType)
 	}
 	if *pretty {
-		log.Printf("Pretty JSON printing enabled")
+		logInfo("Pretty JSON printing enabled")
 	}
 	if *headers {
-		log.Printf("HTTP headers display enabled")
+		logInfo("HTTP headers display enabled")
 	}
 
 	if err := srv.Start(ctx); err != nil {
 		log.Fatalf("Server error: %v", err)
 	}
 }
+
+// logInfo logs an informational message unless -quiet is set.
+func logInfo(format string, args ...interface{}) {
+	if !*quiet {
+		log.Printf(format, args...)
+	}
+}
+
+// parseIndent converts the -indent flag into the indentation string, either a
+// tab or a number of spaces.
+func parseIndent(value string) (string, error) {
+	if value == "tab" {
+		return "\t", nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return "", fmt.Errorf("%q is not a number of spaces or tab", value)
+	}
+	return strings.Repeat(" ", n), nil
+}
+
+// useColor reports whether ANSI colors should be written to f, which is where
+// the log output goes. It follows the NO_COLOR convention.
+func useColor(f *os.File) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// parseList splits a comma-separated flag value, dropping empty entries.
+func parseList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// expandPaths expands the globs of the -in flag into the files they match,
+// in order and without repeats. A glob matching nothing is an error, as it is
+// most likely a typo.
+func expandPaths(patterns []string) ([]string, error) {
+	var paths []string
+	seen := make(map[string]bool)
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a valid glob: %v", pattern, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no files match %q", pattern)
+		}
+		for _, match := range matches {
+			if !seen[match] {
+				seen[match] = true
+				paths = append(paths, match)
+			}
+		}
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no files given")
+	}
+	return paths, nil
+}
+
+// parsePorts parses the -port flag, a port or comma-separated list of ports.
+func parsePorts(value string) ([]int, error) {
+	var ports []int
+	for _, item := range parseList(value) {
+		port, err := strconv.Atoi(item)
+		if err != nil || port < 0 || port > 65535 {
+			return nil, fmt.Errorf("%q is not a port number", item)
+		}
+		ports = append(ports, port)
+	}
+	if len(ports) == 0 {
+		return nil, fmt.Errorf("no port given")
+	}
+	return ports, nil
+}
+
+// setupTracing creates a tracer provider exporting spans over OTLP/HTTP and
+// installs the W3C trace context propagator so incoming traces are continued.
+func setupTracing(endpoint string) (*sdktrace.TracerProvider, error) {
+	exporter, err := otlptracehttp.New(context.Background(),
+		otlptracehttp.WithEndpoint(endpoint),
+		otlptracehttp.WithInsecure(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	res := resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName("reqparser"),
+		semconv.ServiceVersion(version),
+	)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	), nil
+}
@@ -0,0 +1,89 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// annotate renders a decoded JSON value indented like the pretty output,
+// ending the line of every leaf with a comment naming its type: string, int,
+// float, bool or null. Numbers are ints when whole, as for -narrow-ints.
+// Object keys are sorted, and the result is a diagnostic view rather than
+// valid JSON.
+func (s *Server) annotate(v interface{}) string {
+	var b strings.Builder
+	s.writeAnnotated(&b, v, "", "")
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// writeAnnotated writes v at the given indentation, followed by suffix (a
+// comma or nothing) and, for leaves, the type comment.
+func (s *Server) writeAnnotated(b *strings.Builder, v interface{}, indent, suffix string) {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		if len(val) == 0 {
+			fmt.Fprintf(b, "{}%s\n", suffix)
+			return
+		}
+		b.WriteString("{\n")
+		keys := sortedKeys(val)
+		for i, key := range keys {
+			fmt.Fprintf(b, "%s%s%s: ", indent, s.indent, marshalLeaf(key))
+			s.writeAnnotated(b, val[key], indent+s.indent, annotatedSeparator(i, len(keys)))
+		}
+		fmt.Fprintf(b, "%s}%s\n", indent, suffix)
+	case []interface{}:
+		if len(val) == 0 {
+			fmt.Fprintf(b, "[]%s\n", suffix)
+			return
+		}
+		b.WriteString("[\n")
+		for i, elem := range val {
+			b.WriteString(indent + s.indent)
+			s.writeAnnotated(b, elem, indent+s.indent, annotatedSeparator(i, len(val)))
+		}
+		fmt.Fprintf(b, "%s]%s\n", indent, suffix)
+	default:
+		fmt.Fprintf(b, "%s%s // %s\n", marshalLeaf(val), suffix, leafType(val))
+	}
+}
+
+// annotatedSeparator returns the comma following every element but the last.
+func annotatedSeparator(i, n int) string {
+	if i < n-1 {
+		return ","
+	}
+	return ""
+}
+
+// marshalLeaf encodes a scalar as JSON, leaving HTML characters unescaped.
+func marshalLeaf(v interface{}) string {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// leafType names the type of a scalar JSON value.
+func leafType(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "bool"
+	case string:
+		return "string"
+	}
+	if sch := inferSchema(v); sch.kind == kindNumber {
+		if sch.integral() {
+			return "int"
+		}
+		return "float"
+	}
+	return fmt.Sprintf("%T", v)
+}
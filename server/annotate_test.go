@@ -0,0 +1,85 @@
+package server
+
+import (
+	"log"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestAnnotateTypes(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     interface{}
+		expected string
+	}{
+		{
+			name: "Leaf types",
+			data: map[string]interface{}{
+				"active": true,
+				"count":  3.0,
+				"name":   "a<b",
+				"note":   nil,
+				"ratio":  0.5,
+			},
+			expected: "{\n" +
+				"  \"active\": true, // bool\n" +
+				"  \"count\": 3, // int\n" +
+				"  \"name\": \"a<b\", // string\n" +
+				"  \"note\": null, // null\n" +
+				"  \"ratio\": 0.5 // float\n" +
+				"}",
+		},
+		{
+			name: "Nested values",
+			data: map[string]interface{}{
+				"owner": map[string]interface{}{"id": 1.0},
+				"tags":  []interface{}{"a", 2.5},
+				"empty": []interface{}{},
+				"meta":  map[string]interface{}{},
+			},
+			expected: "{\n" +
+				"  \"empty\": [],\n" +
+				"  \"meta\": {},\n" +
+				"  \"owner\": {\n" +
+				"    \"id\": 1 // int\n" +
+				"  },\n" +
+				"  \"tags\": [\n" +
+				"    \"a\", // string\n" +
+				"    2.5 // float\n" +
+				"  ]\n" +
+				"}",
+		},
+		{
+			name:     "Scalar root",
+			data:     "hello",
+			expected: "\"hello\" // string",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := New(8080, "", true, false, WithIndent("  "))
+			if got := srv.annotate(tt.data); got != tt.expected {
+				t.Errorf("annotate() =\n%s\nwant\n%s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestServer_AnnotateTypes(t *testing.T) {
+	var logBuf strings.Builder
+	log.SetOutput(&logBuf)
+	defer log.SetOutput(os.Stderr)
+
+	srv := New(8080, "", false, false, WithAnnotateTypes(true))
+	req := httptest.NewRequest("POST", "/api/data", strings.NewReader(`{"id":1}`))
+	req.Header.Set("Content-Type", "application/json")
+	srv.handleRequest(httptest.NewRecorder(), req)
+
+	expect := "Annotated types:\n{\n    \"id\": 1 // int\n}\n"
+	if !strings.Contains(logBuf.String(), expect) {
+		t.Errorf("log does not contain expected string: %s\nGot: %s", expect, logBuf.String())
+	}
+}
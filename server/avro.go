@@ -0,0 +1,124 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// avroRecord is an Avro record schema. Structs are used rather than maps so
+// that the keys keep their conventional order.
+type avroRecord struct {
+	Type   string      `json:"type"`
+	Name   string      `json:"name"`
+	Fields []avroField `json:"fields"`
+}
+
+type avroField struct {
+	Name    string           `json:"name"`
+	Doc     string           `json:"doc,omitempty"`
+	Type    interface{}      `json:"type"`
+	Default *json.RawMessage `json:"default,omitempty"`
+}
+
+type avroArray struct {
+	Type  string      `json:"type"`
+	Items interface{} `json:"items"`
+}
+
+// avroMixed is the union used for values observed with several types.
+var avroMixed = []interface{}{"null", "boolean", "long", "double", "string"}
+
+func (s *Server) formatAsAvro(sch *schema) (string, error) {
+	root := sch
+	if sch.kind != kindObject {
+		root = &schema{kind: kindObject, fields: []*field{{name: "data", schema: sch}}}
+	}
+
+	types := s.nestedObjects(s.structName, root)
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(s.generateAvroRecord(root, types, make(map[string]bool))); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// generateAvroRecord defines a record and the records nested in it. Records
+// listed in defined have already been written out and are referenced by name.
+func (s *Server) generateAvroRecord(sch *schema, types *objectTypes, defined map[string]bool) avroRecord {
+	nullDefault := json.RawMessage("null")
+
+	record := avroRecord{Type: "record", Name: types.name(sch), Fields: []avroField{}}
+	defined[record.Name] = true
+	// Keys such as "a-b" and "a_b" share a name
+	names := keyNames(sch.fields, sanitizeIdentifier)
+	for i, f := range sch.fields {
+		field := avroField{Name: names[i], Type: s.getAvroType(f.schema, types, defined)}
+		if field.Name != f.name {
+			field.Doc = "JSON key: " + f.name
+		}
+		if f.optional || f.schema.nullable || f.schema.kind == kindNull {
+			field.Type = avroNullable(field.Type)
+			field.Default = &nullDefault
+		}
+		record.Fields = append(record.Fields, field)
+	}
+	return record
+}
+
+func (s *Server) getAvroType(sch *schema, types *objectTypes, defined map[string]bool) interface{} {
+	var avroType interface{}
+	switch sch.kind {
+	case kindBool:
+		avroType = "boolean"
+	case kindNumber:
+		if sch.integral() {
+			avroType = "long"
+		} else {
+			avroType = "double"
+		}
+	case kindString:
+		avroType = "string"
+	case kindArray:
+		var items interface{} = avroMixed
+		if sch.elem != nil {
+			items = s.getAvroType(sch.elem, types, defined)
+		}
+		avroType = avroArray{Type: "array", Items: items}
+	case kindObject:
+		if name := types.name(sch); defined[name] {
+			avroType = name
+		} else {
+			avroType = s.generateAvroRecord(sch, types, defined)
+		}
+	case kindNull:
+		return "null"
+	default:
+		return avroMixed
+	}
+	if sch.nullable {
+		return avroNullable(avroType)
+	}
+	return avroType
+}
+
+// avroNullable turns a type into a union with null listed first, so that null
+// can be used as the field default.
+func avroNullable(avroType interface{}) interface{} {
+	switch t := avroType.(type) {
+	case string:
+		if t == "null" {
+			return t
+		}
+	case []interface{}:
+		if len(t) > 0 && t[0] == "null" {
+			return t
+		}
+		return append([]interface{}{"null"}, t...)
+	}
+	return []interface{}{"null", avroType}
+}
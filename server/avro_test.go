@@ -0,0 +1,84 @@
+package server
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestFormatAsAvro(t *testing.T) {
+	tests := []struct {
+		name           string
+		data           interface{}
+		records        []interface{}
+		expectContains []string
+	}{
+		{
+			name: "Flat object",
+			data: map[string]interface{}{"name": "test", "count": 123.0, "ratio": 0.5, "active": true},
+			expectContains: []string{
+				`"type": "record",` + "\n" + `  "name": "GeneratedStruct",`,
+				`"name": "active",` + "\n" + `      "type": "boolean"`,
+				`"name": "count",` + "\n" + `      "type": "long"`,
+				`"name": "name",` + "\n" + `      "type": "string"`,
+				`"name": "ratio",` + "\n" + `      "type": "double"`,
+			},
+		},
+		{
+			name: "Nested records and arrays",
+			data: map[string]interface{}{
+				"user":       map[string]interface{}{"id": 1.0},
+				"tags":       []interface{}{"a"},
+				"created-at": "2024-01-01",
+			},
+			expectContains: []string{
+				`"name": "created_at",` + "\n" + `      "doc": "JSON key: created-at",`,
+				`"type": "array",` + "\n" + `        "items": "string"`,
+				`"type": "record",` + "\n" + `        "name": "User",`,
+			},
+		},
+		{
+			name: "Optional and nullable fields",
+			records: []interface{}{
+				map[string]interface{}{"id": 1.0, "email": nil},
+				map[string]interface{}{"id": 2.0, "email": "x@example.com", "nickname": "a"},
+			},
+			expectContains: []string{
+				`"name": "email",` + "\n" + `      "type": [` + "\n" + `        "null",` + "\n" + `        "string"` + "\n" + `      ],` + "\n" + `      "default": null`,
+				`"name": "nickname",`,
+			},
+		},
+		{
+			name: "Colliding keys",
+			data: map[string]interface{}{"a-b": 1.0, "a_b": "x"},
+			expectContains: []string{
+				`"name": "a_b2",` + "\n" + `      "doc": "JSON key: a-b",` + "\n" + `      "type": "long"`,
+				`"name": "a_b",` + "\n" + `      "type": "string"`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sch := inferSchema(tt.data)
+			if tt.records != nil {
+				sch = inferRecords(tt.records)
+			}
+
+			srv := New(8080, "avro", false, false)
+			result, err := srv.formatSchema(sch)
+			if err != nil {
+				t.Fatalf("formatSchema() error = %v", err)
+			}
+			if !json.Valid([]byte(result)) {
+				t.Errorf("formatSchema() result is not valid JSON: %s", result)
+			}
+
+			for _, expect := range tt.expectContains {
+				if !strings.Contains(result, expect) {
+					t.Errorf("formatSchema() result does not contain expected string: %s\nGot: %s", expect, result)
+				}
+			}
+		})
+	}
+}
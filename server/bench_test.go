@@ -0,0 +1,60 @@
+package server
+
+import (
+	"fmt"
+	"testing"
+)
+
+// benchmarkPayloads are representative bodies for the formatter benchmarks:
+// a wide flat object, deeply nested objects and a large array of objects.
+func benchmarkPayloads() map[string]interface{} {
+	wide := make(map[string]interface{}, 10000)
+	for i := 0; i < 10000; i++ {
+		switch i % 4 {
+		case 0:
+			wide[fmt.Sprintf("field_%d", i)] = float64(i)
+		case 1:
+			wide[fmt.Sprintf("field_%d", i)] = fmt.Sprintf("value %d", i)
+		case 2:
+			wide[fmt.Sprintf("field_%d", i)] = i%3 == 0
+		default:
+			wide[fmt.Sprintf("field_%d", i)] = []interface{}{float64(i), float64(i + 1)}
+		}
+	}
+
+	deep := map[string]interface{}{"value": 1.0}
+	for i := 0; i < 50; i++ {
+		deep = map[string]interface{}{fmt.Sprintf("level_%d", i): deep, "id": float64(i), "name": "n"}
+	}
+
+	array := make([]interface{}, 1000)
+	for i := range array {
+		array[i] = map[string]interface{}{
+			"id":     float64(i),
+			"name":   fmt.Sprintf("user %d", i),
+			"active": i%2 == 0,
+			"tags":   []interface{}{"a", "b"},
+			"owner":  map[string]interface{}{"id": float64(i), "email": "x@example.com"},
+		}
+	}
+
+	return map[string]interface{}{"Wide": wide, "Deep": deep, "Array": array}
+}
+
+func BenchmarkFormatData(b *testing.B) {
+	payloads := benchmarkPayloads()
+	for _, format := range []string{"go", "rust"} {
+		for _, name := range []string{"Wide", "Deep", "Array"} {
+			data := payloads[name]
+			b.Run(format+"/"+name, func(b *testing.B) {
+				srv := New(8080, format, false, false)
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if _, err := srv.formatData(data); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+)
+
+// cKeywords lists the C reserved words that cannot be used as member names.
+var cKeywords = map[string]bool{
+	"auto": true, "break": true, "case": true, "char": true, "const": true,
+	"continue": true, "default": true, "do": true, "double": true, "else": true,
+	"enum": true, "extern": true, "float": true, "for": true, "goto": true,
+	"if": true, "inline": true, "int": true, "long": true, "register": true,
+	"restrict": true, "return": true, "short": true, "signed": true,
+	"sizeof": true, "static": true, "struct": true, "switch": true,
+	"typedef": true, "union": true, "unsigned": true, "void": true,
+	"volatile": true, "while": true, "bool": true, "true": true, "false": true,
+}
+
+func (s *Server) formatAsC(sch *schema) (string, error) {
+	root := sch
+	if sch.kind != kindObject {
+		root = &schema{kind: kindObject, fields: []*field{{name: "data", schema: sch}}}
+	}
+
+	// C needs complete types before use, so emit children first
+	types := s.nestedObjects(s.structName, root)
+	includes := make(map[string]bool)
+	var structs []string
+	for _, obj := range childrenFirst(types) {
+		structs = append(structs, s.generateCStruct(obj, types, includes))
+	}
+
+	var header string
+	for _, include := range []string{"stdbool.h", "stddef.h"} {
+		if includes[include] {
+			header += fmt.Sprintf("#include <%s>\n", include)
+		}
+	}
+	if header != "" {
+		header += "\n"
+	}
+	return header + strings.Join(structs, "\n\n"), nil
+}
+
+func (s *Server) generateCStruct(obj *objectType, types *objectTypes, includes map[string]bool) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "struct %s {\n", obj.name)
+
+	used := make(map[string]bool)
+	for _, f := range obj.schema.fields {
+		name := cMemberName(cIdentifier(f.name), used)
+
+		fieldType := s.getCType(f.schema, types, includes)
+		if f.optional {
+			fieldType = cPointer(fieldType)
+		}
+		comment := ""
+		if name != f.name {
+			comment = fmt.Sprintf(" /* %s */", strings.ReplaceAll(f.name, "*/", "* /"))
+		}
+		fmt.Fprintf(&b, "    %s;%s\n", cDeclaration(fieldType, name), comment)
+
+		// Arrays are a pointer plus an element count
+		if f.schema.kind == kindArray {
+			includes["stddef.h"] = true
+			fmt.Fprintf(&b, "    size_t %s;\n", cMemberName(name+"_count", used))
+		}
+	}
+	b.WriteString("};")
+	return b.String()
+}
+
+func (s *Server) getCType(sch *schema, types *objectTypes, includes map[string]bool) string {
+	var cType string
+	switch sch.kind {
+	case kindBool:
+		includes["stdbool.h"] = true
+		cType = "bool"
+	case kindNumber:
+		if sch.integral() {
+			cType = "long"
+		} else {
+			cType = "double"
+		}
+	case kindString:
+		return "char *"
+	case kindArray:
+		elemType := "void *"
+		if sch.elem != nil {
+			elemType = s.getCType(sch.elem, types, includes)
+		}
+		return cPointer(elemType)
+	case kindObject:
+		cType = "struct " + types.name(sch)
+	default:
+		return "void *"
+	}
+	if sch.nullable {
+		return cPointer(cType)
+	}
+	return cType
+}
+
+// cMemberName reserves a member name within a struct, suffixing it with a
+// number when a member, array counts included, already has it.
+func cMemberName(name string, used map[string]bool) string {
+	base := name
+	for n := 2; used[name]; n++ {
+		name = fmt.Sprintf("%s_%d", base, n)
+	}
+	used[name] = true
+	return name
+}
+
+// cPointer returns a pointer to a C type, used for arrays and for values that
+// may be missing.
+func cPointer(cType string) string {
+	if strings.HasSuffix(cType, "*") {
+		return cType + "*"
+	}
+	return cType + " *"
+}
+
+// cDeclaration joins a type and member name, keeping pointer stars next to
+// the name.
+func cDeclaration(cType, name string) string {
+	if strings.HasSuffix(cType, "*") {
+		return cType + name
+	}
+	return cType + " " + name
+}
+
+// cIdentifier sanitizes a JSON key into a C identifier.
+func cIdentifier(key string) string {
+	name := sanitizeIdentifier(key)
+	if cKeywords[name] {
+		name += "_"
+	}
+	return name
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatAsC(t *testing.T) {
+	tests := []struct {
+		name           string
+		data           interface{}
+		records        []interface{}
+		expectContains []string
+		expectMissing  []string
+	}{
+		{
+			name: "Flat object",
+			data: map[string]interface{}{"name": "test", "count": 123.0, "ratio": 0.5, "active": true},
+			expectContains: []string{
+				"#include <stdbool.h>\n\nstruct GeneratedStruct {\n",
+				"    bool active;\n",
+				"    long count;\n",
+				"    char *name;\n",
+				"    double ratio;\n",
+				"};",
+			},
+			expectMissing: []string{"stddef.h"},
+		},
+		{
+			name: "Nested structs and arrays",
+			data: map[string]interface{}{
+				"user": map[string]interface{}{"id": 1.0},
+				"tags": []interface{}{"a"},
+			},
+			expectContains: []string{
+				"#include <stddef.h>",
+				"struct User {\n    long id;\n};\n\nstruct GeneratedStruct {",
+				"    char **tags;\n    size_t tags_count;\n",
+				"    struct User user;\n",
+			},
+		},
+		{
+			name: "Identifiers and missing values",
+			records: []interface{}{
+				map[string]interface{}{"created-at": "2024-01-01", "int": 1.0, "score": nil},
+				map[string]interface{}{"created-at": "2024-01-02", "score": 1.5},
+			},
+			expectContains: []string{
+				"    char *created_at; /* created-at */\n",
+				"    long *int_; /* int */\n",
+				"    double *score;\n",
+			},
+		},
+		{
+			name: "Array counts and clashing keys",
+			data: map[string]interface{}{
+				"a":       []interface{}{1.0},
+				"a_count": 2.0,
+			},
+			expectContains: []string{
+				"    long *a;\n    size_t a_count;\n    long a_count_2; /* a_count */\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sch := inferSchema(tt.data)
+			if tt.records != nil {
+				sch = inferRecords(tt.records)
+			}
+
+			srv := New(8080, "c", false, false)
+			result, err := srv.formatSchema(sch)
+			if err != nil {
+				t.Fatalf("formatSchema() error = %v", err)
+			}
+
+			for _, expect := range tt.expectContains {
+				if !strings.Contains(result, expect) {
+					t.Errorf("formatSchema() result does not contain expected string: %s\nGot: %s", expect, result)
+				}
+			}
+			for _, missing := range tt.expectMissing {
+				if strings.Contains(result, missing) {
+					t.Errorf("formatSchema() result contains unexpected string: %s\nGot: %s", missing, result)
+				}
+			}
+		})
+	}
+}
+
+func TestChildrenFirst(t *testing.T) {
+	srv := New(8080, "c", false, false)
+	result, err := srv.formatData(map[string]interface{}{
+		"billing":  map[string]interface{}{"address": map[string]interface{}{"city": "Paris"}},
+		"shipping": map[string]interface{}{"address": map[string]interface{}{"city": "Lyon"}},
+	})
+	if err != nil {
+		t.Fatalf("formatData() error = %v", err)
+	}
+
+	address := strings.Index(result, "struct Address {")
+	for _, parent := range []string{"struct Billing {", "struct Shipping {", "struct GeneratedStruct {"} {
+		if i := strings.Index(result, parent); i < address {
+			t.Errorf("formatData() defined %q before struct Address\nGot: %s", parent, result)
+		}
+	}
+}
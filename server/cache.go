@@ -0,0 +1,96 @@
+package server
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"sync"
+)
+
+// outputCache is a least recently used cache of generated code, keyed by a
+// hash of the format, struct name, request media type and body. The media type
+// is part of the key because it decides how the body is read: the same bytes
+// are two numbers as NDJSON and a header and a row as CSV. The other options are
+// fixed for the server owning the cache, so they need not be part of the key.
+type outputCache struct {
+	size int
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[[sha256.Size]byte]*list.Element
+}
+
+type cacheEntry struct {
+	key    [sha256.Size]byte
+	output string
+	// depthCut records that values were left untyped at the maximum depth, so
+	// hits warn about it as the first request did.
+	depthCut bool
+}
+
+func newOutputCache(size int) *outputCache {
+	return &outputCache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[[sha256.Size]byte]*list.Element),
+	}
+}
+
+// cacheKey hashes a format name, struct name, the media type a body was read
+// as and the body generated from them.
+func cacheKey(formatType, structName, mediaType string, body []byte) [sha256.Size]byte {
+	h := sha256.New()
+	h.Write([]byte(formatType))
+	h.Write([]byte{0})
+	h.Write([]byte(structName))
+	h.Write([]byte{0})
+	h.Write([]byte(mediaType))
+	h.Write([]byte{0})
+	h.Write(body)
+
+	var key [sha256.Size]byte
+	h.Sum(key[:0])
+	return key
+}
+
+// get returns the output stored under key and whether it was cut at the
+// maximum depth.
+func (c *outputCache) get(key [sha256.Size]byte) (string, bool, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return "", false, false
+	}
+	c.order.MoveToFront(elem)
+	entry := elem.Value.(*cacheEntry)
+	return entry.output, entry.depthCut, true
+}
+
+// reset empties the cache.
+func (c *outputCache) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.order.Init()
+	c.entries = make(map[[sha256.Size]byte]*list.Element)
+}
+
+// add stores output under key, with whether it was cut at the maximum depth,
+// evicting the least recently used entry once the cache is full.
+func (c *outputCache) add(key [sha256.Size]byte, output string, depthCut bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*cacheEntry)
+		entry.output, entry.depthCut = output, depthCut
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, output: output, depthCut: depthCut})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
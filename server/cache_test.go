@@ -0,0 +1,117 @@
+package server
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOutputCache(t *testing.T) {
+	cache := newOutputCache(2)
+	a, b, c := cacheKey("go", "Row", "application/json", []byte("a")), cacheKey("go", "Row", "application/json", []byte("b")), cacheKey("go", "Row", "application/json", []byte("c"))
+
+	cache.add(a, "A", false)
+	cache.add(b, "B", false)
+	// Using a makes b the least recently used entry
+	if output, _, ok := cache.get(a); !ok || output != "A" {
+		t.Errorf("get(a) = %q, %v want %q, true", output, ok, "A")
+	}
+	cache.add(c, "C", false)
+
+	if _, _, ok := cache.get(b); ok {
+		t.Error("get(b) found an entry that should have been evicted")
+	}
+	for key, expect := range map[[32]byte]string{a: "A", c: "C"} {
+		if output, _, ok := cache.get(key); !ok || output != expect {
+			t.Errorf("get() = %q, %v want %q, true", output, ok, expect)
+		}
+	}
+
+	if cacheKey("go", "Row", "application/json", []byte("a")) == cacheKey("rust", "Row", "application/json", []byte("a")) {
+		t.Error("cacheKey() ignores the format")
+	}
+	if cacheKey("go", "Row", "application/json", []byte("a")) == cacheKey("go", "Order", "application/json", []byte("a")) {
+		t.Error("cacheKey() ignores the struct name")
+	}
+	if cacheKey("go", "Row", "application/x-ndjson", []byte("a")) == cacheKey("go", "Row", "text/csv", []byte("a")) {
+		t.Error("cacheKey() ignores the media type")
+	}
+}
+
+func TestServer_FormatCached(t *testing.T) {
+	srv := New(8080, "go", false, false, WithCacheSize(8))
+	body := []byte(`{"name": "test"}`)
+
+	first, err := srv.Format(body, "test")
+	if err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	cached, _, ok := srv.cache.get(cacheKey("go", defaultStructName, "application/x-ndjson", body))
+	if !ok {
+		t.Fatal("Format() did not cache its output")
+	}
+	if !strings.Contains(cached, "type GeneratedStruct struct") {
+		t.Errorf("cached output does not contain the struct\nGot: %s", cached)
+	}
+
+	// Other formats share the cache without colliding
+	srv.formatType = "rust"
+	second, err := srv.Format(body, "test")
+	if err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	if first == second || !strings.Contains(second, "struct GeneratedStruct {") {
+		t.Errorf("Format() for rust returned\n%s", second)
+	}
+}
+
+func TestServer_CacheWarnsOnDepthLimit(t *testing.T) {
+	srv := New(8080, "go", false, false, WithCacheSize(8), WithMaxDepth(1))
+	body := []byte(`{"a":{"b":{"c":1}}}`)
+	records := []interface{}{map[string]interface{}{"a": map[string]interface{}{"b": map[string]interface{}{"c": 1.0}}}}
+	var logBuf strings.Builder
+	logger := log.New(&logBuf, "", 0)
+
+	for i := 0; i < 2; i++ {
+		logBuf.Reset()
+		if _, err := srv.generateRecords(logger, "application/json", body, records, "test"); err != nil {
+			t.Fatalf("generateRecords() error = %v", err)
+		}
+		if !strings.Contains(logBuf.String(), "Warning: values nested deeper than 1 levels are left untyped") {
+			t.Errorf("request %d did not log the depth warning\nGot: %s", i+1, logBuf.String())
+		}
+	}
+	if _, cut, ok := srv.cache.get(cacheKey("go", defaultStructName, "application/json", body)); !ok || !cut {
+		t.Errorf("cache.get() cut = %v, ok = %v, want true, true", cut, ok)
+	}
+}
+
+func TestServer_CacheKeyedOnMediaType(t *testing.T) {
+	srv := New(8080, "go", false, false, WithQuiet(true), WithCacheSize(8))
+	handler := srv.httpServer().Handler
+	body := []byte("1\n2")
+
+	// The same bytes are two numbers as NDJSON and a header and a row as CSV
+	expect := map[string]string{
+		"application/x-ndjson": "data float64 `json:\"data\"`",
+		"text/csv":             "_1 float64 `json:\"1\"`",
+	}
+	for _, contentType := range []string{"application/x-ndjson", "text/csv"} {
+		r := httptest.NewRequest("POST", "/api", bytes.NewReader(body))
+		r.Header.Set("Content-Type", contentType)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, r)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("POST as %s returned status %v\nGot: %s", contentType, rr.Code, rr.Body.String())
+		}
+	}
+	for contentType, want := range expect {
+		cached, _, ok := srv.cache.get(cacheKey("go", defaultStructName, contentType, body))
+		if !ok || !strings.Contains(cached, want) {
+			t.Errorf("cached output for %s does not contain expected string: %s\nGot: %s", contentType, want, cached)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package server
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// captureWriter appends the records of request bodies to a writer as
+// newline-delimited JSON, one compact record per line, for Replay to read
+// back.
+type captureWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func newCaptureWriter(w io.Writer) *captureWriter {
+	return &captureWriter{w: w}
+}
+
+// write appends the records of one body in a single write, so that a
+// rotating file never splits them.
+func (c *captureWriter) write(records []interface{}) error {
+	var buf bytes.Buffer
+	for _, record := range records {
+		line, err := marshalUnescaped(record)
+		if err != nil {
+			return err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, err := c.w.Write(buf.Bytes())
+	return err
+}
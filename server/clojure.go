@@ -0,0 +1,131 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+)
+
+func (s *Server) formatAsClojure(sch *schema) (string, error) {
+	root := sch
+	if sch.kind != kindObject {
+		root = &schema{kind: kindObject, fields: []*field{{name: "data", schema: sch}}}
+	}
+
+	// Specs resolve lazily, but defining children first reads top down
+	types := s.nestedObjects(s.structName, root)
+	_, enumNames := s.enumTypes(types)
+	specs := make([]string, 0, len(types.objects))
+	for _, obj := range childrenFirst(types) {
+		specs = append(specs, s.generateClojureSpecs(obj, types, enumNames))
+	}
+	return clojurePreamble(s.structName) + strings.Join(specs, "\n\n"), nil
+}
+
+// clojurePreamble starts Clojure output with a namespace named after the root
+// type, requiring clojure.spec.
+func clojurePreamble(structName string) string {
+	return fmt.Sprintf("(ns %s\n  (:require [clojure.spec.alpha :as s]))\n\n", clojureName(structName))
+}
+
+// generateClojureSpecs defines a spec per field, qualified by the object so
+// that fields of the same name in different objects keep their own specs, and
+// the s/keys spec of the object itself.
+func (s *Server) generateClojureSpecs(obj *objectType, types *objectTypes, enumNames map[*field]string) string {
+	var b strings.Builder
+	namespace := clojureName(obj.name)
+
+	var required, optional []string
+	names := keyNames(obj.schema.fields, clojureKey)
+	for i, f := range obj.schema.fields {
+		key := fmt.Sprintf(":%s/%s", namespace, names[i])
+		spec := clojureSpec(f.schema, types)
+		if _, ok := enumNames[f]; ok {
+			spec = clojureEnum(f.schema)
+		}
+		fmt.Fprintf(&b, "(s/def %s %s)\n", key, spec)
+
+		if f.optional {
+			optional = append(optional, key)
+		} else {
+			required = append(required, key)
+		}
+	}
+
+	keys := "(s/keys"
+	if len(required) > 0 {
+		keys += fmt.Sprintf(" :req-un [%s]", strings.Join(required, " "))
+	}
+	if len(optional) > 0 {
+		keys += fmt.Sprintf(" :opt-un [%s]", strings.Join(optional, " "))
+	}
+	fmt.Fprintf(&b, "(s/def ::%s %s))", namespace, keys)
+	return b.String()
+}
+
+// clojureSpec returns the spec of a value, with nested objects referring to
+// their own s/keys spec.
+func clojureSpec(sch *schema, types *objectTypes) string {
+	var spec string
+	switch sch.kind {
+	case kindBool:
+		spec = "boolean?"
+	case kindNumber:
+		spec = "number?"
+	case kindString:
+		spec = "string?"
+	case kindArray:
+		elemSpec := "any?"
+		if sch.elem != nil {
+			elemSpec = clojureSpec(sch.elem, types)
+		}
+		spec = fmt.Sprintf("(s/coll-of %s)", elemSpec)
+	case kindObject:
+		spec = "::" + clojureName(types.name(sch))
+	case kindNull:
+		return "nil?"
+	default:
+		return "any?"
+	}
+	if sch.nullable {
+		return fmt.Sprintf("(s/nilable %s)", spec)
+	}
+	return spec
+}
+
+// clojureEnum specs a detected enum as the set of its values.
+func clojureEnum(sch *schema) string {
+	values := enumValues(sch)
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = fmt.Sprintf("%q", value)
+	}
+	spec := fmt.Sprintf("#{%s}", strings.Join(quoted, " "))
+	if sch.nullable {
+		return fmt.Sprintf("(s/nilable %s)", spec)
+	}
+	return spec
+}
+
+// clojureName turns a type name into a kebab-case symbol, so GeneratedStruct
+// becomes generated-struct.
+func clojureName(name string) string {
+	return strings.ReplaceAll(toSnakeCase(name), "_", "-")
+}
+
+// clojureKey returns the name of a field's keyword. :req-un matches map keys
+// by that name, so readable keys are kept as they are and others sanitized.
+func clojureKey(key string) string {
+	for i, r := range key {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case i > 0 && r >= '0' && r <= '9':
+		case i > 0 && strings.ContainsRune("*+!-_'?<>=", r):
+		default:
+			return sanitizeIdentifier(key)
+		}
+	}
+	if key == "" {
+		return sanitizeIdentifier(key)
+	}
+	return key
+}
@@ -0,0 +1,69 @@
+package server
+
+import "testing"
+
+func TestFormatAsClojure(t *testing.T) {
+	testFormatCases(t, "clojure", []formatCase{
+		{
+			name: "Flat object",
+			data: map[string]interface{}{"name": "test", "value": 123.0, "active": true},
+			expectContains: []string{
+				"(ns generated-struct\n  (:require [clojure.spec.alpha :as s]))\n\n",
+				"(s/def :generated-struct/active boolean?)\n" +
+					"(s/def :generated-struct/name string?)\n" +
+					"(s/def :generated-struct/value number?)\n" +
+					"(s/def ::generated-struct (s/keys :req-un [:generated-struct/active :generated-struct/name :generated-struct/value]))",
+			},
+		},
+		{
+			name: "Nested objects and arrays",
+			data: map[string]interface{}{
+				"user-info": map[string]interface{}{"id": 1.0},
+				"tags":      []interface{}{"a"},
+				"empty":     []interface{}{},
+			},
+			expectContains: []string{
+				"(s/def :user-info/id number?)\n(s/def ::user-info (s/keys :req-un [:user-info/id]))\n\n(s/def :generated-struct/empty",
+				"(s/def :generated-struct/empty (s/coll-of any?))\n",
+				"(s/def :generated-struct/tags (s/coll-of string?))\n",
+				"(s/def :generated-struct/user-info ::user-info)\n",
+			},
+		},
+		{
+			name: "Optional, null and enum fields",
+			opts: []Option{WithDetectEnums(true)},
+			records: []interface{}{
+				map[string]interface{}{"id": 1.0, "email": nil, "note": nil, "status": "open"},
+				map[string]interface{}{"id": 2.0, "email": "x@example.com", "status": "closed"},
+				map[string]interface{}{"id": 3.0, "email": "y@example.com", "status": "open"},
+			},
+			expectContains: []string{
+				"(s/def :generated-struct/email (s/nilable string?))\n",
+				"(s/def :generated-struct/note nil?)\n",
+				"(s/def :generated-struct/status #{\"closed\" \"open\"})\n",
+				"(s/keys :req-un [:generated-struct/email :generated-struct/id :generated-struct/status] :opt-un [:generated-struct/note])",
+			},
+		},
+		{
+			name: "Keys that are not keywords",
+			data: map[string]interface{}{"first name": "a", "ok?": true},
+			expectContains: []string{
+				"(s/def :generated-struct/first_name string?)\n",
+				"(s/def :generated-struct/ok? boolean?)\n",
+			},
+		},
+		{
+			name: "Readable keys keep their names",
+			data: map[string]interface{}{"a b": "x", "a_b": 1.0},
+			expectContains: []string{
+				"(s/def :generated-struct/a_b2 string?)\n",
+				"(s/def :generated-struct/a_b number?)\n",
+			},
+		},
+		{
+			name:           "Non-object root",
+			data:           []interface{}{1.0},
+			expectContains: []string{"(s/def :generated-struct/data (s/coll-of number?))\n"},
+		},
+	})
+}
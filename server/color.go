@@ -0,0 +1,99 @@
+package server
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ANSI escape sequences used to highlight generated code.
+const (
+	colorReset   = "\033[0m"
+	colorKeyword = "\033[35m"
+	colorType    = "\033[32m"
+	colorField   = "\033[36m"
+	colorMeta    = "\033[90m"
+)
+
+var (
+	goTypeDecl    = regexp.MustCompile(`^type (\S+) (.+)$`)
+	goConstMember = regexp.MustCompile(`^(\s+)(\S+) (\S+) = (.+)$`)
+	goField       = regexp.MustCompile("^(\\s+)(\\S+) ([^`]+?)( `[^`]*`)?( //.*)?$")
+
+	rustAttribute = regexp.MustCompile(`^(\s*)(#\[.*\])$`)
+	rustTypeDecl  = regexp.MustCompile(`^(pub )?(struct|enum) (\S+) \{$`)
+	rustField     = regexp.MustCompile(`^(\s+)(pub )?([^\s:]+): (.+?),( //.*)?$`)
+	rustVariant   = regexp.MustCompile(`^(\s+)(\w+),$`)
+)
+
+// paint wraps text in an ANSI color.
+func paint(color, text string) string {
+	return color + text + colorReset
+}
+
+// colorize highlights keywords, type names and field names in generated Go
+// and Rust code. Other formats are returned unchanged.
+func colorize(formatType, code string) string {
+	var colorLine func(string) string
+	switch formatType {
+	case "go":
+		colorLine = colorGoLine
+	case "rust":
+		colorLine = colorRustLine
+	default:
+		return code
+	}
+
+	lines := strings.Split(code, "\n")
+	for i, line := range lines {
+		lines[i] = colorLine(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func colorGoLine(line string) string {
+	if m := goTypeDecl.FindStringSubmatch(line); m != nil {
+		rest := paint(colorType, m[2])
+		if strings.HasPrefix(m[2], "struct") {
+			rest = paint(colorKeyword, "struct") + m[2][len("struct"):]
+		}
+		return paint(colorKeyword, "type") + " " + paint(colorType, m[1]) + " " + rest
+	}
+	if line == "const (" {
+		return paint(colorKeyword, "const") + " ("
+	}
+	if m := goConstMember.FindStringSubmatch(line); m != nil {
+		return m[1] + paint(colorField, m[2]) + " " + paint(colorType, m[3]) + " = " + paint(colorMeta, m[4])
+	}
+	if m := goField.FindStringSubmatch(line); m != nil {
+		return m[1] + paint(colorField, m[2]) + " " + paint(colorType, m[3]) + paint(colorMeta, m[4]+m[5])
+	}
+	return line
+}
+
+func colorRustLine(line string) string {
+	if m := rustAttribute.FindStringSubmatch(line); m != nil {
+		return m[1] + paint(colorMeta, m[2])
+	}
+	if m := rustTypeDecl.FindStringSubmatch(line); m != nil {
+		visibility := ""
+		if m[1] != "" {
+			visibility = paint(colorKeyword, "pub") + " "
+		}
+		return visibility + paint(colorKeyword, m[2]) + " " + paint(colorType, m[3]) + " {"
+	}
+	if m := rustField.FindStringSubmatch(line); m != nil {
+		visibility := ""
+		if m[2] != "" {
+			visibility = paint(colorKeyword, "pub") + " "
+		}
+		comment := m[5]
+		if comment != "" {
+			comment = paint(colorMeta, comment)
+		}
+		return m[1] + visibility + paint(colorField, m[3]) + ": " + paint(colorType, m[4]) + "," + comment
+	}
+	if m := rustVariant.FindStringSubmatch(line); m != nil {
+		return m[1] + paint(colorField, m[2]) + ","
+	}
+	return line
+}
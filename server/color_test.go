@@ -0,0 +1,105 @@
+package server
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestColorize(t *testing.T) {
+	tests := []struct {
+		name           string
+		formatType     string
+		code           string
+		expectContains []string
+	}{
+		{
+			name:       "Go struct",
+			formatType: "go",
+			code:       "type GeneratedStruct struct {\n    name string `json:\"name\"`\n}",
+			expectContains: []string{
+				paint(colorKeyword, "type") + " " + paint(colorType, "GeneratedStruct") + " " + paint(colorKeyword, "struct") + " {",
+				"    " + paint(colorField, "name") + " " + paint(colorType, "string") + paint(colorMeta, " `json:\"name\"`"),
+			},
+		},
+		{
+			name:       "Go field with example comment",
+			formatType: "go",
+			code:       "    name string `json:\"name\"` // e.g. \"test\"",
+			expectContains: []string{
+				"    " + paint(colorField, "name") + " " + paint(colorType, "string") + paint(colorMeta, " `json:\"name\"` // e.g. \"test\""),
+			},
+		},
+		{
+			name:       "Go enum",
+			formatType: "go",
+			code:       "type Status string\n\nconst (\n    StatusActive Status = \"active\"\n)",
+			expectContains: []string{
+				paint(colorKeyword, "type") + " " + paint(colorType, "Status") + " " + paint(colorType, "string"),
+				paint(colorKeyword, "const") + " (",
+				"    " + paint(colorField, "StatusActive") + " " + paint(colorType, "Status") + " = " + paint(colorMeta, `"active"`),
+			},
+		},
+		{
+			name:       "Rust struct",
+			formatType: "rust",
+			code:       "#[derive(Debug, Serialize, Deserialize)]\nstruct GeneratedStruct {\n    #[serde(rename = \"name\")]\n    name: String,\n}",
+			expectContains: []string{
+				paint(colorMeta, "#[derive(Debug, Serialize, Deserialize)]"),
+				paint(colorKeyword, "struct") + " " + paint(colorType, "GeneratedStruct") + " {",
+				"    " + paint(colorMeta, `#[serde(rename = "name")]`),
+				"    " + paint(colorField, "name") + ": " + paint(colorType, "String") + ",",
+			},
+		},
+		{
+			name:       "Rust pub struct",
+			formatType: "rust",
+			code:       "pub struct GeneratedStruct {\n    pub name: String,\n}\n\npub enum Status {\n    Active,\n}",
+			expectContains: []string{
+				paint(colorKeyword, "pub") + " " + paint(colorKeyword, "struct") + " " + paint(colorType, "GeneratedStruct") + " {",
+				"    " + paint(colorKeyword, "pub") + " " + paint(colorField, "name") + ": " + paint(colorType, "String") + ",",
+				paint(colorKeyword, "pub") + " " + paint(colorKeyword, "enum") + " " + paint(colorType, "Status") + " {",
+			},
+		},
+		{
+			name:       "Rust field with example comment",
+			formatType: "rust",
+			code:       "    value: f64, // e.g. 123",
+			expectContains: []string{
+				"    " + paint(colorField, "value") + ": " + paint(colorType, "f64") + "," + paint(colorMeta, " // e.g. 123"),
+			},
+		},
+		{
+			name:           "Other formats are unchanged",
+			formatType:     "zod",
+			code:           "const GeneratedStruct = z.object({});",
+			expectContains: []string{"const GeneratedStruct = z.object({});"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := colorize(tt.formatType, tt.code)
+			for _, expect := range tt.expectContains {
+				if !strings.Contains(result, expect) {
+					t.Errorf("colorize() result does not contain expected string: %q\nGot: %q", expect, result)
+				}
+			}
+		})
+	}
+}
+
+func TestColorize_RustPub(t *testing.T) {
+	srv := New(8080, "rust", false, false, WithRustPub(true))
+	code, err := srv.formatData(map[string]interface{}{"owner": map[string]interface{}{"name": "alice"}})
+	if err != nil {
+		t.Fatalf("formatData() error = %v", err)
+	}
+
+	result := colorize("rust", code)
+	for _, name := range []string{"GeneratedStruct", "Owner"} {
+		expect := paint(colorKeyword, "pub") + " " + paint(colorKeyword, "struct") + " " + paint(colorType, name) + " {"
+		if !strings.Contains(result, expect) {
+			t.Errorf("colorize() result does not contain expected string: %q\nGot: %q", expect, result)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package server
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// crystalKeywords lists the reserved words that cannot be used as property
+// names.
+var crystalKeywords = map[string]bool{
+	"abstract": true, "alias": true, "annotation": true, "as": true,
+	"asm": true, "begin": true, "break": true, "case": true, "class": true,
+	"def": true, "do": true, "else": true, "elsif": true, "end": true,
+	"ensure": true, "enum": true, "extend": true, "false": true, "for": true,
+	"fun": true, "if": true, "in": true, "include": true, "instance_sizeof": true,
+	"is_a": true, "lib": true, "macro": true, "module": true, "next": true,
+	"nil": true, "of": true, "offsetof": true, "out": true, "pointerof": true,
+	"private": true, "protected": true, "require": true, "rescue": true,
+	"return": true, "select": true, "self": true, "sizeof": true,
+	"struct": true, "super": true, "then": true, "true": true, "type": true,
+	"typeof": true, "uninitialized": true, "union": true, "unless": true,
+	"until": true, "verbatim": true, "when": true, "while": true,
+	"with": true, "yield": true,
+}
+
+// crystalPreamble starts Crystal output, loading JSON::Serializable.
+const crystalPreamble = "require \"json\"\n\n"
+
+// crystalBuiltinTypes lists the standard library types that generated
+// structs use or inherit from, which a struct of the same name would reopen
+// rather than declare.
+var crystalBuiltinTypes = map[string]bool{
+	"Array": true, "Bool": true, "Float": true, "Float64": true, "Hash": true,
+	"Int": true, "Int32": true, "Int64": true, "JSON": true, "Nil": true,
+	"Object": true, "String": true, "Struct": true, "Value": true,
+}
+
+func (s *Server) formatAsCrystal(sch *schema) (string, error) {
+	root := sch
+	if sch.kind != kindObject {
+		root = &schema{kind: kindObject, fields: []*field{{name: "data", schema: sch}}}
+	}
+
+	types := s.nestedObjects(s.structName, root)
+	structs := make([]string, 0, len(types.objects))
+	for _, obj := range types.objects {
+		structs = append(structs, s.generateCrystalStruct(obj, types))
+	}
+	return crystalPreamble + strings.Join(structs, "\n\n"), nil
+}
+
+func (s *Server) generateCrystalStruct(obj *objectType, types *objectTypes) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "struct %s\n  include JSON::Serializable\n", obj.name)
+	if len(obj.schema.fields) > 0 {
+		b.WriteString("\n")
+	}
+
+	used := make(map[string]bool)
+	for _, f := range obj.schema.fields {
+		name := crystalName(f.name)
+		base := name
+		for n := 2; used[name]; n++ {
+			name = fmt.Sprintf("%s_%d", base, n)
+		}
+		used[name] = true
+
+		fieldType := s.getCrystalType(f.schema, types)
+		if f.optional {
+			fieldType = crystalNilable(fieldType)
+		}
+		if name != f.name {
+			fmt.Fprintf(&b, "  @[JSON::Field(key: %s)]\n", strconv.Quote(f.name))
+		}
+		fmt.Fprintf(&b, "  property %s : %s\n", name, fieldType)
+	}
+	b.WriteString("end")
+	return b.String()
+}
+
+func (s *Server) getCrystalType(sch *schema, types *objectTypes) string {
+	var crystalType string
+	switch sch.kind {
+	case kindBool:
+		crystalType = "Bool"
+	case kindNumber:
+		crystalType = "Float64"
+		if sch.integral() {
+			crystalType = "Int64"
+		}
+	case kindString:
+		crystalType = "String"
+	case kindArray:
+		elemType := "JSON::Any"
+		if sch.elem != nil {
+			elemType = s.getCrystalType(sch.elem, types)
+		}
+		crystalType = fmt.Sprintf("Array(%s)", elemType)
+	case kindObject:
+		crystalType = types.name(sch)
+	case kindNull:
+		return "JSON::Any?"
+	default:
+		crystalType = "JSON::Any"
+	}
+	if sch.nullable {
+		return crystalNilable(crystalType)
+	}
+	return crystalType
+}
+
+// crystalNilable makes a Crystal type nilable unless it already is.
+func crystalNilable(crystalType string) string {
+	if strings.HasSuffix(crystalType, "?") {
+		return crystalType
+	}
+	return crystalType + "?"
+}
+
+// crystalName converts a JSON key into a snake_case property name, avoiding
+// reserved words.
+func crystalName(key string) string {
+	name := sanitizeIdentifier(toSnakeCase(key))
+	if name == "_" {
+		return "field"
+	}
+	if crystalKeywords[name] {
+		name += "_"
+	}
+	return name
+}
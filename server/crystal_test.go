@@ -0,0 +1,51 @@
+package server
+
+import "testing"
+
+func TestFormatAsCrystal(t *testing.T) {
+	testFormatCases(t, "crystal", []formatCase{
+		{
+			name: "Flat object",
+			data: map[string]interface{}{"name": "test", "value": 123.0, "ratio": 0.5, "active": true},
+			expectContains: []string{
+				"require \"json\"\n\nstruct GeneratedStruct\n  include JSON::Serializable\n\n",
+				"  property active : Bool\n",
+				"  property name : String\n",
+				"  property ratio : Float64\n",
+				"  property value : Int64\n",
+			},
+		},
+		{
+			name: "Nested objects, arrays and renamed keys",
+			data: map[string]interface{}{
+				"userInfo": map[string]interface{}{"id": 1.0},
+				"tags":     []interface{}{"a", "b"},
+				"end":      "soon",
+				"empty":    []interface{}{},
+			},
+			expectContains: []string{
+				"  @[JSON::Field(key: \"end\")]\n  property end_ : String\n",
+				"  property empty : Array(JSON::Any)\n",
+				"  property tags : Array(String)\n",
+				"  @[JSON::Field(key: \"userInfo\")]\n  property user_info : UserInfo\n",
+				"struct UserInfo\n  include JSON::Serializable\n\n  property id : Int64\nend",
+			},
+		},
+		{
+			name: "Optional and null fields",
+			records: []interface{}{
+				map[string]interface{}{"id": 1.0, "email": nil, "note": nil},
+				map[string]interface{}{"id": 2.0, "email": "x@example.com"},
+			},
+			expectContains: []string{
+				"  property email : String?\n",
+				"  property note : JSON::Any?\n",
+			},
+		},
+		{
+			name:           "Non-object root",
+			data:           []interface{}{1.5},
+			expectContains: []string{"  property data : Array(Float64)\n"},
+		},
+	})
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// decodeCSV decodes a CSV document into one record per row, keyed by the
+// column names in the first row.
+func decodeCSV(r io.Reader) ([]interface{}, error) {
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var records []interface{}
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return records, nil
+		} else if err != nil {
+			return nil, err
+		}
+		record := make(map[string]interface{}, len(header))
+		for i, name := range header {
+			record[name] = csvValue(row[i])
+		}
+		records = append(records, record)
+	}
+}
+
+// csvValue converts a CSV cell into the JSON value it most likely holds, so
+// that columns are typed as numbers, booleans or strings. Empty cells are
+// null.
+func csvValue(cell string) interface{} {
+	switch {
+	case cell == "":
+		return nil
+	case numberPattern.MatchString(cell):
+		if n, err := strconv.ParseFloat(cell, 64); err == nil {
+			return n
+		}
+	case strings.EqualFold(cell, "true"):
+		return true
+	case strings.EqualFold(cell, "false"):
+		return false
+	}
+	return cell
+}
+
+// rowsAlias declares name as a slice of the generated struct for CSV bodies,
+// in the formats that have type aliases.
+func (s *Server) rowsAlias(name, structName string) string {
+	switch s.formatType {
+	case "go":
+		return fmt.Sprintf("type %s []%s", name, structName)
+	case "rust":
+		return fmt.Sprintf("%stype %s = Vec<%s>;", s.rustVisibility(), name, structName)
+	default:
+		return ""
+	}
+}
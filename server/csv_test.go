@@ -0,0 +1,49 @@
+package server
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []interface{}
+		wantErr bool
+	}{
+		{
+			name:  "Typed cells",
+			input: "id,name,active,note\n1,alice,TRUE,\n-2.5e1,\"smith, bob\",false,NaN\n",
+			want: []interface{}{
+				map[string]interface{}{"id": 1.0, "name": "alice", "active": true, "note": nil},
+				map[string]interface{}{"id": -25.0, "name": "smith, bob", "active": false, "note": "NaN"},
+			},
+		},
+		{
+			name:  "Header only",
+			input: "id,name\n",
+		},
+		{
+			name: "Empty",
+		},
+		{
+			name:    "Rows of differing length",
+			input:   "id,name\n1,alice,extra\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeCSV(strings.NewReader(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeCSV() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeCSV() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
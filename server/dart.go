@@ -0,0 +1,145 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+)
+
+// dartKeywords lists the reserved words that cannot be used as field names.
+var dartKeywords = map[string]bool{
+	"assert": true, "break": true, "case": true, "catch": true, "class": true,
+	"const": true, "continue": true, "default": true, "do": true, "else": true,
+	"enum": true, "extends": true, "false": true, "final": true, "finally": true,
+	"for": true, "if": true, "in": true, "is": true, "new": true, "null": true,
+	"rethrow": true, "return": true, "super": true, "switch": true, "this": true,
+	"throw": true, "true": true, "try": true, "var": true, "void": true,
+	"while": true, "with": true,
+}
+
+// dartBuiltinTypes lists the dart:core types and json_annotation annotations
+// that generated classes use, which a class of the same name would shadow.
+var dartBuiltinTypes = map[string]bool{
+	"JsonKey": true, "JsonSerializable": true, "List": true, "Map": true,
+	"Null": true, "Object": true, "String": true,
+}
+
+func (s *Server) formatAsDart(sch *schema) (string, error) {
+	root := sch
+	if sch.kind != kindObject {
+		root = &schema{kind: kindObject, fields: []*field{{name: "data", schema: sch}}}
+	}
+
+	types := s.nestedObjects(s.structName, root)
+	classes := make([]string, 0, len(types.objects))
+	for _, obj := range types.objects {
+		classes = append(classes, s.generateDartClass(obj, types))
+	}
+
+	return dartPreamble(s.structName) + strings.Join(classes, "\n\n"), nil
+}
+
+// dartPreamble starts Dart output, importing the annotations and including
+// the part json_serializable generates for the root type.
+func dartPreamble(structName string) string {
+	return fmt.Sprintf("import 'package:json_annotation/json_annotation.dart';\n\npart '%s.g.dart';\n\n", toSnakeCase(structName))
+}
+
+func (s *Server) generateDartClass(obj *objectType, types *objectTypes) string {
+	var fields, params []string
+	used := make(map[string]bool, len(obj.schema.fields))
+	for i, f := range obj.schema.fields {
+		fieldType := s.getDartType(f.schema, types)
+		if f.optional {
+			fieldType = dartNullable(fieldType)
+		}
+
+		// Keys such as "a-b" and "a_b" share a name
+		name := uniqueName(dartFieldName(f.name, i), used)
+		if name != f.name {
+			fields = append(fields, fmt.Sprintf("  @JsonKey(name: %s)", dartString(f.name)))
+		}
+		fields = append(fields, fmt.Sprintf("  final %s %s;", fieldType, name))
+
+		if strings.HasSuffix(fieldType, "?") || fieldType == "dynamic" {
+			params = append(params, "this."+name)
+		} else {
+			params = append(params, "required this."+name)
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "@JsonSerializable()\nclass %s {\n", obj.name)
+	for _, line := range fields {
+		b.WriteString(line + "\n")
+	}
+	if len(fields) > 0 {
+		b.WriteString("\n")
+	}
+	if len(params) > 0 {
+		fmt.Fprintf(&b, "  %s({%s});\n\n", obj.name, strings.Join(params, ", "))
+	} else {
+		fmt.Fprintf(&b, "  %s();\n\n", obj.name)
+	}
+	fmt.Fprintf(&b, "  factory %s.fromJson(Map<String, dynamic> json) => _$%sFromJson(json);\n\n", obj.name, obj.name)
+	fmt.Fprintf(&b, "  Map<String, dynamic> toJson() => _$%sToJson(this);\n}", obj.name)
+	return b.String()
+}
+
+func (s *Server) getDartType(sch *schema, types *objectTypes) string {
+	var dartType string
+	switch sch.kind {
+	case kindBool:
+		dartType = "bool"
+	case kindNumber:
+		dartType = "double"
+	case kindString:
+		dartType = "String"
+	case kindArray:
+		elemType := "dynamic"
+		if sch.elem != nil {
+			elemType = s.getDartType(sch.elem, types)
+		}
+		dartType = fmt.Sprintf("List<%s>", elemType)
+	case kindObject:
+		dartType = types.name(sch)
+	default:
+		return "dynamic"
+	}
+	if sch.nullable {
+		return dartNullable(dartType)
+	}
+	return dartType
+}
+
+// dartNullable marks a Dart type nullable. dynamic already accepts null.
+func dartNullable(dartType string) string {
+	if dartType == "dynamic" || strings.HasSuffix(dartType, "?") {
+		return dartType
+	}
+	return dartType + "?"
+}
+
+// dartFieldName converts a JSON key into a camelCase Dart field name, avoiding
+// reserved words. Keys without an ASCII name, such as CJK keys, are numbered
+// by position instead, as a leading underscore would make the field private.
+func dartFieldName(key string, index int) string {
+	name := toCamelCase(key)
+	if name != "" && name[0] >= '0' && name[0] <= '9' {
+		name = "n" + name
+	}
+	if !isIdentifier(name) {
+		return fmt.Sprintf("field%d", index)
+	}
+	if dartKeywords[name] {
+		name += "Value"
+	}
+	return name
+}
+
+// dartString quotes a value as a single-quoted Dart string literal.
+func dartString(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, "'", `\'`)
+	value = strings.ReplaceAll(value, "$", `\$`)
+	return "'" + value + "'"
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatAsDart(t *testing.T) {
+	tests := []struct {
+		name           string
+		data           interface{}
+		records        []interface{}
+		expectContains []string
+	}{
+		{
+			name: "Flat object",
+			data: map[string]interface{}{
+				"string_field": "test",
+				"number_field": 123.45,
+				"bool_field":   true,
+				"array_field":  []interface{}{1, 2, 3},
+			},
+			expectContains: []string{
+				"import 'package:json_annotation/json_annotation.dart';",
+				"@JsonSerializable()\nclass GeneratedStruct {",
+				"  @JsonKey(name: 'string_field')\n  final String stringField;",
+				"  final double numberField;",
+				"  final bool boolField;",
+				"  final List<double> arrayField;",
+				"factory GeneratedStruct.fromJson(Map<String, dynamic> json) => _$GeneratedStructFromJson(json);",
+				"Map<String, dynamic> toJson() => _$GeneratedStructToJson(this);",
+			},
+		},
+		{
+			name: "Nested objects",
+			data: map[string]interface{}{
+				"user": map[string]interface{}{"name": "test"},
+			},
+			expectContains: []string{
+				"  final User user;",
+				"  GeneratedStruct({required this.user});",
+				"class User {\n  final String name;",
+			},
+		},
+		{
+			name: "Optional, null and reserved fields",
+			records: []interface{}{
+				map[string]interface{}{"email": nil, "class": "a", "extra": nil},
+				map[string]interface{}{"email": "x@example.com", "class": "b"},
+			},
+			expectContains: []string{
+				"  @JsonKey(name: 'class')\n  final String classValue;",
+				"  final String? email;",
+				"  final dynamic extra;",
+				"  GeneratedStruct({required this.classValue, this.email, this.extra});",
+			},
+		},
+		{
+			name: "Colliding keys",
+			data: map[string]interface{}{"a-b": 1.0, "a_b": "x"},
+			expectContains: []string{
+				"  @JsonKey(name: 'a-b')\n  final double ab;",
+				"  @JsonKey(name: 'a_b')\n  final String ab2;",
+				"  GeneratedStruct({required this.ab, required this.ab2});",
+			},
+		},
+		{
+			name: "CJK key",
+			data: map[string]interface{}{"id": 1.0, "名前": "x"},
+			expectContains: []string{
+				"  @JsonKey(name: '名前')\n  final String field1;",
+				"  GeneratedStruct({required this.id, required this.field1});",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sch := inferSchema(tt.data)
+			if tt.records != nil {
+				sch = inferRecords(tt.records)
+			}
+
+			srv := New(8080, "dart", false, false)
+			result, err := srv.formatSchema(sch)
+			if err != nil {
+				t.Fatalf("formatSchema() error = %v", err)
+			}
+
+			for _, expect := range tt.expectContains {
+				if !strings.Contains(result, expect) {
+					t.Errorf("formatSchema() result does not contain expected string: %s\nGot: %s", expect, result)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package server
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// minBase64Length keeps short words that happen to be valid base64, such as
+// "test", from being treated as binary.
+const minBase64Length = 16
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// numberPattern matches the JSON number grammar, which is what Go's ,string
+// option expects inside the quotes.
+var numberPattern = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+// allStrings reports whether a string schema has samples and every one of them
+// satisfies match.
+func allStrings(sch *schema, match func(string) bool) bool {
+	if sch.kind != kindString || len(sch.samples) == 0 {
+		return false
+	}
+	for _, sample := range sch.samples {
+		if !match(sample.(string)) {
+			return false
+		}
+	}
+	return true
+}
+
+// isUUID reports whether every sample of a string schema is a UUID.
+func (s *Server) isUUID(sch *schema) bool {
+	return s.detectUUID && allStrings(sch, uuidPattern.MatchString)
+}
+
+// isIP reports whether every sample of a string schema is an IPv4 or IPv6
+// address.
+func (s *Server) isIP(sch *schema) bool {
+	return s.detectNet && allStrings(sch, isIPString)
+}
+
+// isCIDR reports whether every sample of a string schema is an IP network in
+// CIDR notation, such as "10.0.0.0/8".
+func (s *Server) isCIDR(sch *schema) bool {
+	return s.detectNet && allStrings(sch, isCIDRString)
+}
+
+func isIPString(value string) bool {
+	return net.ParseIP(value) != nil
+}
+
+func isCIDRString(value string) bool {
+	_, _, err := net.ParseCIDR(value)
+	return err == nil
+}
+
+// isDateString reports whether value is an RFC 3339 timestamp, such as
+// "2024-05-01T12:30:00Z", or a full date, such as "2024-05-01".
+func isDateString(value string) bool {
+	if _, err := time.Parse(time.RFC3339, value); err == nil {
+		return true
+	}
+	_, err := time.Parse(time.DateOnly, value)
+	return err == nil
+}
+
+// isBase64 reports whether every sample of a string schema is standard base64
+// that decodes to binary rather than text.
+func (s *Server) isBase64(sch *schema) bool {
+	return s.detectBase64 && allStrings(sch, isBinaryBase64)
+}
+
+// isNumericString reports whether every sample of a string schema is a number
+// written as a string, such as "42".
+func (s *Server) isNumericString(sch *schema) bool {
+	return s.coerceNumericStrings && allStrings(sch, numberPattern.MatchString)
+}
+
+// numericStringNumbers returns a number schema of the samples of a numeric
+// string schema, so that they are typed like plain numbers.
+func numericStringNumbers(sch *schema) *schema {
+	numbers := &schema{kind: kindNumber, samples: make([]interface{}, len(sch.samples))}
+	for i, sample := range sch.samples {
+		numbers.samples[i] = json.Number(sample.(string))
+	}
+	return numbers
+}
+
+// isDuration reports whether every sample of a string schema is a Go style
+// duration, such as "5m30s".
+func (s *Server) isDuration(sch *schema) bool {
+	return s.detectDurations && allStrings(sch, isDurationString)
+}
+
+// isDurationString reports whether value parses with time.ParseDuration. A
+// unit is required, so that the bare "0" stays a string.
+func isDurationString(value string) bool {
+	if _, err := time.ParseDuration(value); err != nil {
+		return false
+	}
+	return strings.IndexFunc(value, unicode.IsLetter) >= 0
+}
+
+func isBinaryBase64(value string) bool {
+	if len(value) < minBase64Length {
+		return false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return false
+	}
+	return !isText(decoded)
+}
+
+// isText reports whether data is UTF-8 without control characters other than
+// common whitespace.
+func isText(data []byte) bool {
+	if !utf8.Valid(data) {
+		return false
+	}
+	for _, r := range string(data) {
+		if unicode.IsControl(r) && r != '\n' && r != '\r' && r != '\t' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,377 @@
+package server
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatData_DetectUUID(t *testing.T) {
+	testData := map[string]interface{}{
+		"id":   "123e4567-e89b-12d3-a456-426614174000",
+		"name": "test",
+	}
+
+	tests := []struct {
+		name           string
+		formatType     string
+		detectUUID     bool
+		expectContains []string
+	}{
+		{
+			name:           "Go format",
+			formatType:     "go",
+			detectUUID:     true,
+			expectContains: []string{"id uuid.UUID `json:\"id\"`", "name string `json:\"name\"`"},
+		},
+		{
+			name:           "Rust format",
+			formatType:     "rust",
+			detectUUID:     true,
+			expectContains: []string{"id: uuid::Uuid,", "name: String,"},
+		},
+		{
+			name:           "Disabled",
+			formatType:     "go",
+			expectContains: []string{"id string `json:\"id\"`"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := New(8080, tt.formatType, false, false, WithDetectUUID(tt.detectUUID))
+			result, err := srv.formatData(testData)
+			if err != nil {
+				t.Fatalf("formatData() error = %v", err)
+			}
+
+			for _, expect := range tt.expectContains {
+				if !strings.Contains(result, expect) {
+					t.Errorf("formatData() result does not contain expected string: %s\nGot: %s", expect, result)
+				}
+			}
+		})
+	}
+}
+
+func TestFormatData_DetectBase64(t *testing.T) {
+	testData := map[string]interface{}{
+		"avatar": "iVBORw0KGgoAAAANSUhEUgAAAAEAAAAB",
+		"note":   "dGhpcyBpcyBwbGFpbiB0ZXh0",
+		"name":   "test",
+	}
+
+	tests := []struct {
+		name           string
+		formatType     string
+		detectBase64   bool
+		expectContains []string
+	}{
+		{
+			name:         "Go format",
+			formatType:   "go",
+			detectBase64: true,
+			expectContains: []string{
+				"avatar []byte `json:\"avatar\"`",
+				"note string `json:\"note\"`",
+				"name string `json:\"name\"`",
+			},
+		},
+		{
+			name:         "Rust format",
+			formatType:   "rust",
+			detectBase64: true,
+			expectContains: []string{
+				"#[serde_with::serde_as]\n#[derive(Debug, Serialize, Deserialize)]",
+				"    #[serde_as(as = \"serde_with::base64::Base64\")]\n    avatar: Vec<u8>,",
+				"note: String,",
+			},
+		},
+		{
+			name:           "Disabled",
+			formatType:     "go",
+			expectContains: []string{"avatar string `json:\"avatar\"`"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := New(8080, tt.formatType, false, false, WithDetectBase64(tt.detectBase64))
+			result, err := srv.formatData(testData)
+			if err != nil {
+				t.Fatalf("formatData() error = %v", err)
+			}
+
+			for _, expect := range tt.expectContains {
+				if !strings.Contains(result, expect) {
+					t.Errorf("formatData() result does not contain expected string: %s\nGot: %s", expect, result)
+				}
+			}
+		})
+	}
+}
+
+func TestFormatData_CoerceNumericStrings(t *testing.T) {
+	testData := map[string]interface{}{
+		"age":   "42",
+		"price": "-1.5e3",
+		"code":  "0x1F",
+		"name":  "test",
+	}
+
+	tests := []struct {
+		name           string
+		formatType     string
+		coerce         bool
+		options        []Option
+		expectContains []string
+	}{
+		{
+			name:       "Go format",
+			formatType: "go",
+			coerce:     true,
+			expectContains: []string{
+				"age int `json:\"age,string\"`",
+				"price float64 `json:\"price,string\"`",
+				"code string `json:\"code\"`",
+				"name string `json:\"name\"`",
+			},
+		},
+		{
+			name:       "Rust format",
+			formatType: "rust",
+			coerce:     true,
+			expectContains: []string{
+				"#[serde_with::serde_as]\n#[derive(Debug, Serialize, Deserialize)]",
+				"    #[serde_as(as = \"serde_with::DisplayFromStr\")]\n    age: i64,",
+				"    #[serde_as(as = \"serde_with::DisplayFromStr\")]\n    price: f64,",
+				"code: String,",
+			},
+		},
+		{
+			name:       "Go narrow ints",
+			formatType: "go",
+			coerce:     true,
+			options:    []Option{WithNarrowInts(true)},
+			expectContains: []string{
+				"age int32 `json:\"age,string\"`",
+				"price float64 `json:\"price,string\"`",
+			},
+		},
+		{
+			name:           "Rust narrow ints",
+			formatType:     "rust",
+			coerce:         true,
+			options:        []Option{WithNarrowInts(true)},
+			expectContains: []string{"    age: i32,", "    price: f64,"},
+		},
+		{
+			name:       "Go number type",
+			formatType: "go",
+			coerce:     true,
+			options:    []Option{WithNumberType("json.Number")},
+			expectContains: []string{
+				"age json.Number `json:\"age\"`",
+				"price json.Number `json:\"price\"`",
+			},
+		},
+		{
+			name:           "Rust number type",
+			formatType:     "rust",
+			coerce:         true,
+			options:        []Option{WithNumberType("u64")},
+			expectContains: []string{"    age: u64,", "    price: u64,"},
+		},
+		{
+			name:           "Disabled",
+			formatType:     "go",
+			expectContains: []string{"age string `json:\"age\"`"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := New(8080, tt.formatType, false, false, append([]Option{WithCoerceNumericStrings(tt.coerce)}, tt.options...)...)
+			result, err := srv.formatData(testData)
+			if err != nil {
+				t.Fatalf("formatData() error = %v", err)
+			}
+
+			for _, expect := range tt.expectContains {
+				if !strings.Contains(result, expect) {
+					t.Errorf("formatData() result does not contain expected string: %s\nGot: %s", expect, result)
+				}
+			}
+		})
+	}
+}
+
+func TestFormatData_DetectorPrecedence(t *testing.T) {
+	records := []interface{}{
+		map[string]interface{}{"n": "1234567890123456", "state": "c29tZSBiaW5hcnkgZGF0YQ=="},
+		map[string]interface{}{"n": "1234567890123457", "state": "c29tZSBiaW5hcnkgZGF0YQ=="},
+	}
+	options := []Option{WithDetectBase64(true), WithCoerceNumericStrings(true), WithDetectEnums(true)}
+
+	tests := []struct {
+		formatType     string
+		expectContains []string
+		expectMissing  []string
+	}{
+		{
+			formatType: "go",
+			expectContains: []string{
+				"n int `json:\"n,string\"`",
+				"state State `json:\"state\"`",
+			},
+		},
+		{
+			formatType: "rust",
+			expectContains: []string{
+				"    #[serde_as(as = \"serde_with::DisplayFromStr\")]\n    n: i64,",
+				"    state: State,",
+			},
+			expectMissing: []string{"Base64"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.formatType, func(t *testing.T) {
+			srv := New(8080, tt.formatType, false, false, options...)
+			result, err := srv.formatSchema(inferRecords(records))
+			if err != nil {
+				t.Fatalf("formatSchema() error = %v", err)
+			}
+
+			for _, expect := range tt.expectContains {
+				if !strings.Contains(result, expect) {
+					t.Errorf("formatSchema() result does not contain expected string: %s\nGot: %s", expect, result)
+				}
+			}
+			for _, missing := range tt.expectMissing {
+				if strings.Contains(result, missing) {
+					t.Errorf("formatSchema() result contains unexpected string: %s\nGot: %s", missing, result)
+				}
+			}
+		})
+	}
+}
+
+func TestFormatData_DetectDurations(t *testing.T) {
+	testData := map[string]interface{}{
+		"timeout":  "5m30s",
+		"interval": "1.5h",
+		"zero":     "0",
+		"name":     "test",
+	}
+
+	tests := []struct {
+		name           string
+		formatType     string
+		detect         bool
+		expectContains []string
+	}{
+		{
+			name:       "Go format",
+			formatType: "go",
+			detect:     true,
+			expectContains: []string{
+				"type Duration struct {\n    time.Duration\n}",
+				"func (d *Duration) UnmarshalJSON(data []byte) error {",
+				"parsed, err := time.ParseDuration(s)",
+				"interval Duration `json:\"interval\"`",
+				"timeout Duration `json:\"timeout\"`",
+				"zero string `json:\"zero\"`",
+				"name string `json:\"name\"`",
+			},
+		},
+		{
+			name:       "Rust format",
+			formatType: "rust",
+			detect:     true,
+			expectContains: []string{
+				"    #[serde(with = \"humantime_serde\")]\n    timeout: std::time::Duration,",
+				"name: String,",
+			},
+		},
+		{
+			name:           "Disabled",
+			formatType:     "go",
+			expectContains: []string{"timeout string `json:\"timeout\"`"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := New(8080, tt.formatType, false, false, WithDetectDurations(tt.detect))
+			result, err := srv.formatData(testData)
+			if err != nil {
+				t.Fatalf("formatData() error = %v", err)
+			}
+
+			for _, expect := range tt.expectContains {
+				if !strings.Contains(result, expect) {
+					t.Errorf("formatData() result does not contain expected string: %s\nGot: %s", expect, result)
+				}
+			}
+		})
+	}
+}
+
+func TestFormatData_DetectNet(t *testing.T) {
+	testData := map[string]interface{}{
+		"addr":    "192.168.1.10",
+		"addr6":   "2001:db8::1",
+		"subnet":  "10.0.0.0/8",
+		"version": "1.2.3",
+	}
+
+	tests := []struct {
+		name           string
+		formatType     string
+		detect         bool
+		expectContains []string
+	}{
+		{
+			name:       "Go format",
+			formatType: "go",
+			detect:     true,
+			expectContains: []string{
+				"addr net.IP `json:\"addr\"`",
+				"addr6 net.IP `json:\"addr6\"`",
+				"subnet *net.IPNet `json:\"subnet\"`",
+				"version string `json:\"version\"`",
+			},
+		},
+		{
+			name:       "Rust format",
+			formatType: "rust",
+			detect:     true,
+			expectContains: []string{
+				"addr: std::net::IpAddr,",
+				"subnet: ipnet::IpNet,",
+				"version: String,",
+			},
+		},
+		{
+			name:           "Disabled",
+			formatType:     "go",
+			expectContains: []string{"addr string `json:\"addr\"`", "subnet string `json:\"subnet\"`"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := New(8080, tt.formatType, false, false, WithDetectNet(tt.detect))
+			result, err := srv.formatData(testData)
+			if err != nil {
+				t.Fatalf("formatData() error = %v", err)
+			}
+
+			for _, expect := range tt.expectContains {
+				if !strings.Contains(result, expect) {
+					t.Errorf("formatData() result does not contain expected string: %s\nGot: %s", expect, result)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// duplicateKeys lists, once each and in the order they are met, the paths of
+// the keys repeated within one object, such as "owner.name" or "items[].id".
+// encoding/json keeps the last value of a repeated key, so the earlier ones
+// vanish from the generated type without notice. The body may hold several
+// values, as NDJSON and JSON text sequences do.
+func duplicateKeys(body []byte) ([]string, error) {
+	dec := json.NewDecoder(bytes.NewReader(bytes.ReplaceAll(body, []byte{recordSeparator}, []byte{'\n'})))
+	var duplicates []string
+	for {
+		if err := scanDuplicateKeys(dec, "", &duplicates); err == io.EOF {
+			return duplicates, nil
+		} else if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// scanDuplicateKeys reads one value from dec, appending the paths of the keys
+// repeated within its objects that are not listed yet.
+func scanDuplicateKeys(dec *json.Decoder, path string, duplicates *[]string) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	switch token {
+	case json.Delim('{'):
+		seen := make(map[string]bool)
+		for dec.More() {
+			token, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			key, ok := token.(string)
+			if !ok {
+				return fmt.Errorf("unexpected object key %v", token)
+			}
+			keyPath := key
+			if path != "" {
+				keyPath = path + "." + key
+			}
+			if seen[key] && !slices.Contains(*duplicates, keyPath) {
+				*duplicates = append(*duplicates, keyPath)
+			}
+			seen[key] = true
+			if err := scanDuplicateKeys(dec, keyPath, duplicates); err != nil {
+				return err
+			}
+		}
+		_, err := dec.Token()
+		return err
+	case json.Delim('['):
+		for dec.More() {
+			if err := scanDuplicateKeys(dec, path+"[]", duplicates); err != nil {
+				return err
+			}
+		}
+		_, err := dec.Token()
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDuplicateKeys(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected []string
+	}{
+		{
+			name: "No duplicates",
+			body: `{"id":1,"owner":{"id":2}}`,
+		},
+		{
+			name:     "Top-level key",
+			body:     `{"id":1,"name":"a","id":2}`,
+			expected: []string{"id"},
+		},
+		{
+			name:     "Nested and array keys",
+			body:     `{"owner":{"name":"a","name":"b"},"items":[{"id":1,"id":2},{"id":3,"id":4}]}`,
+			expected: []string{"owner.name", "items[].id"},
+		},
+		{
+			name:     "Several NDJSON records",
+			body:     "{\"a\":1}\n{\"b\":1,\"b\":2}\n",
+			expected: []string{"b"},
+		},
+		{
+			name:     "JSON text sequence",
+			body:     "\x1e{\"a\":1,\"a\":2}\n",
+			expected: []string{"a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := duplicateKeys([]byte(tt.body))
+			if err != nil {
+				t.Fatalf("duplicateKeys() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("duplicateKeys() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestServer_StrictKeys(t *testing.T) {
+	body := `{"id":1,"name":"alice","id":2}`
+	tests := []struct {
+		name           string
+		opts           []Option
+		expectedCode   int
+		expectContains string
+	}{
+		{
+			name:           "Warning by default",
+			expectedCode:   http.StatusOK,
+			expectContains: "Warning: Duplicate keys in JSON: id, only the last value of each is kept",
+		},
+		{
+			name:           "Refused in strict mode",
+			opts:           []Option{WithStrictKeys(true)},
+			expectedCode:   http.StatusBadRequest,
+			expectContains: "Duplicate keys in JSON: id",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logBuf strings.Builder
+			log.SetOutput(&logBuf)
+			defer log.SetOutput(os.Stderr)
+
+			srv := New(8080, "go", false, false, tt.opts...)
+			req := httptest.NewRequest("POST", "/api/data", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			rr := httptest.NewRecorder()
+			srv.handleRequest(rr, req)
+
+			if rr.Code != tt.expectedCode {
+				t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, tt.expectedCode)
+			}
+			if !strings.Contains(logBuf.String(), tt.expectContains) {
+				t.Errorf("log does not contain expected string: %s\nGot: %s", tt.expectContains, logBuf.String())
+			}
+			if tt.expectedCode == http.StatusBadRequest && !strings.Contains(responseText(rr.Body.String()), tt.expectContains) {
+				t.Errorf("response does not contain expected string: %s\nGot: %s", tt.expectContains, rr.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// elmKeywords lists the reserved words that cannot be used as field names.
+var elmKeywords = map[string]bool{
+	"alias": true, "as": true, "case": true, "else": true, "exposing": true,
+	"if": true, "import": true, "in": true, "infix": true, "let": true,
+	"module": true, "of": true, "port": true, "then": true, "type": true,
+	"where": true,
+}
+
+// elmBuiltinTypes lists the types imported by default and Decoder, which a
+// type alias of the same name would shadow.
+var elmBuiltinTypes = map[string]bool{
+	"Bool": true, "Char": true, "Cmd": true, "Decoder": true, "Float": true,
+	"Int": true, "List": true, "Maybe": true, "Never": true, "Order": true,
+	"Program": true, "Result": true, "String": true, "Sub": true,
+}
+
+func (s *Server) formatAsElm(sch *schema) (string, error) {
+	root := sch
+	if sch.kind != kindObject {
+		root = &schema{kind: kindObject, fields: []*field{{name: "data", schema: sch}}}
+	}
+
+	types := s.nestedObjects(s.structName, root)
+	aliases := make([]string, 0, len(types.objects))
+	for _, obj := range types.objects {
+		aliases = append(aliases, s.generateElmAlias(obj, types))
+	}
+
+	return elmPreamble(s.structName) + strings.Join(aliases, "\n\n\n") + elmFooter, nil
+}
+
+// elmPreamble starts Elm output with the module declaration and imports.
+func elmPreamble(structName string) string {
+	return fmt.Sprintf("module %s exposing (..)\n\nimport Json.Decode as Decode exposing (Decoder)\n\n\n", structName)
+}
+
+// elmFooter ends Elm output with andMap, which lets decoders take any number
+// of fields, unlike Decode.map8.
+const elmFooter = "\n\n\nandMap : Decoder a -> Decoder (a -> b) -> Decoder b\nandMap =\n    Decode.map2 (|>)\n"
+
+func (s *Server) generateElmAlias(obj *objectType, types *objectTypes) string {
+	var fields, decoders []string
+	taken := make(map[string]bool)
+	for i, f := range obj.schema.fields {
+		fieldType := s.getElmType(f.schema, types)
+		decoder := fmt.Sprintf("Decode.field %s %s", elmString(f.name), elmParens(s.getElmDecoder(f.schema, types)))
+		if f.optional {
+			if strings.HasPrefix(fieldType, "Maybe ") {
+				// Flatten a missing key and a null value into one Nothing
+				decoder = fmt.Sprintf("Decode.map (Maybe.andThen identity) (Decode.maybe (%s))", decoder)
+			} else {
+				fieldType = elmMaybe(fieldType)
+				decoder = fmt.Sprintf("Decode.maybe (%s)", decoder)
+			}
+		}
+
+		name := elmFieldName(f.name, i)
+		base := name
+		for n := 2; taken[name]; n++ {
+			name = fmt.Sprintf("%s%d", base, n)
+		}
+		taken[name] = true
+
+		fields = append(fields, fmt.Sprintf("%s : %s", name, fieldType))
+		decoders = append(decoders, fmt.Sprintf("        |> andMap (%s)", decoder))
+	}
+
+	var b strings.Builder
+	if len(fields) == 0 {
+		fmt.Fprintf(&b, "type alias %s =\n    {}\n\n\n", obj.name)
+	} else {
+		fmt.Fprintf(&b, "type alias %s =\n    { %s\n    }\n\n\n", obj.name, strings.Join(fields, "\n    , "))
+	}
+	decoderName := elmDecoderName(obj.name)
+	fmt.Fprintf(&b, "%s : Decoder %s\n%s =\n    Decode.succeed %s", decoderName, obj.name, decoderName, obj.name)
+	for _, line := range decoders {
+		b.WriteString("\n" + line)
+	}
+	return b.String()
+}
+
+func (s *Server) getElmType(sch *schema, types *objectTypes) string {
+	var elmType string
+	switch sch.kind {
+	case kindBool:
+		elmType = "Bool"
+	case kindNumber:
+		elmType = "Float"
+	case kindString:
+		elmType = "String"
+	case kindArray:
+		elemType := "Decode.Value"
+		if sch.elem != nil {
+			elemType = s.getElmType(sch.elem, types)
+		}
+		elmType = "List " + elmParens(elemType)
+	case kindObject:
+		elmType = types.name(sch)
+	case kindNull:
+		return "Maybe Decode.Value"
+	default:
+		elmType = "Decode.Value"
+	}
+	if sch.nullable {
+		return elmMaybe(elmType)
+	}
+	return elmType
+}
+
+func (s *Server) getElmDecoder(sch *schema, types *objectTypes) string {
+	var decoder string
+	switch sch.kind {
+	case kindBool:
+		decoder = "Decode.bool"
+	case kindNumber:
+		decoder = "Decode.float"
+	case kindString:
+		decoder = "Decode.string"
+	case kindArray:
+		elemDecoder := "Decode.value"
+		if sch.elem != nil {
+			elemDecoder = s.getElmDecoder(sch.elem, types)
+		}
+		decoder = "Decode.list " + elmParens(elemDecoder)
+	case kindObject:
+		decoder = elmDecoderName(types.name(sch))
+	case kindNull:
+		return "Decode.nullable Decode.value"
+	default:
+		decoder = "Decode.value"
+	}
+	if sch.nullable {
+		return "Decode.nullable " + elmParens(decoder)
+	}
+	return decoder
+}
+
+// elmMaybe wraps an Elm type in Maybe unless it already is one.
+func elmMaybe(elmType string) string {
+	if strings.HasPrefix(elmType, "Maybe ") {
+		return elmType
+	}
+	return "Maybe " + elmParens(elmType)
+}
+
+// elmParens parenthesizes a type or expression made of several words so it
+// can be used as an argument.
+func elmParens(expr string) string {
+	if strings.Contains(expr, " ") {
+		return "(" + expr + ")"
+	}
+	return expr
+}
+
+// elmFieldName converts a JSON key to a camelCase record field name, falling
+// back to the field position for keys without an ASCII name, such as CJK keys.
+func elmFieldName(key string, index int) string {
+	name := toCamelCase(key)
+	if name != "" && unicode.IsDigit(rune(name[0])) {
+		name = "n" + name
+	}
+	if !isIdentifier(name) {
+		return fmt.Sprintf("field%d", index)
+	}
+	if elmKeywords[name] {
+		name += "_"
+	}
+	return name
+}
+
+// elmDecoderName names the decoder of a type alias, e.g. userInfoDecoder.
+func elmDecoderName(typeName string) string {
+	return toCamelCase(typeName) + "Decoder"
+}
+
+// elmString quotes a value as an Elm string literal.
+func elmString(value string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range value {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if unicode.IsControl(r) {
+				fmt.Fprintf(&b, `\u{%04X}`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,80 @@
+package server
+
+import "testing"
+
+func TestFormatAsElm(t *testing.T) {
+	testFormatCases(t, "elm", []formatCase{
+		{
+			name: "Flat object",
+			data: map[string]interface{}{"name": "test", "value": 123.0, "active": true},
+			expectContains: []string{
+				"module GeneratedStruct exposing (..)",
+				"type alias GeneratedStruct =\n    { active : Bool\n    , name : String\n    , value : Float\n    }",
+				"generatedStructDecoder : Decoder GeneratedStruct\ngeneratedStructDecoder =\n    Decode.succeed GeneratedStruct\n" +
+					"        |> andMap (Decode.field \"active\" Decode.bool)\n" +
+					"        |> andMap (Decode.field \"name\" Decode.string)\n" +
+					"        |> andMap (Decode.field \"value\" Decode.float)",
+				"andMap =\n    Decode.map2 (|>)",
+			},
+		},
+		{
+			name: "Nested objects, arrays and renamed keys",
+			data: map[string]interface{}{
+				"user_info": map[string]interface{}{"full_name": "test"},
+				"tags":      []interface{}{"a", "b"},
+				"type":      "admin",
+			},
+			expectContains: []string{
+				"tags : List String",
+				"type_ : String",
+				"userInfo : UserInfo",
+				`|> andMap (Decode.field "tags" (Decode.list Decode.string))`,
+				`|> andMap (Decode.field "user_info" userInfoDecoder)`,
+				"type alias UserInfo =\n    { fullName : String\n    }",
+				`|> andMap (Decode.field "full_name" Decode.string)`,
+			},
+		},
+		{
+			name: "Optional and null fields",
+			records: []interface{}{
+				map[string]interface{}{"id": 1.0, "email": nil, "tags": []interface{}{"a"}},
+				map[string]interface{}{"id": 2.0, "email": "x@example.com", "nickname": nil},
+			},
+			expectContains: []string{
+				"email : Maybe String",
+				`|> andMap (Decode.field "email" (Decode.nullable Decode.string))`,
+				"nickname : Maybe Decode.Value",
+				`|> andMap (Decode.map (Maybe.andThen identity) (Decode.maybe (Decode.field "nickname" (Decode.nullable Decode.value))))`,
+				"tags : Maybe (List String)",
+				`|> andMap (Decode.maybe (Decode.field "tags" (Decode.list Decode.string)))`,
+			},
+		},
+		{
+			name: "Reserved words and invalid identifiers",
+			data: map[string]interface{}{"import": "a", "where": "b", "2fa": true},
+			expectContains: []string{
+				"import_ : String",
+				"where_ : String",
+				"n2fa : Bool",
+				"|> andMap (Decode.field \"2fa\" Decode.bool)",
+				"|> andMap (Decode.field \"import\" Decode.string)",
+			},
+		},
+		{
+			name: "CJK key",
+			data: map[string]interface{}{"id": 1.0, "名前": "x"},
+			expectContains: []string{
+				"field1 : String",
+				"|> andMap (Decode.field \"名前\" Decode.string)",
+			},
+			expectMissing: []string{"名前 :"},
+		},
+		{
+			name: "Non-object root",
+			data: []interface{}{"a", "b"},
+			expectContains: []string{
+				"type alias GeneratedStruct =\n    { data : List String\n    }",
+			},
+		},
+	})
+}
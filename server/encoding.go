@@ -0,0 +1,64 @@
+package server
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+)
+
+// errUnsupportedEncoding is returned for content codings reqparser cannot
+// decode.
+type errUnsupportedEncoding string
+
+func (e errUnsupportedEncoding) Error() string {
+	return fmt.Sprintf("unsupported Content-Encoding: %s", string(e))
+}
+
+// decodeBody wraps the request body in readers that undo its
+// Content-Encoding. Codings are listed in the order they were applied, so
+// they are removed from last to first.
+func decodeBody(r *http.Request) (io.Reader, error) {
+	var body io.Reader = r.Body
+	codings := strings.Split(r.Header.Get("Content-Encoding"), ",")
+	for i := len(codings) - 1; i >= 0; i-- {
+		coding := strings.ToLower(strings.TrimSpace(codings[i]))
+		var err error
+		switch coding {
+		case "", "identity":
+			continue
+		case "gzip", "x-gzip":
+			body, err = gzip.NewReader(body)
+		case "deflate":
+			body, err = newDeflateReader(body)
+		case "br":
+			body = brotli.NewReader(body)
+		default:
+			return nil, errUnsupportedEncoding(coding)
+		}
+		if err == io.EOF {
+			// An encoded but empty body has nothing to decode
+			return http.NoBody, nil
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	return body, nil
+}
+
+// newDeflateReader reads the "deflate" coding, which is meant to be zlib
+// wrapped but is sent as raw deflate by some clients.
+func newDeflateReader(r io.Reader) (io.Reader, error) {
+	buffered := bufio.NewReader(r)
+	header, err := buffered.Peek(2)
+	if err == nil && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(buffered)
+	}
+	return flate.NewReader(buffered), nil
+}
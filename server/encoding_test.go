@@ -0,0 +1,99 @@
+package server
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+)
+
+func TestHandleRequest_ContentEncoding(t *testing.T) {
+	const payload = `{"name":"test"}`
+
+	compress := func(newWriter func(io.Writer) io.WriteCloser) []byte {
+		var buf bytes.Buffer
+		w := newWriter(&buf)
+		if _, err := w.Write([]byte(payload)); err != nil {
+			t.Fatalf("Failed to compress payload: %v", err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("Failed to compress payload: %v", err)
+		}
+		return buf.Bytes()
+	}
+
+	tests := []struct {
+		name         string
+		encoding     string
+		body         []byte
+		expectedCode int
+	}{
+		{
+			name:         "gzip",
+			encoding:     "gzip",
+			body:         compress(func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) }),
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "deflate",
+			encoding:     "deflate",
+			body:         compress(func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) }),
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:     "raw deflate",
+			encoding: "deflate",
+			body: compress(func(w io.Writer) io.WriteCloser {
+				fw, _ := flate.NewWriter(w, flate.DefaultCompression)
+				return fw
+			}),
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "brotli",
+			encoding:     "br",
+			body:         compress(func(w io.Writer) io.WriteCloser { return brotli.NewWriter(w) }),
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Unknown encoding",
+			encoding:     "zstd",
+			body:         []byte(payload),
+			expectedCode: http.StatusUnsupportedMediaType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logBuf bytes.Buffer
+			log.SetOutput(&logBuf)
+			defer log.SetOutput(os.Stderr)
+
+			srv := New(8080, "", false, false)
+			req := httptest.NewRequest("POST", "/api/data", bytes.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Content-Encoding", tt.encoding)
+			rr := httptest.NewRecorder()
+			srv.handleRequest(rr, req)
+
+			if rr.Code != tt.expectedCode {
+				t.Fatalf("Handler returned wrong status code: got %v want %v: %s", rr.Code, tt.expectedCode, rr.Body.String())
+			}
+			if tt.expectedCode == http.StatusOK && !strings.Contains(logBuf.String(), "JSON-Body: "+payload) {
+				t.Errorf("Log output does not contain decoded body\nGot: %s", logBuf.String())
+			}
+			if tt.expectedCode == http.StatusUnsupportedMediaType && !strings.Contains(rr.Body.String(), "unsupported Content-Encoding: zstd") {
+				t.Errorf("Response does not name the encoding: %s", rr.Body.String())
+			}
+		})
+	}
+}
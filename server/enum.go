@@ -0,0 +1,135 @@
+package server
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// maxEnumValues is the largest number of distinct values a string field may
+// take and still be treated as an enum.
+const maxEnumValues = 8
+
+// enumValues returns the sorted distinct values of a string schema when they
+// look like an enum: at least one value repeats and there are no more than
+// maxEnumValues of them. It returns nil otherwise.
+func enumValues(sch *schema) []string {
+	if sch.kind != kindString || len(sch.samples) < 2 {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	for _, sample := range sch.samples {
+		seen[sample.(string)] = true
+	}
+	if len(seen) > maxEnumValues || len(seen) == len(sch.samples) {
+		return nil
+	}
+
+	values := make([]string, 0, len(seen))
+	for value := range seen {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	return values
+}
+
+// isEnumField reports whether a field should be generated as an enum.
+func (s *Server) isEnumField(f *field) bool {
+	return s.detectEnums && !s.isNumericString(f.schema) && !s.isDuration(f.schema) && !s.isIP(f.schema) && !s.isCIDR(f.schema) && enumValues(f.schema) != nil
+}
+
+// enumType is a string enum emitted as its own named type.
+type enumType struct {
+	name   string
+	values []string
+}
+
+// enumTypes names the enums used by the fields of every object type, in
+// order. Fields with the same name and values share one enum; otherwise
+// numeric suffixes keep enum names apart from each other, from the object
+// types, from the server's reserved names and from the format's built-in
+// types.
+func (s *Server) enumTypes(types *objectTypes) ([]*enumType, map[*field]string) {
+	taken := s.takenNames()
+	for _, obj := range types.objects {
+		taken[obj.name] = true
+	}
+
+	var enums []*enumType
+	names := make(map[*field]string)
+	byBase := make(map[string][]*enumType)
+	for _, obj := range types.objects {
+	fields:
+		for _, f := range obj.schema.fields {
+			if !s.isEnumField(f) {
+				continue
+			}
+			base, values := enumTypeName(f.name), enumValues(f.schema)
+			for _, enum := range byBase[base] {
+				if slices.Equal(enum.values, values) {
+					names[f] = enum.name
+					continue fields
+				}
+			}
+
+			name := base
+			for n := 2; taken[name]; n++ {
+				name = fmt.Sprintf("%s%d", base, n)
+			}
+			taken[name] = true
+			enum := &enumType{name: name, values: values}
+			byBase[base] = append(byBase[base], enum)
+			enums = append(enums, enum)
+			names[f] = name
+		}
+	}
+	return enums, names
+}
+
+// enumMemberName builds the identifier for one enum value, falling back to
+// its position when the value has no usable characters.
+func enumMemberName(value string, index int) string {
+	if name := toPascalCase(value); name != "" {
+		if name[0] >= '0' && name[0] <= '9' {
+			return "V" + name
+		}
+		return name
+	}
+	return fmt.Sprintf("Value%d", index)
+}
+
+// enumTypeName names the enum type generated for a field, like the types of
+// nested objects.
+func enumTypeName(fieldName string) string {
+	if toPascalCase(fieldName) == "" {
+		return "Enum"
+	}
+	return typeNameFor(fieldName)
+}
+
+func formatGoEnum(typeName string, values []string) string {
+	result := fmt.Sprintf("type %s string\n\nconst (\n", typeName)
+	used := make(map[string]bool, len(values))
+	for i, value := range values {
+		// Values such as "in-progress" and "in_progress" share a name
+		result += fmt.Sprintf("    %s%s %s = %q\n", typeName, uniqueName(enumMemberName(value, i), used), typeName, value)
+	}
+	return result + ")\n\n"
+}
+
+// formatRustEnum declares an enum with the same derives as the structs using
+// it. Deriving Default on an enum needs a default variant, so the first value
+// is marked as one.
+func formatRustEnum(derive, visibility, typeName string, values []string) string {
+	result := fmt.Sprintf("%s\n%senum %s {\n", derive, visibility, typeName)
+	used := make(map[string]bool, len(values))
+	for i, value := range values {
+		if i == 0 && strings.Contains(derive, " Default") {
+			result += "    #[default]\n"
+		}
+		result += fmt.Sprintf("    #[serde(rename = %q)]\n    %s,\n", value, uniqueName(enumMemberName(value, i), used))
+	}
+	return result + "}\n\n"
+}
@@ -0,0 +1,234 @@
+package server
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEnumValues(t *testing.T) {
+	tests := []struct {
+		name    string
+		samples []interface{}
+		expect  []string
+	}{
+		{
+			name:    "Repeated values",
+			samples: []interface{}{"active", "inactive", "active"},
+			expect:  []string{"active", "inactive"},
+		},
+		{
+			name:    "All distinct",
+			samples: []interface{}{"alice", "bob", "carol"},
+		},
+		{
+			name:    "Single sample",
+			samples: []interface{}{"active"},
+		},
+		{
+			name:    "Too many values",
+			samples: []interface{}{"a", "b", "c", "d", "e", "f", "g", "h", "i", "a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sch *schema
+			for _, sample := range tt.samples {
+				sch = mergeSchemas(sch, inferSchema(sample))
+			}
+			got := enumValues(sch)
+			if strings.Join(got, ",") != strings.Join(tt.expect, ",") {
+				t.Errorf("enumValues() = %v, want %v", got, tt.expect)
+			}
+		})
+	}
+}
+
+func TestFormatData_DetectEnums(t *testing.T) {
+	records := []interface{}{
+		map[string]interface{}{"status": "active", "name": "a"},
+		map[string]interface{}{"status": "inactive", "name": "b"},
+		map[string]interface{}{"status": "active", "name": "c"},
+	}
+
+	tests := []struct {
+		name           string
+		formatType     string
+		detectEnums    bool
+		opts           []Option
+		expectContains []string
+		expectMissing  []string
+	}{
+		{
+			name:        "Go format",
+			formatType:  "go",
+			detectEnums: true,
+			expectContains: []string{
+				"type Status string",
+				`StatusActive Status = "active"`,
+				`StatusInactive Status = "inactive"`,
+				"status Status `json:\"status\"`",
+				"name string `json:\"name\"`",
+			},
+		},
+		{
+			name:        "Rust format",
+			formatType:  "rust",
+			detectEnums: true,
+			expectContains: []string{
+				"enum Status {",
+				"#[serde(rename = \"active\")]\n    Active,",
+				"status: Status,",
+				"name: String,",
+			},
+		},
+		{
+			name:        "Rust format with derives",
+			formatType:  "rust",
+			detectEnums: true,
+			opts:        []Option{WithRustDerives([]string{"Clone", "PartialEq", "Default"})},
+			expectContains: []string{
+				"#[derive(Debug, Serialize, Deserialize, Clone, PartialEq, Default)]\nenum Status {\n    #[default]\n    #[serde(rename = \"active\")]\n    Active,\n    #[serde(rename = \"inactive\")]\n    Inactive,\n}",
+				"#[derive(Debug, Serialize, Deserialize, Clone, PartialEq, Default)]\nstruct GeneratedStruct {",
+			},
+		},
+		{
+			name:           "Disabled",
+			formatType:     "go",
+			expectContains: []string{"status string `json:\"status\"`"},
+			expectMissing:  []string{"type Status string"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := New(8080, tt.formatType, false, false, append(tt.opts, WithDetectEnums(tt.detectEnums))...)
+			result, err := srv.formatSchema(inferRecords(records))
+			if err != nil {
+				t.Fatalf("formatSchema() error = %v", err)
+			}
+
+			for _, expect := range tt.expectContains {
+				if !strings.Contains(result, expect) {
+					t.Errorf("formatSchema() result does not contain expected string: %s\nGot: %s", expect, result)
+				}
+			}
+			for _, missing := range tt.expectMissing {
+				if strings.Contains(result, missing) {
+					t.Errorf("formatSchema() result contains unexpected string: %s\nGot: %s", missing, result)
+				}
+			}
+		})
+	}
+}
+
+func TestFormatData_EnumsAcrossObjects(t *testing.T) {
+	records := []interface{}{
+		map[string]interface{}{"status": "active", "device": map[string]interface{}{"status": "on"}, "owner": map[string]interface{}{"status": "active"}},
+		map[string]interface{}{"status": "active", "device": map[string]interface{}{"status": "off"}, "owner": map[string]interface{}{"status": "inactive"}},
+		map[string]interface{}{"status": "inactive", "device": map[string]interface{}{"status": "on"}, "owner": map[string]interface{}{"status": "active"}},
+	}
+
+	tests := []struct {
+		formatType     string
+		expectContains []string
+	}{
+		{
+			formatType: "go",
+			expectContains: []string{
+				"type Status string\n\nconst (\n    StatusActive Status = \"active\"\n    StatusInactive Status = \"inactive\"\n)",
+				"type Status2 string\n\nconst (\n    Status2Off Status2 = \"off\"\n    Status2On Status2 = \"on\"\n)",
+				"type Device struct {\n    status Status2 `json:\"status\"`\n}",
+				"type Owner struct {\n    status Status `json:\"status\"`\n}",
+			},
+		},
+		{
+			formatType: "rust",
+			expectContains: []string{
+				"enum Status {\n",
+				"enum Status2 {\n",
+				"struct Device {\n    status: Status2,\n}",
+				"struct Owner {\n    status: Status,\n}",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.formatType, func(t *testing.T) {
+			srv := New(8080, tt.formatType, false, false, WithDetectEnums(true))
+			result, err := srv.formatSchema(inferRecords(records))
+			if err != nil {
+				t.Fatalf("formatSchema() error = %v", err)
+			}
+
+			for _, expect := range tt.expectContains {
+				if !strings.Contains(result, expect) {
+					t.Errorf("formatSchema() result does not contain expected string: %s\nGot: %s", expect, result)
+				}
+			}
+			// Each enum is defined once
+			if n := strings.Count(result, "Status string") + strings.Count(result, "enum Status {"); n != 1 {
+				t.Errorf("Status defined %d times\nGot: %s", n, result)
+			}
+		})
+	}
+}
+
+func TestEnumTypeName(t *testing.T) {
+	tests := []struct {
+		fieldName string
+		expect    string
+	}{
+		{"status", "Status"},
+		{"2fa_status", "T2faStatus"},
+		{"--", "Enum"},
+	}
+
+	for _, tt := range tests {
+		if got := enumTypeName(tt.fieldName); got != tt.expect {
+			t.Errorf("enumTypeName(%q) = %q, want %q", tt.fieldName, got, tt.expect)
+		}
+	}
+}
+
+func TestFormatData_EnumMemberCollisions(t *testing.T) {
+	records := []interface{}{
+		map[string]interface{}{"s": "in-progress"},
+		map[string]interface{}{"s": "in_progress"},
+		map[string]interface{}{"s": "in-progress"},
+	}
+
+	tests := []struct {
+		formatType     string
+		expectContains []string
+	}{
+		{
+			formatType: "go",
+			expectContains: []string{
+				"    SInProgress S = \"in-progress\"\n    SInProgress2 S = \"in_progress\"\n",
+			},
+		},
+		{
+			formatType: "rust",
+			expectContains: []string{
+				"    #[serde(rename = \"in-progress\")]\n    InProgress,\n    #[serde(rename = \"in_progress\")]\n    InProgress2,\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.formatType, func(t *testing.T) {
+			srv := New(8080, tt.formatType, false, false, WithDetectEnums(true))
+			result, err := srv.formatSchema(inferRecords(records))
+			if err != nil {
+				t.Fatalf("formatSchema() error = %v", err)
+			}
+
+			for _, expect := range tt.expectContains {
+				if !strings.Contains(result, expect) {
+					t.Errorf("formatSchema() result does not contain expected string: %s\nGot: %s", expect, result)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Error codes of the JSON error responses, stable for clients to match on.
+const (
+	codeBusy                = "busy"
+	codeRateLimited         = "rate_limited"
+	codeMethodNotAllowed    = "method_not_allowed"
+	codeInvalidStructName   = "invalid_struct_name"
+	codeMissingLang         = "missing_lang"
+	codeUnsupportedEncoding = "unsupported_encoding"
+	codeDecodeError         = "decode_error"
+	codeBodyTooLarge        = "body_too_large"
+	codeReadError           = "read_error"
+	codeEmptyBody           = "empty_body"
+	codeNotAcceptable       = "not_acceptable"
+	codeParseError          = "parse_error"
+	codeDuplicateKeys       = "duplicate_keys"
+	codeTooManyFields       = "too_many_fields"
+	codeTooDeep             = "too_deep"
+	codeFormatError         = "format_error"
+	codeOutputError         = "output_error"
+)
+
+var (
+	errBusy             = &requestError{http.StatusServiceUnavailable, codeBusy, "Server busy", ""}
+	errRateLimited      = &requestError{http.StatusTooManyRequests, codeRateLimited, "Too many requests", ""}
+	errMethodNotAllowed = &requestError{http.StatusMethodNotAllowed, codeMethodNotAllowed, "Method not allowed", ""}
+)
+
+// requestError describes why a request could not be processed and the HTTP
+// status to reply with. The message says what failed and the optional detail
+// why, such as the position of a JSON syntax error.
+type requestError struct {
+	status  int
+	code    string
+	message string
+	detail  string
+}
+
+func (e *requestError) Error() string {
+	if e.detail == "" {
+		return e.message
+	}
+	return e.message + ": " + e.detail
+}
+
+// errorResponse is the JSON body of an error reply.
+type errorResponse struct {
+	Error  string `json:"error"`
+	Detail string `json:"detail,omitempty"`
+	Code   string `json:"code"`
+}
+
+// writeJSONError replies with the status of err and a JSON body such as
+// {"error":"Error parsing JSON","detail":"...","code":"parse_error"}.
+func writeJSONError(w http.ResponseWriter, err *requestError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(err.status)
+	json.NewEncoder(w).Encode(errorResponse{Error: err.message, Detail: err.detail, Code: err.code})
+}
+
+// invalidStructName rejects a struct name header that is not an identifier.
+func invalidStructName(name string) *requestError {
+	return &requestError{http.StatusBadRequest, codeInvalidStructName, fmt.Sprintf("Invalid %s", structNameHeader), fmt.Sprintf("%q is not an identifier", name)}
+}
+
+// notAcceptable rejects a request whose Accept header asks for generated
+// code when it has no JSON body to generate the code from.
+func notAcceptable(mediaType string) *requestError {
+	return &requestError{http.StatusNotAcceptable, codeNotAcceptable, "No JSON body to generate code from", fmt.Sprintf("Accept asks for %s", mediaType)}
+}
+
+// readRequestBody decodes and reads the body of r within the maximum body
+// size.
+func (s *Server) readRequestBody(r *http.Request) ([]byte, *requestError) {
+	bodyReader, err := decodeBody(r)
+	if unsupported, ok := err.(errUnsupportedEncoding); ok {
+		return nil, &requestError{http.StatusUnsupportedMediaType, codeUnsupportedEncoding, unsupported.Error(), ""}
+	} else if err != nil {
+		return nil, &requestError{http.StatusBadRequest, codeDecodeError, "Error decoding request body", err.Error()}
+	}
+
+	body, err := s.readBody(bodyReader)
+	if err == errBodyTooLarge {
+		return nil, &requestError{http.StatusRequestEntityTooLarge, codeBodyTooLarge, s.bodyTooLargeMessage(), ""}
+	} else if err != nil {
+		return nil, &requestError{http.StatusBadRequest, codeReadError, "Error reading request body", ""}
+	}
+	return body, nil
+}
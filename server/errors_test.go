@@ -0,0 +1,149 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// responseText returns the message of a JSON error response, joined to its
+// detail as in the logs, or the body itself for other responses.
+func responseText(body string) string {
+	var response errorResponse
+	if err := json.Unmarshal([]byte(body), &response); err != nil || response.Code == "" {
+		return body
+	}
+	if response.Detail == "" {
+		return response.Error
+	}
+	return response.Error + ": " + response.Detail
+}
+
+func TestWriteJSONError(t *testing.T) {
+	tests := []struct {
+		name         string
+		opts         []Option
+		method       string
+		target       string
+		header       map[string]string
+		body         string
+		expectedCode int
+		expected     map[string]string
+	}{
+		{
+			name:         "Parse error",
+			method:       "POST",
+			target:       "/api/data",
+			header:       map[string]string{"Content-Type": "application/json"},
+			body:         `{"id":}`,
+			expectedCode: http.StatusBadRequest,
+			expected: map[string]string{
+				"error":  "Error parsing JSON",
+				"detail": "invalid character '}' looking for beginning of value at offset 7 near \"{\\\"id\\\":}\"",
+				"code":   "parse_error",
+			},
+		},
+		{
+			name:         "Invalid struct name",
+			method:       "POST",
+			target:       "/api/data",
+			header:       map[string]string{"Content-Type": "application/json", "X-Struct-Name": "Order Item"},
+			body:         `{"id":1}`,
+			expectedCode: http.StatusBadRequest,
+			expected: map[string]string{
+				"error":  "Invalid X-Struct-Name",
+				"detail": `"Order Item" is not an identifier`,
+				"code":   "invalid_struct_name",
+			},
+		},
+		{
+			name:         "Body too large",
+			opts:         []Option{WithMaxBodySize(4)},
+			method:       "POST",
+			target:       "/api/data",
+			header:       map[string]string{"Content-Type": "application/json"},
+			body:         `{"id":1}`,
+			expectedCode: http.StatusRequestEntityTooLarge,
+			expected:     map[string]string{"error": "Request body exceeds 4 bytes", "code": "body_too_large"},
+		},
+		{
+			name:         "Unsupported encoding",
+			method:       "POST",
+			target:       "/api/data",
+			header:       map[string]string{"Content-Type": "application/json", "Content-Encoding": "compress"},
+			body:         `{"id":1}`,
+			expectedCode: http.StatusUnsupportedMediaType,
+			expected:     map[string]string{"code": "unsupported_encoding"},
+		},
+		{
+			name:         "Method not allowed",
+			method:       "GET",
+			target:       "/format",
+			expectedCode: http.StatusMethodNotAllowed,
+			expected:     map[string]string{"error": "Method not allowed", "code": "method_not_allowed"},
+		},
+		{
+			name:         "Empty body on /format",
+			method:       "POST",
+			target:       "/format?lang=go",
+			body:         " ",
+			expectedCode: http.StatusBadRequest,
+			expected:     map[string]string{"error": "Empty request body", "code": "empty_body"},
+		},
+		{
+			name:         "Rate limited",
+			opts:         []Option{WithRateLimit(0.001, false)},
+			method:       "POST",
+			target:       "/api/data",
+			header:       map[string]string{"Content-Type": "application/json"},
+			body:         `{"id":1}`,
+			expectedCode: http.StatusTooManyRequests,
+			expected:     map[string]string{"error": "Too many requests", "code": "rate_limited"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := New(8080, "go", false, false, append([]Option{WithQuiet(true)}, tt.opts...)...)
+			handler := srv.httpServer().Handler
+
+			var rr *httptest.ResponseRecorder
+			// The rate limiter lets the first request through
+			for i := 0; rr == nil || (tt.expectedCode == http.StatusTooManyRequests && i < 2); i++ {
+				req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+				for key, value := range tt.header {
+					req.Header.Set(key, value)
+				}
+				rr = httptest.NewRecorder()
+				handler.ServeHTTP(rr, req)
+			}
+
+			if rr.Code != tt.expectedCode {
+				t.Fatalf("handler returned wrong status code: got %v want %v\n%s", rr.Code, tt.expectedCode, rr.Body.String())
+			}
+			if contentType := rr.Header().Get("Content-Type"); contentType != "application/json" {
+				t.Errorf("handler returned wrong content type: got %v want application/json", contentType)
+			}
+
+			var got map[string]string
+			if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+				t.Fatalf("error body is not a JSON object of strings: %v\n%s", err, rr.Body.String())
+			}
+			if got["error"] == "" {
+				t.Errorf("error body has no error message: %s", rr.Body.String())
+			}
+			for key, want := range tt.expected {
+				if got[key] != want {
+					t.Errorf("%s = %q, want %q", key, got[key], want)
+				}
+			}
+			for key := range got {
+				if key != "error" && key != "detail" && key != "code" {
+					t.Errorf("error body has unexpected key %q", key)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package server
+
+import (
+	"encoding/json"
+)
+
+// maxExampleLength is the longest example value shown in a field comment, in
+// characters, before it is truncated.
+const maxExampleLength = 40
+
+// exampleComment returns a trailing comment showing the first value observed
+// for a scalar field, or an empty string when comments are disabled or there
+// is no value to show.
+func (s *Server) exampleComment(sch *schema) string {
+	if !s.comments || len(sch.samples) == 0 {
+		return ""
+	}
+	switch sch.kind {
+	case kindBool, kindNumber, kindString:
+	default:
+		return ""
+	}
+
+	// JSON encoding quotes strings and escapes newlines, keeping the comment
+	// on one line
+	example, err := json.Marshal(sch.samples[0])
+	if err != nil {
+		return ""
+	}
+	if runes := []rune(string(example)); len(runes) > maxExampleLength {
+		example = []byte(string(runes[:maxExampleLength]) + "...")
+	}
+	return " // e.g. " + string(example)
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatData_Comments(t *testing.T) {
+	data := map[string]interface{}{
+		"name":   "test",
+		"value":  123.0,
+		"active": true,
+		"bio":    strings.Repeat("a", 50),
+		"note":   "line one\nline two",
+		"tags":   []interface{}{"a"},
+	}
+
+	tests := []struct {
+		formatType     string
+		expectContains []string
+	}{
+		{
+			formatType: "go",
+			expectContains: []string{
+				"    active bool `json:\"active\"` // e.g. true\n",
+				"    bio string `json:\"bio\"` // e.g. \"" + strings.Repeat("a", 39) + "...\n",
+				"    name string `json:\"name\"` // e.g. \"test\"\n",
+				"    note string `json:\"note\"` // e.g. \"line one\\nline two\"\n",
+				"    tags []string `json:\"tags\"`\n",
+				"    value float64 `json:\"value\"` // e.g. 123\n",
+			},
+		},
+		{
+			formatType: "rust",
+			expectContains: []string{
+				"    name: String, // e.g. \"test\"\n",
+				"    tags: Vec<String>,\n",
+				"    value: f64, // e.g. 123\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.formatType, func(t *testing.T) {
+			srv := New(8080, tt.formatType, false, false, WithComments(true))
+			result, err := srv.formatData(data)
+			if err != nil {
+				t.Fatalf("formatData() error = %v", err)
+			}
+
+			for _, expect := range tt.expectContains {
+				if !strings.Contains(result, expect) {
+					t.Errorf("formatData() result does not contain expected string: %s\nGot: %s", expect, result)
+				}
+			}
+		})
+	}
+
+	// Without the option fields carry no comments
+	result, err := New(8080, "go", false, false).formatData(data)
+	if err != nil {
+		t.Fatalf("formatData() error = %v", err)
+	}
+	if strings.Contains(result, "// e.g.") {
+		t.Errorf("formatData() without comments added one\nGot: %s", result)
+	}
+}
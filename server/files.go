@@ -0,0 +1,63 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+// FormatFiles generates one type for the JSON documents of several files,
+// merging their schemas so that fields missing from some files are optional.
+// Each file holds a JSON document or an NDJSON stream. With verbose logging,
+// the files each field was seen in are logged.
+func (s *Server) FormatFiles(paths []string) (string, error) {
+	var records []interface{}
+	contents := make([][]byte, 0, len(paths))
+	contributors := make(map[string][]string)
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		fileRecords, err := decodeNDJSON(bytes.NewReader(data))
+		if err != nil {
+			return "", fmt.Errorf("%s: invalid JSON: %s", path, describeJSONError(data, err))
+		}
+		if len(fileRecords) == 0 {
+			return "", fmt.Errorf("%s: invalid JSON: no value found", path)
+		}
+		records = append(records, fileRecords...)
+		contents = append(contents, data)
+
+		for _, fieldPath := range fieldPaths(inferRecords(fileRecords), "") {
+			contributors[fieldPath] = append(contributors[fieldPath], path)
+		}
+	}
+	if len(records) == 0 {
+		return "", fmt.Errorf("no files given")
+	}
+
+	if s.verbose {
+		for _, fieldPath := range fieldPaths(inferRecords(records), "") {
+			log.Printf("Field %s from %s", fieldPath, strings.Join(contributors[fieldPath], ", "))
+		}
+	}
+	return s.generateRecords(log.Default(), "application/x-ndjson", bytes.Join(contents, []byte("\n")), records, strings.Join(paths, ", "))
+}
+
+// fieldPaths lists the paths of the fields of a schema and of the objects
+// nested in it, with array elements marked by [].
+func fieldPaths(sch *schema, prefix string) []string {
+	for sch.kind == kindArray && sch.elem != nil {
+		sch, prefix = sch.elem, strings.TrimSuffix(prefix, ".")+"[]."
+	}
+	var paths []string
+	for _, f := range sch.fields {
+		path := prefix + f.name
+		paths = append(paths, path)
+		paths = append(paths, fieldPaths(f.schema, path+".")...)
+	}
+	return paths
+}
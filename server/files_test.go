@@ -0,0 +1,89 @@
+package server
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestServer_FormatFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.json":   `{"id":1,"owner":{"name":"alice"}}`,
+		"b.ndjson": "{\"id\":2,\"email\":\"x@example.com\"}\n{\"id\":3}\n",
+		"bad.json": `{"id":`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a, b := filepath.Join(dir, "a.json"), filepath.Join(dir, "b.ndjson")
+
+	var logBuf bytes.Buffer
+	log.SetOutput(&logBuf)
+	defer log.SetOutput(os.Stderr)
+
+	srv := New(8080, "go", false, false, WithVerbose(true))
+	result, err := srv.FormatFiles([]string{a, b})
+	if err != nil {
+		t.Fatalf("FormatFiles() error = %v", err)
+	}
+	for _, expect := range []string{
+		"    email *string `json:\"email,omitempty\"`\n",
+		"    id float64 `json:\"id\"`\n",
+		"    owner *Owner `json:\"owner,omitempty\"`\n",
+		"type Owner struct {\n    name string `json:\"name\"`\n}",
+	} {
+		if !strings.Contains(result, expect) {
+			t.Errorf("FormatFiles() result does not contain expected string: %s\nGot: %s", expect, result)
+		}
+	}
+	for _, expect := range []string{
+		"Field email from " + b + "\n",
+		"Field id from " + a + ", " + b + "\n",
+		"Field owner.name from " + a + "\n",
+	} {
+		if !strings.Contains(logBuf.String(), expect) {
+			t.Errorf("Logs do not contain expected string: %s\nGot: %s", expect, logBuf.String())
+		}
+	}
+
+	// Contributions are only logged in verbose mode
+	logBuf.Reset()
+	if _, err := New(8080, "go", false, false).FormatFiles([]string{a, b}); err != nil {
+		t.Fatalf("FormatFiles() error = %v", err)
+	}
+	if strings.Contains(logBuf.String(), "Field ") {
+		t.Errorf("Logs contain field contributions without verbose mode\nGot: %s", logBuf.String())
+	}
+
+	_, err = srv.FormatFiles([]string{a, filepath.Join(dir, "bad.json")})
+	if err == nil || !strings.Contains(err.Error(), "bad.json: invalid JSON: unexpected EOF") {
+		t.Errorf("FormatFiles() error = %v, want the invalid file named", err)
+	}
+	if _, err := srv.FormatFiles([]string{filepath.Join(dir, "missing.json")}); err == nil {
+		t.Errorf("FormatFiles() accepted a missing file")
+	}
+}
+
+func TestFieldPaths(t *testing.T) {
+	sch := inferSchema(map[string]interface{}{
+		"id":    1.0,
+		"owner": map[string]interface{}{"name": "alice"},
+		"items": []interface{}{map[string]interface{}{"sku": "a"}},
+	})
+	want := []string{"id", "items", "items[].sku", "owner", "owner.name"}
+	if got := fieldPaths(sch, ""); !reflect.DeepEqual(got, want) {
+		t.Errorf("fieldPaths() = %v, want %v", got, want)
+	}
+
+	top := inferSchema([]interface{}{map[string]interface{}{"id": 1.0}})
+	if got := fieldPaths(top, ""); !reflect.DeepEqual(got, []string{"[].id"}) {
+		t.Errorf("fieldPaths() = %v, want [[].id]", got)
+	}
+}
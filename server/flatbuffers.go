@@ -0,0 +1,95 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+)
+
+// flatbuffersKeywords lists the words of the FlatBuffers schema language that
+// cannot name a field.
+var flatbuffersKeywords = map[string]bool{
+	"attribute": true, "enum": true, "file_extension": true,
+	"file_identifier": true, "include": true, "namespace": true,
+	"native_include": true, "root_type": true, "rpc_service": true,
+	"struct": true, "table": true, "union": true,
+}
+
+// flexbuffer is the type of values a FlatBuffers schema cannot describe, such
+// as mixed values or nested arrays, kept as schemaless FlexBuffers.
+const flexbuffer = "[ubyte] (flexbuffer)"
+
+func (s *Server) formatAsFlatbuffers(sch *schema) (string, error) {
+	root := sch
+	if sch.kind != kindObject {
+		root = &schema{kind: kindObject, fields: []*field{{name: "data", schema: sch}}}
+	}
+
+	// Emit children first so each table is declared before it is used
+	types := s.nestedObjects(s.structName, root)
+	tables := make([]string, 0, len(types.objects)+1)
+	for _, obj := range childrenFirst(types) {
+		tables = append(tables, generateFlatbuffersTable(obj, types))
+	}
+	tables = append(tables, fmt.Sprintf("root_type %s;", s.structName))
+	return strings.Join(tables, "\n\n"), nil
+}
+
+// generateFlatbuffersTable writes a table. Table fields may always be absent,
+// so only scalars, which would otherwise read as zero, are marked optional
+// with a null default.
+func generateFlatbuffersTable(obj *objectType, types *objectTypes) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "table %s {\n", obj.name)
+	names := keyNames(obj.schema.fields, flatbuffersFieldName)
+	for i, f := range obj.schema.fields {
+		fieldType := flatbuffersType(f.schema, types)
+		if isFlatbuffersScalar(fieldType) && (f.optional || f.schema.nullable) {
+			fieldType += " = null"
+		}
+		fmt.Fprintf(&b, "  %s:%s;\n", names[i], fieldType)
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// flatbuffersFieldName sanitizes a JSON key into a field name, appending an
+// underscore to keywords.
+func flatbuffersFieldName(key string) string {
+	name := sanitizeIdentifier(key)
+	if flatbuffersKeywords[name] {
+		name += "_"
+	}
+	return name
+}
+
+func flatbuffersType(sch *schema, types *objectTypes) string {
+	switch sch.kind {
+	case kindBool:
+		return "bool"
+	case kindNumber:
+		if sch.integral() {
+			return "long"
+		}
+		return "double"
+	case kindString:
+		return "string"
+	case kindArray:
+		if sch.elem == nil {
+			return flexbuffer
+		}
+		elemType := flatbuffersType(sch.elem, types)
+		// Vectors cannot hold vectors
+		if strings.HasPrefix(elemType, "[") {
+			return flexbuffer
+		}
+		return "[" + elemType + "]"
+	case kindObject:
+		return types.name(sch)
+	default:
+		return flexbuffer
+	}
+}
+
+func isFlatbuffersScalar(fieldType string) bool {
+	return fieldType == "bool" || fieldType == "long" || fieldType == "double"
+}
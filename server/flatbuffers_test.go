@@ -0,0 +1,68 @@
+package server
+
+import "testing"
+
+func TestFormatAsFlatbuffers(t *testing.T) {
+	testFormatCases(t, "flatbuffers", []formatCase{
+		{
+			name: "Flat object",
+			data: map[string]interface{}{"name": "test", "value": 1.5, "count": 3.0, "active": true},
+			expectContains: []string{
+				"table GeneratedStruct {\n" +
+					"  active:bool;\n" +
+					"  count:long;\n" +
+					"  name:string;\n" +
+					"  value:double;\n" +
+					"}\n\nroot_type GeneratedStruct;",
+			},
+		},
+		{
+			name: "Nested tables and vectors",
+			data: map[string]interface{}{
+				"owner": map[string]interface{}{"id": 1.0},
+				"items": []interface{}{map[string]interface{}{"sku": "a"}},
+				"tags":  []interface{}{"a"},
+				"grid":  []interface{}{[]interface{}{1.0}},
+				"empty": []interface{}{},
+				"mixed": []interface{}{1.0, "a"},
+			},
+			expectContains: []string{
+				"table Owner {\n  id:long;\n}\n\ntable Items {\n  sku:string;\n}\n\ntable GeneratedStruct {\n",
+				"  empty:[ubyte] (flexbuffer);\n",
+				"  grid:[ubyte] (flexbuffer);\n",
+				"  items:[Items];\n",
+				"  mixed:[ubyte] (flexbuffer);\n",
+				"  owner:Owner;\n",
+				"  tags:[string];\n",
+			},
+		},
+		{
+			name: "Optional scalars and keywords",
+			records: []interface{}{
+				map[string]interface{}{"id": 1.0, "score": nil, "note": "x", "table": true},
+				map[string]interface{}{"id": 2.0, "score": 1.5},
+			},
+			expectContains: []string{
+				"  id:long;\n",
+				"  note:string;\n",
+				"  score:double = null;\n",
+				"  table_:bool = null;\n",
+			},
+		},
+		{
+			name: "Colliding keys",
+			data: map[string]interface{}{"a b": "x", "a_b": true, "table": 1.0, "table_": "y"},
+			expectContains: []string{
+				"  a_b2:string;\n",
+				"  a_b:bool;\n",
+				"  table_2:long;\n",
+				"  table_:string;\n",
+			},
+		},
+		{
+			name:           "Non-object root",
+			data:           []interface{}{"a"},
+			expectContains: []string{"table GeneratedStruct {\n  data:[string];\n}"},
+		},
+	})
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+)
+
+// FormatInfo describes an output format.
+type FormatInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// formats lists every output format, in the order they were added.
+var formats = []FormatInfo{
+	{"go", "Go structs with json tags"},
+	{"rust", "Rust structs with serde attributes"},
+	{"typeddict", "Python TypedDict classes"},
+	{"scala", "Scala case classes"},
+	{"haskell", "Haskell records with aeson Generic instances"},
+	{"zod", "Zod schemas for TypeScript runtime validation"},
+	{"openapi", "OpenAPI 3.0 component schemas (YAML)"},
+	{"avro", "Avro record schemas"},
+	{"dart", "Dart classes with json_serializable annotations"},
+	{"c", "C structs"},
+	{"elm", "Elm type aliases with Json.Decode decoders"},
+	{"php", "PHP 8 classes with typed properties"},
+	{"ocaml", "OCaml record types with ppx_deriving_yojson annotations"},
+	{"fsharp", "F# records with System.Text.Json attributes"},
+	{"ruby", "Ruby Structs, or Sorbet T::Struct classes"},
+	{"crystal", "Crystal structs with JSON::Serializable"},
+	{"objc", "Objective-C interfaces with @property declarations"},
+	{"mermaid", "Mermaid class diagrams of the nested objects"},
+	{"thrift", "Apache Thrift structs with field IDs in key order"},
+	{"toml", "TOML documents converted from the JSON body"},
+	{"kotlin", "Kotlin data classes for kotlinx.serialization, Jackson or Moshi"},
+	{"schema-json", "The inferred schema as language neutral JSON"},
+	{"plantuml", "PlantUML class diagrams of the nested objects"},
+	{"clojure", "clojure.spec.alpha specs with s/keys for each object"},
+	{"flatbuffers", "FlatBuffers schemas with a table per object and a root_type"},
+	{"rbi", "Sorbet RBI signatures for the Ruby Structs"},
+	{"xsd", "XML Schemas with a complexType per object"},
+	{"graphql", "GraphQL object types, or input types with -graphql-kind input"},
+}
+
+// formatExtensions maps each format to the extension of the files -watch
+// writes its code to.
+var formatExtensions = map[string]string{
+	"go": ".go", "rust": ".rs", "typeddict": ".py", "scala": ".scala",
+	"haskell": ".hs", "zod": ".ts", "openapi": ".yaml", "avro": ".avsc",
+	"dart": ".dart", "c": ".h", "elm": ".elm", "php": ".php", "ocaml": ".ml",
+	"fsharp": ".fs", "ruby": ".rb", "crystal": ".cr", "objc": ".h",
+	"mermaid": ".mmd", "thrift": ".thrift", "toml": ".toml", "kotlin": ".kt",
+	"schema-json": ".json", "plantuml": ".puml", "clojure": ".clj",
+	"flatbuffers": ".fbs", "rbi": ".rbi", "xsd": ".xsd",
+	"graphql": ".graphql",
+}
+
+// Formats returns every output format, in the order they were added.
+func Formats() []FormatInfo {
+	return append([]FormatInfo(nil), formats...)
+}
+
+// FormatNames returns the name of every output format, in the order they
+// were added.
+func FormatNames() []string {
+	names := make([]string, len(formats))
+	for i, f := range formats {
+		names[i] = f.Name
+	}
+	return names
+}
+
+// IsFormat reports whether name is an output format.
+func IsFormat(name string) bool {
+	for _, f := range formats {
+		if f.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) handleFormats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(formats)
+}
+
+// numberTypes lists, for the formats that support -number-type, the types
+// every number may be forced to.
+var numberTypes = map[string][]string{
+	"go":   {"int", "int32", "int64", "uint64", "float32", "float64", "json.Number"},
+	"rust": {"i32", "i64", "u64", "f32", "f64", "serde_json::Number"},
+}
+
+// NumberTypes returns the types the numbers of a format may be forced to, or
+// nil when the format always infers them.
+func NumberTypes(format string) []string {
+	return numberTypes[format]
+}
+
+// numberTypeFor returns the forced number type when it is valid for format,
+// so that a format picked through content negotiation keeps inferring.
+func (s *Server) numberTypeFor(format string) string {
+	if slices.Contains(numberTypes[format], s.numberType) {
+		return s.numberType
+	}
+	return ""
+}
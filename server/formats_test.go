@@ -0,0 +1,101 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFormats_Registered(t *testing.T) {
+	// Every listed format must be handled by formatSchema, commented by the
+	// generated header unless it is JSON, and have a -watch file extension
+	sch := inferSchema(map[string]interface{}{"id": 1.0})
+	for _, name := range FormatNames() {
+		srv := New(8080, name, false, false)
+		_, err := srv.formatSchema(sch)
+		if err != nil && strings.HasPrefix(err.Error(), "unsupported format type") {
+			t.Errorf("format %s is listed but not generated", name)
+		}
+		if _, ok := commentPrefixes[name]; !ok && name != "avro" && name != "schema-json" {
+			t.Errorf("format %s has no comment prefix", name)
+		}
+		if _, ok := formatExtensions[name]; !ok {
+			t.Errorf("format %s has no file extension", name)
+		}
+	}
+
+	if !IsFormat("go") || IsFormat("cobol") {
+		t.Errorf("IsFormat() does not match the listed formats")
+	}
+}
+
+func TestServer_Formats(t *testing.T) {
+	srv := New(8080, "", false, false)
+
+	req := httptest.NewRequest("GET", "/formats", nil)
+	rr := httptest.NewRecorder()
+	srv.httpServer().Handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("Handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+	if contentType := rr.Header().Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("Handler returned wrong content type: got %v want application/json", contentType)
+	}
+
+	var got []FormatInfo
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("Handler returned invalid JSON: %v\nGot: %s", err, rr.Body.String())
+	}
+	if !reflect.DeepEqual(got, Formats()) {
+		t.Errorf("Handler returned %+v, want %+v", got, Formats())
+	}
+	if !strings.HasPrefix(rr.Body.String(), `[{"name":"go","description":"Go structs with json tags"},`) {
+		t.Errorf("Handler returned wrong body: got %s", rr.Body.String())
+	}
+}
+
+// formatCase is a body, decoded as one value or as records, and what one
+// format must generate for it.
+type formatCase struct {
+	name           string
+	opts           []Option
+	data           interface{}
+	records        []interface{}
+	expectContains []string
+	expectMissing  []string
+}
+
+// testFormatCases generates each case's body in formatType and checks the
+// result, for the table-driven formatter tests.
+func testFormatCases(t *testing.T, formatType string, tests []formatCase) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sch := inferSchema(tt.data)
+			if tt.records != nil {
+				sch = inferRecords(tt.records)
+			}
+
+			srv := New(8080, formatType, false, false, tt.opts...)
+			result, err := srv.formatSchema(sch)
+			if err != nil {
+				t.Fatalf("formatSchema() error = %v", err)
+			}
+
+			for _, expect := range tt.expectContains {
+				if !strings.Contains(result, expect) {
+					t.Errorf("formatSchema() result does not contain expected string: %s\nGot: %s", expect, result)
+				}
+			}
+			for _, missing := range tt.expectMissing {
+				if strings.Contains(result, missing) {
+					t.Errorf("formatSchema() result contains unexpected string: %s\nGot: %s", missing, result)
+				}
+			}
+		})
+	}
+}
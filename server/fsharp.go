@@ -0,0 +1,113 @@
+package server
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// fsharpPreamble starts F# output, opening the serialization namespaces.
+const fsharpPreamble = "open System.Text.Json\nopen System.Text.Json.Serialization\n\n"
+
+// fsharpBuiltinTypes lists the built-in type names and the opened
+// System.Text.Json types that generated records use, which a record of the
+// same name would shadow.
+var fsharpBuiltinTypes = map[string]bool{
+	"Boolean": true, "Double": true, "JsonElement": true,
+	"JsonPropertyName": true, "JsonPropertyNameAttribute": true, "List": true,
+	"Object": true, "Option": true, "String": true,
+}
+
+func (s *Server) formatAsFSharp(sch *schema) (string, error) {
+	root := sch
+	if sch.kind != kindObject {
+		root = &schema{kind: kindObject, fields: []*field{{name: "data", schema: sch}}}
+	}
+
+	// F# needs types defined before use, so emit children first
+	types := s.nestedObjects(s.structName, root)
+	records := make([]string, 0, len(types.objects))
+	for _, obj := range childrenFirst(types) {
+		records = append(records, s.generateFSharpRecord(obj, types))
+	}
+	return fsharpPreamble + strings.Join(records, "\n\n"), nil
+}
+
+func (s *Server) generateFSharpRecord(obj *objectType, types *objectTypes) string {
+	// Records need at least one field
+	if len(obj.schema.fields) == 0 {
+		return fmt.Sprintf("type %s() = class end", obj.name)
+	}
+
+	used := make(map[string]bool)
+	lines := make([]string, 0, len(obj.schema.fields))
+	for i, f := range obj.schema.fields {
+		name := fsharpFieldName(f.name, i)
+		base := name
+		for n := 2; used[name]; n++ {
+			name = fmt.Sprintf("%s%d", base, n)
+		}
+		used[name] = true
+
+		fieldType := s.getFSharpType(f.schema, types)
+		if f.optional {
+			fieldType = fsharpOption(fieldType)
+		}
+
+		var attr string
+		if name != f.name {
+			attr = fmt.Sprintf("[<JsonPropertyName(%s)>] ", strconv.Quote(f.name))
+		}
+		lines = append(lines, fmt.Sprintf("%s%s: %s", attr, name, fieldType))
+	}
+	return fmt.Sprintf("type %s =\n    { %s }", obj.name, strings.Join(lines, "\n      "))
+}
+
+func (s *Server) getFSharpType(sch *schema, types *objectTypes) string {
+	var fsharpType string
+	switch sch.kind {
+	case kindBool:
+		fsharpType = "bool"
+	case kindNumber:
+		fsharpType = "float"
+	case kindString:
+		fsharpType = "string"
+	case kindArray:
+		elemType := "JsonElement"
+		if sch.elem != nil {
+			elemType = s.getFSharpType(sch.elem, types)
+		}
+		fsharpType = elemType + " list"
+	case kindObject:
+		fsharpType = types.name(sch)
+	case kindNull:
+		return "JsonElement option"
+	default:
+		fsharpType = "JsonElement"
+	}
+	if sch.nullable {
+		return fsharpOption(fsharpType)
+	}
+	return fsharpType
+}
+
+// fsharpOption makes an F# type optional unless it already is.
+func fsharpOption(fsharpType string) string {
+	if strings.HasSuffix(fsharpType, " option") {
+		return fsharpType
+	}
+	return fsharpType + " option"
+}
+
+// fsharpFieldName converts a JSON key to a PascalCase record field name. F#
+// keywords are all lowercase, so PascalCase names cannot clash with them.
+func fsharpFieldName(key string, index int) string {
+	name := toPascalCase(key)
+	if name == "" {
+		return fmt.Sprintf("Field%d", index)
+	}
+	if name[0] >= '0' && name[0] <= '9' {
+		name = "N" + name
+	}
+	return name
+}
@@ -0,0 +1,49 @@
+package server
+
+import "testing"
+
+func TestFormatAsFSharp(t *testing.T) {
+	testFormatCases(t, "fsharp", []formatCase{
+		{
+			name: "Flat object",
+			data: map[string]interface{}{"name": "test", "value": 123.0, "active": true},
+			expectContains: []string{
+				"open System.Text.Json.Serialization",
+				"type GeneratedStruct =\n" +
+					"    { [<JsonPropertyName(\"active\")>] Active: bool\n" +
+					"      [<JsonPropertyName(\"name\")>] Name: string\n" +
+					"      [<JsonPropertyName(\"value\")>] Value: float }",
+			},
+		},
+		{
+			name: "Nested objects, arrays and keywords",
+			data: map[string]interface{}{
+				"user_info": map[string]interface{}{"FullName": "test"},
+				"tags":      []interface{}{"a", "b"},
+				"type":      "admin",
+				"2fa":       true,
+			},
+			expectContains: []string{
+				"type UserInfo =\n    { FullName: string }\n\ntype GeneratedStruct =",
+				`[<JsonPropertyName("2fa")>] N2fa: bool`,
+				`[<JsonPropertyName("tags")>] Tags: string list`,
+				`[<JsonPropertyName("type")>] Type: string`,
+				`[<JsonPropertyName("user_info")>] UserInfo: UserInfo`,
+			},
+		},
+		{
+			name: "Optional and null fields",
+			records: []interface{}{
+				map[string]interface{}{"id": 1.0, "email": nil, "tags": []interface{}{"a", nil}},
+				map[string]interface{}{"id": 2.0, "email": "x@example.com", "meta": map[string]interface{}{}},
+			},
+			expectContains: []string{
+				"Email: string option",
+				"Id: float",
+				"Meta: Meta option",
+				"Tags: string option list option",
+				"type Meta() = class end",
+			},
+		},
+	})
+}
@@ -0,0 +1,71 @@
+package server
+
+import "fmt"
+
+// goDurationBase names the wrapper typing detected durations in Go output.
+// encoding/json reads time.Duration as a number of nanoseconds, so strings
+// such as "5m30s" need their own UnmarshalJSON.
+const goDurationBase = "Duration"
+
+// usesGoDuration reports whether a field of the object types is a detected
+// duration, needing the wrapper.
+func (s *Server) usesGoDuration(types *objectTypes) bool {
+	for _, obj := range types.objects {
+		for _, f := range obj.schema.fields {
+			if s.isDuration(f.schema) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// goDurationType names the duration wrapper, with a numeric suffix when an
+// object type, an enum, a reserved name or a built-in type takes the name,
+// unless a wrapper is already declared elsewhere.
+func (s *Server) goDurationType(types *objectTypes, enumNames map[*field]string) string {
+	if s.goDurationName != "" {
+		return s.goDurationName
+	}
+	taken := s.takenNames()
+	for _, obj := range types.objects {
+		taken[obj.name] = true
+	}
+	for _, name := range enumNames {
+		taken[name] = true
+	}
+
+	name := goDurationBase
+	for n := 2; taken[name]; n++ {
+		name = fmt.Sprintf("%s%d", goDurationBase, n)
+	}
+	return name
+}
+
+// formatGoDuration declares the duration wrapper, reading and writing
+// durations in the time.ParseDuration syntax.
+func formatGoDuration(typeName string) string {
+	return fmt.Sprintf(`// %[1]s is a time.Duration written in JSON as a string such as "5m30s".
+type %[1]s struct {
+    time.Duration
+}
+
+func (d %[1]s) MarshalJSON() ([]byte, error) {
+    return json.Marshal(d.String())
+}
+
+func (d *%[1]s) UnmarshalJSON(data []byte) error {
+    var s string
+    if err := json.Unmarshal(data, &s); err != nil {
+        return err
+    }
+    parsed, err := time.ParseDuration(s)
+    if err != nil {
+        return err
+    }
+    d.Duration = parsed
+    return nil
+}
+
+`, typeName)
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+func TestFormatGoDuration(t *testing.T) {
+	tests := []struct {
+		name           string
+		data           interface{}
+		expectContains []string
+		expectMissing  []string
+	}{
+		{
+			name: "Nested durations",
+			data: map[string]interface{}{
+				"retry": map[string]interface{}{"backoff": "250ms"},
+			},
+			expectContains: []string{
+				"func (d Duration) MarshalJSON() ([]byte, error) {",
+				"backoff Duration `json:\"backoff\"`",
+			},
+		},
+		{
+			name: "Name taken by an object",
+			data: map[string]interface{}{
+				"duration": map[string]interface{}{"max": "1h"},
+			},
+			expectContains: []string{
+				"type Duration2 struct {\n    time.Duration\n}",
+				"duration Duration `json:\"duration\"`",
+				"type Duration struct {\n    max Duration2 `json:\"max\"`\n}",
+			},
+		},
+		{
+			name:          "No durations",
+			data:          map[string]interface{}{"name": "test"},
+			expectMissing: []string{"Duration", "import"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := New(8080, "go", false, false, WithPackage("models"), WithDetectDurations(true))
+			result, err := srv.formatData(tt.data)
+			if err != nil {
+				t.Fatalf("formatData() error = %v", err)
+			}
+			if _, err := parser.ParseFile(token.NewFileSet(), "models.go", result, 0); err != nil {
+				t.Errorf("formatData() result is not valid Go: %v\nGot: %s", err, result)
+			}
+
+			for _, expect := range tt.expectContains {
+				if !strings.Contains(result, expect) {
+					t.Errorf("formatData() result does not contain expected string: %s\nGot: %s", expect, result)
+				}
+			}
+			for _, missing := range tt.expectMissing {
+				if strings.Contains(result, missing) {
+					t.Errorf("formatData() result contains unexpected string: %s\nGot: %s", missing, result)
+				}
+			}
+		})
+	}
+}
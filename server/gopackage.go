@@ -0,0 +1,87 @@
+package server
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// goImportPaths maps the package names Go field types may be qualified with,
+// by the detectors or by -type-override, to their import paths.
+var goImportPaths = map[string]string{
+	"big":   "math/big",
+	"json":  "encoding/json",
+	"net":   "net",
+	"netip": "net/netip",
+	"sql":   "database/sql",
+	"time":  "time",
+	"url":   "net/url",
+	"uuid":  "github.com/google/uuid",
+}
+
+// goQualifier matches the package of a qualified type such as time.Duration.
+var goQualifier = regexp.MustCompile(`\b([a-z][a-z0-9]*)\.[A-Z]`)
+
+// goFile returns the package clause and import block starting Go output with
+// -package, or nothing without it. Standard library imports come first, then
+// the others after a blank line, as goimports groups them.
+func (s *Server) goFile(types *objectTypes, enumNames map[*field]string) string {
+	if s.goPackage == "" {
+		return ""
+	}
+	header := fmt.Sprintf("package %s\n\n", s.goPackage)
+
+	var std, others []string
+	for _, path := range s.goImports(types, enumNames) {
+		if strings.Contains(strings.SplitN(path, "/", 2)[0], ".") {
+			others = append(others, path)
+		} else {
+			std = append(std, path)
+		}
+	}
+	if len(std)+len(others) == 0 {
+		return header
+	}
+	header += "import (\n"
+	for _, path := range std {
+		header += fmt.Sprintf("    %q\n", path)
+	}
+	if len(std) > 0 && len(others) > 0 {
+		header += "\n"
+	}
+	for _, path := range others {
+		header += fmt.Sprintf("    %q\n", path)
+	}
+	return header + ")\n\n"
+}
+
+// goImports lists, sorted, the import paths of the packages the fields of
+// the generated structs, and the duration wrapper, use.
+func (s *Server) goImports(types *objectTypes, enumNames map[*field]string) []string {
+	// Every object is visited, so flattened structs need not be inlined, and
+	// their struct tags cannot be mistaken for types
+	named := *s
+	named.flatten = false
+	seen := make(map[string]bool)
+	var paths []string
+	if s.usesGoDuration(types) {
+		// The duration wrapper parses strings with time.ParseDuration
+		seen["encoding/json"], seen["time"] = true, true
+		paths = append(paths, "encoding/json", "time")
+	}
+	for _, obj := range types.objects {
+		for _, f := range obj.schema.fields {
+			fieldType, _ := named.goFieldType(f, types, enumNames, "")
+			for _, match := range goQualifier.FindAllStringSubmatch(fieldType, -1) {
+				path, ok := goImportPaths[match[1]]
+				if ok && !seen[path] {
+					seen[path] = true
+					paths = append(paths, path)
+				}
+			}
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
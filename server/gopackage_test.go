@@ -0,0 +1,74 @@
+package server
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatData_Package(t *testing.T) {
+	testData := map[string]interface{}{
+		"id":      "123e4567-e89b-12d3-a456-426614174000",
+		"timeout": "5m30s",
+		"owner":   map[string]interface{}{"addr": "10.0.0.1"},
+		"name":    "test",
+	}
+
+	tests := []struct {
+		name           string
+		options        []Option
+		expectContains []string
+		expectMissing  []string
+	}{
+		{
+			name:    "Detected imports",
+			options: []Option{WithPackage("models"), WithDetectUUID(true), WithDetectDurations(true), WithDetectNet(true)},
+			expectContains: []string{
+				"package models\n\nimport (\n    \"encoding/json\"\n    \"net\"\n    \"time\"\n\n    \"github.com/google/uuid\"\n)\n\n// Duration is",
+			},
+		},
+		{
+			name:           "No imports",
+			options:        []Option{WithPackage("models")},
+			expectContains: []string{"package models\n\ntype GeneratedStruct struct {"},
+			expectMissing:  []string{"import"},
+		},
+		{
+			name:           "Flattened",
+			options:        []Option{WithPackage("models"), WithFlatten(true), WithDetectNet(true)},
+			expectContains: []string{"package models\n\nimport (\n    \"net\"\n)\n\ntype GeneratedStruct struct {"},
+			expectMissing:  []string{"\"time\""},
+		},
+		{
+			name:           "Type override",
+			options:        []Option{WithPackage("models"), WithTypeOverrides(map[string]string{"name": "sql.NullString", "owner.addr": "*mylib.Addr"})},
+			expectContains: []string{"import (\n    \"database/sql\"\n)\n"},
+			expectMissing:  []string{"mylib\""},
+		},
+		{
+			name:          "Disabled",
+			options:       []Option{WithDetectDurations(true)},
+			expectMissing: []string{"package", "import"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := New(8080, "go", false, false, tt.options...)
+			result, err := srv.formatData(testData)
+			if err != nil {
+				t.Fatalf("formatData() error = %v", err)
+			}
+
+			for _, expect := range tt.expectContains {
+				if !strings.Contains(result, expect) {
+					t.Errorf("formatData() result does not contain expected string: %s\nGot: %s", expect, result)
+				}
+			}
+			for _, missing := range tt.expectMissing {
+				if strings.Contains(result, missing) {
+					t.Errorf("formatData() result contains unexpected string: %s\nGot: %s", missing, result)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package server
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// graphqlJSON is the custom scalar typing values GraphQL cannot describe,
+// such as mixed values, declared ahead of the types when used.
+const graphqlJSON = "JSON"
+
+// graphqlBuiltinScalars lists the scalars every GraphQL schema has, which
+// need no declaration.
+var graphqlBuiltinScalars = map[string]bool{"Int": true, "Float": true, "String": true, "Boolean": true, "ID": true}
+
+// graphqlScalarKinds lists the detected types -graphql-scalars can map to a
+// scalar of the user's choice: date for RFC 3339 timestamps and dates, id for
+// identifier fields and uuid for UUIDs.
+var graphqlScalarKinds = map[string]bool{"date": true, "id": true, "uuid": true}
+
+var graphqlNamePattern = regexp.MustCompile(`^[_A-Za-z][_0-9A-Za-z]*$`)
+
+// ParseGraphQLScalars parses a -graphql-scalars value such as
+// "date=DateTime,id=ID" into detected types and the scalars typing them.
+func ParseGraphQLScalars(value string) (map[string]string, error) {
+	scalars := make(map[string]string)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		kind, scalar, ok := strings.Cut(entry, "=")
+		kind, scalar = strings.TrimSpace(kind), strings.TrimSpace(scalar)
+		if !ok || kind == "" || scalar == "" {
+			return nil, fmt.Errorf("%q is not kind=Scalar", entry)
+		}
+		if !graphqlScalarKinds[kind] {
+			return nil, fmt.Errorf("unknown kind %q, valid kinds are: %s", kind, strings.Join(graphqlScalarKindNames(), ", "))
+		}
+		if !graphqlNamePattern.MatchString(scalar) || strings.HasPrefix(scalar, "__") {
+			return nil, fmt.Errorf("%q is not a GraphQL name", scalar)
+		}
+		if _, dup := scalars[kind]; dup {
+			return nil, fmt.Errorf("%q is mapped twice", kind)
+		}
+		scalars[kind] = scalar
+	}
+	return scalars, nil
+}
+
+// graphqlScalarKindNames returns, sorted, the detected types -graphql-scalars
+// can map.
+func graphqlScalarKindNames() []string {
+	kinds := make([]string, 0, len(graphqlScalarKinds))
+	for kind := range graphqlScalarKinds {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// graphqlReservedNames returns the scalar names an object type must not take:
+// the built-in scalars, the JSON scalar and the -graphql-scalars mappings.
+func (s *Server) graphqlReservedNames() map[string]bool {
+	names := map[string]bool{graphqlJSON: true}
+	for name := range graphqlBuiltinScalars {
+		names[name] = true
+	}
+	for _, name := range s.graphqlScalars {
+		names[name] = true
+	}
+	return names
+}
+
+// formatAsGraphQL describes the payload in the GraphQL schema language, with
+// an object type per object, or with -graphql-kind input an input type per
+// object named with an Input suffix, for mutation arguments.
+func (s *Server) formatAsGraphQL(sch *schema) (string, error) {
+	root := sch
+	if sch.kind != kindObject {
+		root = &schema{kind: kindObject, fields: []*field{{name: "data", schema: sch}}}
+	}
+
+	types := s.nestedObjects(s.structName, root)
+	scalars := make(map[string]bool)
+	definitions := make([]string, 0, len(types.objects)+1)
+	for _, obj := range types.objects {
+		definitions = append(definitions, s.generateGraphQLType(obj, types, scalars))
+	}
+
+	// Declare the custom scalars used, one per line
+	if len(scalars) > 0 {
+		names := make([]string, 0, len(scalars))
+		for name := range scalars {
+			names = append(names, "scalar "+name)
+		}
+		sort.Strings(names)
+		definitions = append([]string{strings.Join(names, "\n")}, definitions...)
+	}
+	return strings.Join(definitions, "\n\n"), nil
+}
+
+// generateGraphQLType writes the type of one object. GraphQL types need at
+// least one field, so an empty object gets a placeholder.
+func (s *Server) generateGraphQLType(obj *objectType, types *objectTypes, scalars map[string]bool) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s {\n", s.graphqlKeyword(), s.graphqlTypeName(obj.name))
+	if len(obj.schema.fields) == 0 {
+		b.WriteString("  _empty: Boolean\n")
+	}
+	names := keyNames(obj.schema.fields, graphqlName)
+	for i, f := range obj.schema.fields {
+		name := names[i]
+		var fieldType string
+		if scalar, ok := s.graphqlScalars["id"]; ok && isIDField(f) {
+			fieldType = useGraphQLScalar(scalar, scalars)
+		} else {
+			fieldType = s.getGraphQLType(f.schema, types, scalars)
+		}
+		if !f.optional && !f.schema.nullable && f.schema.kind != kindNull {
+			fieldType += "!"
+		}
+		fmt.Fprintf(&b, "  %s: %s\n", name, fieldType)
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// getGraphQLType returns the nullable GraphQL type of a schema. Int is 32
+// bits, so whole numbers beyond it are typed Float. Custom scalars used are
+// added to scalars.
+func (s *Server) getGraphQLType(sch *schema, types *objectTypes, scalars map[string]bool) string {
+	switch sch.kind {
+	case kindBool:
+		return "Boolean"
+	case kindNumber:
+		if sch.intBits() == 32 {
+			return "Int"
+		}
+		return "Float"
+	case kindString:
+		if scalar, ok := s.graphqlScalars["uuid"]; ok && allStrings(sch, uuidPattern.MatchString) {
+			return useGraphQLScalar(scalar, scalars)
+		}
+		if scalar, ok := s.graphqlScalars["date"]; ok && allStrings(sch, isDateString) {
+			return useGraphQLScalar(scalar, scalars)
+		}
+		return "String"
+	case kindArray:
+		if sch.elem == nil {
+			return "[" + useGraphQLScalar(graphqlJSON, scalars) + "]"
+		}
+		elemType := s.getGraphQLType(sch.elem, types, scalars)
+		if !sch.elem.nullable && sch.elem.kind != kindNull {
+			elemType += "!"
+		}
+		return "[" + elemType + "]"
+	case kindObject:
+		return s.graphqlTypeName(types.name(sch))
+	default:
+		return useGraphQLScalar(graphqlJSON, scalars)
+	}
+}
+
+// useGraphQLScalar returns a scalar name, recording it in scalars for
+// declaration unless it is built in.
+func useGraphQLScalar(name string, scalars map[string]bool) string {
+	if !graphqlBuiltinScalars[name] {
+		scalars[name] = true
+	}
+	return name
+}
+
+// isIDField reports whether a field holds identifiers, judging by its key:
+// id, or a key ending in _id or Id, of strings or whole numbers.
+func isIDField(f *field) bool {
+	if f.schema.kind != kindString && !f.schema.integral() {
+		return false
+	}
+	return strings.EqualFold(f.name, "id") || strings.HasSuffix(strings.ToLower(f.name), "_id") || strings.HasSuffix(f.name, "Id")
+}
+
+// graphqlKeyword returns the keyword declaring object types, type or input.
+func (s *Server) graphqlKeyword() string {
+	if s.graphqlKind == "input" {
+		return "input"
+	}
+	return "type"
+}
+
+// graphqlTypeName names the GraphQL type of an object, suffixed with Input
+// for input types as is customary.
+func (s *Server) graphqlTypeName(name string) string {
+	if s.graphqlKind == "input" {
+		return name + "Input"
+	}
+	return name
+}
+
+// graphqlName turns a JSON key into a GraphQL field name. Names starting with
+// two underscores are reserved for introspection.
+func graphqlName(key string) string {
+	name := sanitizeIdentifier(key)
+	if strings.HasPrefix(name, "__") {
+		return "f" + name
+	}
+	return name
+}
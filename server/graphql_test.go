@@ -0,0 +1,154 @@
+package server
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseGraphQLScalars(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     string
+		expected  map[string]string
+		expectErr string
+	}{
+		{
+			name:     "Empty",
+			value:    "",
+			expected: map[string]string{},
+		},
+		{
+			name:     "Several kinds",
+			value:    "date=DateTime, id = ID,uuid=UUID",
+			expected: map[string]string{"date": "DateTime", "id": "ID", "uuid": "UUID"},
+		},
+		{name: "Missing scalar", value: "date=", expectErr: `"date=" is not kind=Scalar`},
+		{name: "Unknown kind", value: "time=Time", expectErr: `unknown kind "time", valid kinds are: date, id, uuid`},
+		{name: "Invalid name", value: "date=Date-Time", expectErr: `"Date-Time" is not a GraphQL name`},
+		{name: "Reserved name", value: "id=__ID", expectErr: `"__ID" is not a GraphQL name`},
+		{name: "Duplicate kind", value: "id=ID,id=Key", expectErr: `"id" is mapped twice`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseGraphQLScalars(tt.value)
+			if tt.expectErr != "" {
+				if err == nil || err.Error() != tt.expectErr {
+					t.Fatalf("ParseGraphQLScalars() error = %v, want %s", err, tt.expectErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseGraphQLScalars() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("ParseGraphQLScalars() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFormatAsGraphQL(t *testing.T) {
+	testFormatCases(t, "graphql", []formatCase{
+		{
+			name: "Flat object",
+			data: map[string]interface{}{"name": "test", "value": 123.0, "ratio": 0.5, "active": true, "big": 1e12},
+			expectContains: []string{
+				"type GeneratedStruct {\n" +
+					"  active: Boolean!\n" +
+					"  big: Float!\n" +
+					"  name: String!\n" +
+					"  ratio: Float!\n" +
+					"  value: Int!\n" +
+					"}",
+			},
+			expectMissing: []string{"scalar"},
+		},
+		{
+			name: "Nested objects and arrays",
+			data: map[string]interface{}{
+				"user-info": map[string]interface{}{"id": 1.0},
+				"items":     []interface{}{map[string]interface{}{"sku": "a"}},
+				"tags":      []interface{}{"a", nil},
+				"empty":     []interface{}{},
+			},
+			expectContains: []string{
+				"scalar JSON\n\ntype GeneratedStruct {\n",
+				"  empty: [JSON]!\n",
+				"  items: [Items!]!\n",
+				"  tags: [String]!\n",
+				"  user_info: UserInfo!\n",
+				"type UserInfo {\n  id: Int!\n}",
+				"type Items {\n  sku: String!\n}",
+			},
+		},
+		{
+			name: "Optional and null fields",
+			records: []interface{}{
+				map[string]interface{}{"id": 1.0, "email": nil, "note": nil, "meta": map[string]interface{}{}},
+				map[string]interface{}{"id": 2.0, "email": "x@example.com"},
+			},
+			expectContains: []string{
+				"  email: String\n",
+				"  id: Int!\n",
+				"  meta: Meta\n",
+				"  note: JSON\n",
+				"type Meta {\n  _empty: Boolean\n}",
+			},
+		},
+		{
+			name: "Input types",
+			data: map[string]interface{}{"id": 1.0, "owner": map[string]interface{}{"name": "a"}},
+			opts: []Option{WithGraphQLKind("input")},
+			expectContains: []string{
+				"input GeneratedStructInput {\n  id: Int!\n  owner: OwnerInput!\n}",
+				"input OwnerInput {\n  name: String!\n}",
+			},
+			expectMissing: []string{"type "},
+		},
+		{
+			name: "Custom scalars",
+			data: map[string]interface{}{
+				"id":         "123e4567-e89b-12d3-a456-426614174000",
+				"owner_id":   7.0,
+				"ref":        "123e4567-e89b-12d3-a456-426614174000",
+				"created_at": "2024-05-01T12:30:00Z",
+				"birthday":   "1990-02-03",
+				"grid":       []interface{}{"2024-05-01"},
+				"name":       "2024",
+				"ids":        []interface{}{1.0},
+			},
+			opts: []Option{WithGraphQLScalars(map[string]string{"date": "DateTime", "id": "ID", "uuid": "UUID"})},
+			expectContains: []string{
+				"scalar DateTime\nscalar UUID\n\ntype GeneratedStruct {\n",
+				"  birthday: DateTime!\n",
+				"  created_at: DateTime!\n",
+				"  grid: [DateTime!]!\n",
+				"  id: ID!\n",
+				"  ids: [Int!]!\n",
+				"  name: String!\n",
+				"  owner_id: ID!\n",
+				"  ref: UUID!\n",
+			},
+			expectMissing: []string{"scalar ID", "scalar JSON"},
+		},
+		{
+			name:           "Reserved names",
+			data:           map[string]interface{}{"__typename": "User"},
+			expectContains: []string{"  f__typename: String!\n"},
+		},
+		{
+			name: "Colliding keys",
+			data: map[string]interface{}{"a b": "x", "a_b": 1.0},
+			expectContains: []string{
+				"  a_b2: String!\n",
+				"  a_b: Int!\n",
+			},
+		},
+		{
+			name:           "Non-object root",
+			data:           []interface{}{1.5},
+			expectContains: []string{"type GeneratedStruct {\n  data: [Float!]!\n}"},
+		},
+	})
+}
@@ -0,0 +1,141 @@
+package server
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// gzipMinSize is the smallest response body compressed. Smaller bodies grow
+// rather than shrink once the gzip header and trailer are added.
+const gzipMinSize = 1024
+
+// acceptsGzip reports whether an Accept-Encoding header allows gzip, either
+// by name or through a wildcard, with a nonzero quality.
+func acceptsGzip(acceptEncoding string) bool {
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding != "gzip" && coding != "x-gzip" && coding != "*" {
+			continue
+		}
+		if name, value, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(name) == "q" {
+			if q, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err != nil || q == 0 {
+				continue
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// gzipMiddleware compresses responses for clients that accept gzip, once the
+// body reaches gzipMinSize. Smaller bodies are sent as they are.
+func gzipMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Upgraded connections, such as WebSockets, need the connection itself
+		if r.Header.Get("Upgrade") != "" || !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+			w.Header().Add("Vary", "Accept-Encoding")
+			next.ServeHTTP(w, r)
+			return
+		}
+		gw := &gzipResponseWriter{ResponseWriter: w, status: http.StatusOK}
+		defer gw.close()
+		next.ServeHTTP(gw, r)
+	})
+}
+
+// gzipResponseWriter buffers the start of a response until it is known
+// whether the body is large enough to compress.
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	// committed is set once the headers were sent, compressed or not.
+	committed bool
+	buf       []byte
+	gz        *gzip.Writer
+}
+
+func (w *gzipResponseWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	w.status = status
+}
+
+func (w *gzipResponseWriter) Write(p []byte) (int, error) {
+	w.wroteHeader = true
+	if w.committed {
+		if w.gz != nil {
+			return w.gz.Write(p)
+		}
+		return w.ResponseWriter.Write(p)
+	}
+
+	w.buf = append(w.buf, p...)
+	if len(w.buf) >= gzipMinSize {
+		if err := w.commit(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// commit sends the headers and the buffered body, compressed when compress
+// is set and the handler did not encode the body itself.
+func (w *gzipResponseWriter) commit(compress bool) error {
+	w.committed = true
+	header := w.Header()
+	header.Add("Vary", "Accept-Encoding")
+	if compress && header.Get("Content-Encoding") == "" {
+		header.Set("Content-Encoding", "gzip")
+		header.Del("Content-Length")
+		w.gz = gzip.NewWriter(w.ResponseWriter)
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	if w.gz != nil {
+		_, err := w.gz.Write(buf)
+		return err
+	}
+	_, err := w.ResponseWriter.Write(buf)
+	return err
+}
+
+// Flush sends what was written so far, so that streamed responses are not
+// held back. A body flushed before reaching gzipMinSize is sent uncompressed.
+func (w *gzipResponseWriter) Flush() {
+	if !w.committed {
+		w.commit(false)
+	}
+	if w.gz != nil {
+		w.gz.Flush()
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *gzipResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// close sends a body that stayed under gzipMinSize, or finishes the gzip
+// stream.
+func (w *gzipResponseWriter) close() {
+	if !w.committed {
+		w.commit(false)
+	}
+	if w.gz != nil {
+		w.gz.Close()
+	}
+}
@@ -0,0 +1,164 @@
+package server
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAcceptsGzip(t *testing.T) {
+	tests := []struct {
+		acceptEncoding string
+		want           bool
+	}{
+		{"", false},
+		{"gzip", true},
+		{"deflate, gzip;q=0.5", true},
+		{"GZIP", true},
+		{"br, *", true},
+		{"gzip;q=0", false},
+		{"gzip;q=0.0, br", false},
+		{"identity", false},
+	}
+
+	for _, tt := range tests {
+		if got := acceptsGzip(tt.acceptEncoding); got != tt.want {
+			t.Errorf("acceptsGzip(%q) = %v, want %v", tt.acceptEncoding, got, tt.want)
+		}
+	}
+}
+
+func TestGzipMiddleware(t *testing.T) {
+	large := strings.Repeat("type GeneratedStruct struct {}\n", 64)
+	tests := []struct {
+		name           string
+		acceptEncoding string
+		handler        http.HandlerFunc
+		expectGzip     bool
+		expectStatus   int
+		expectBody     string
+	}{
+		{
+			name:           "Large body",
+			acceptEncoding: "gzip",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+				w.WriteHeader(http.StatusCreated)
+				// Written in pieces, crossing the threshold midway
+				io.WriteString(w, large[:100])
+				io.WriteString(w, large[100:])
+			},
+			expectGzip:   true,
+			expectStatus: http.StatusCreated,
+			expectBody:   large,
+		},
+		{
+			name:           "Tiny body",
+			acceptEncoding: "gzip",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				io.WriteString(w, `{"version":"0.1.0"}`)
+			},
+			expectStatus: http.StatusOK,
+			expectBody:   `{"version":"0.1.0"}`,
+		},
+		{
+			name: "Client without gzip",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				io.WriteString(w, large)
+			},
+			expectStatus: http.StatusOK,
+			expectBody:   large,
+		},
+		{
+			name:           "Flushed before the threshold",
+			acceptEncoding: "gzip",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				io.WriteString(w, "event 1\n")
+				w.(http.Flusher).Flush()
+				io.WriteString(w, large)
+			},
+			expectStatus: http.StatusOK,
+			expectBody:   "event 1\n" + large,
+		},
+		{
+			name:           "Error without a body",
+			acceptEncoding: "gzip",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			},
+			expectStatus: http.StatusNoContent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			rr := httptest.NewRecorder()
+			gzipMiddleware(tt.handler).ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectStatus {
+				t.Errorf("Handler returned wrong status code: got %v want %v", rr.Code, tt.expectStatus)
+			}
+			if vary := rr.Header().Values("Vary"); len(vary) != 1 || vary[0] != "Accept-Encoding" {
+				t.Errorf("Handler returned wrong Vary header: got %v", vary)
+			}
+
+			body := rr.Body.String()
+			encoding := rr.Header().Get("Content-Encoding")
+			if tt.expectGzip {
+				if encoding != "gzip" {
+					t.Fatalf("Handler returned wrong Content-Encoding: got %q want gzip", encoding)
+				}
+				reader, err := gzip.NewReader(rr.Body)
+				if err != nil {
+					t.Fatalf("Response is not gzip: %v", err)
+				}
+				decoded, err := io.ReadAll(reader)
+				if err != nil {
+					t.Fatalf("Error decompressing response: %v", err)
+				}
+				body = string(decoded)
+			} else if encoding != "" {
+				t.Errorf("Handler returned unexpected Content-Encoding: %q", encoding)
+			}
+			if body != tt.expectBody {
+				t.Errorf("Handler returned wrong body: got %q want %q", body, tt.expectBody)
+			}
+		})
+	}
+}
+
+func TestServer_Gzip(t *testing.T) {
+	srv := New(8080, "", false, false, WithGzip(true))
+	ts := httptest.NewServer(srv.httpServer().Handler)
+	defer ts.Close()
+
+	// The client asks for gzip and decompresses transparently
+	resp, err := http.Get(ts.URL + "/formats")
+	if err != nil {
+		t.Fatalf("GET /formats error = %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !resp.Uncompressed {
+		t.Errorf("GET /formats was not compressed")
+	}
+	if !strings.HasPrefix(string(body), `[{"name":"go"`) {
+		t.Errorf("GET /formats returned wrong body: %s", body)
+	}
+
+	resp, err = http.Get(ts.URL + "/version")
+	if err != nil {
+		t.Fatalf("GET /version error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.Uncompressed {
+		t.Errorf("GET /version was compressed below the minimum size")
+	}
+}
@@ -0,0 +1,132 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// haskellPreamble starts Haskell output with the extension and imports the
+// records need.
+const haskellPreamble = "{-# LANGUAGE DeriveGeneric #-}\n\n" +
+	"import Data.Aeson\n" +
+	"import Data.Text (Text)\n" +
+	"import GHC.Generics (Generic)\n\n"
+
+// haskellBuiltinTypes lists the Prelude types and classes and those the
+// imports bring in, which a record of the same name would make ambiguous.
+var haskellBuiltinTypes = map[string]bool{
+	"Array": true, "Bool": true, "Char": true, "Double": true, "Either": true,
+	"Float": true, "FromJSON": true, "Generic": true, "IO": true, "Int": true,
+	"Integer": true, "Maybe": true, "Object": true, "Ordering": true,
+	"Show": true, "String": true, "Text": true, "ToJSON": true, "Value": true,
+}
+
+func (s *Server) formatAsHaskell(sch *schema) (string, error) {
+	root := sch
+	if sch.kind != kindObject {
+		root = &schema{kind: kindObject, fields: []*field{{name: "data", schema: sch}}}
+	}
+
+	// Record fields share one namespace per module, so every type gets its
+	// own field prefix
+	types := s.nestedObjects(s.structName, root)
+	prefixes := make(map[string]bool)
+	records := make([]string, 0, len(types.objects))
+	for _, obj := range types.objects {
+		prefix := haskellPrefix(obj.name)
+		base := prefix
+		for n := 2; prefixes[prefix]; n++ {
+			prefix = fmt.Sprintf("%s%d", base, n)
+		}
+		prefixes[prefix] = true
+		records = append(records, s.generateHaskellRecord(obj, prefix, types))
+	}
+	return haskellPreamble + strings.Join(records, "\n"), nil
+}
+
+func (s *Server) generateHaskellRecord(obj *objectType, prefix string, types *objectTypes) string {
+	var result string
+	if len(obj.schema.fields) == 0 {
+		result = fmt.Sprintf("data %s = %s\n  deriving (Show, Generic)\n", obj.name, obj.name)
+	} else {
+		var fields, labels []string
+		used := make(map[string]bool, len(obj.schema.fields))
+		for i, f := range obj.schema.fields {
+			fieldType := s.getHaskellType(f.schema, types)
+			if f.optional {
+				fieldType = haskellMaybe(fieldType)
+			}
+			// Keys such as "a-b" and "a_b" share a name
+			name := uniqueName(haskellFieldName(prefix, f.name, i), used)
+			fields = append(fields, fmt.Sprintf("%s :: %s", name, fieldType))
+			labels = append(labels, fmt.Sprintf("--   %s -> %q", name, f.name))
+		}
+		result = fmt.Sprintf("-- JSON keys, restore them with an aeson fieldLabelModifier:\n%s\ndata %s = %s\n  { %s\n  } deriving (Show, Generic)\n",
+			strings.Join(labels, "\n"), obj.name, obj.name, strings.Join(fields, "\n  , "))
+	}
+	return result + fmt.Sprintf("\ninstance FromJSON %s\ninstance ToJSON %s\n", obj.name, obj.name)
+}
+
+func (s *Server) getHaskellType(sch *schema, types *objectTypes) string {
+	var haskellType string
+	switch sch.kind {
+	case kindBool:
+		haskellType = "Bool"
+	case kindNumber:
+		haskellType = "Double"
+	case kindString:
+		haskellType = "Text"
+	case kindArray:
+		elemType := "Value"
+		if sch.elem != nil {
+			elemType = s.getHaskellType(sch.elem, types)
+		}
+		haskellType = fmt.Sprintf("[%s]", elemType)
+	case kindObject:
+		haskellType = types.name(sch)
+	case kindNull:
+		return "Maybe Value"
+	default:
+		haskellType = "Value"
+	}
+	if sch.nullable {
+		return haskellMaybe(haskellType)
+	}
+	return haskellType
+}
+
+// haskellMaybe wraps a Haskell type in Maybe unless it already is one.
+func haskellMaybe(haskellType string) string {
+	if strings.HasPrefix(haskellType, "Maybe ") {
+		return haskellType
+	}
+	if strings.Contains(haskellType, " ") {
+		return "Maybe (" + haskellType + ")"
+	}
+	return "Maybe " + haskellType
+}
+
+// haskellPrefix builds a record field prefix from the capitals of a type name,
+// e.g. "gs" for GeneratedStruct.
+func haskellPrefix(typeName string) string {
+	var b strings.Builder
+	for _, r := range typeName {
+		if unicode.IsUpper(r) {
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	if b.Len() == 0 {
+		return "r"
+	}
+	return b.String()
+}
+
+// haskellFieldName prefixes a PascalCase JSON key, falling back to the field
+// position for keys without any usable characters.
+func haskellFieldName(prefix, key string, index int) string {
+	if name := toPascalCase(key); name != "" {
+		return prefix + name
+	}
+	return fmt.Sprintf("%sField%d", prefix, index)
+}
@@ -0,0 +1,51 @@
+package server
+
+import "testing"
+
+func TestFormatAsHaskell(t *testing.T) {
+	testFormatCases(t, "haskell", []formatCase{
+		{
+			name: "Flat object",
+			data: map[string]interface{}{"name": "test", "value": 123.0, "active": true},
+			expectContains: []string{
+				"import Data.Aeson",
+				"data GeneratedStruct = GeneratedStruct\n  { gsActive :: Bool\n  , gsName :: Text\n  , gsValue :: Double\n  } deriving (Show, Generic)",
+				`--   gsName -> "name"`,
+				"instance FromJSON GeneratedStruct",
+				"instance ToJSON GeneratedStruct",
+			},
+		},
+		{
+			name: "Nested objects and arrays",
+			data: map[string]interface{}{
+				"user_info": map[string]interface{}{"full_name": "test"},
+				"tags":      []interface{}{"a", "b"},
+			},
+			expectContains: []string{
+				"gsTags :: [Text]",
+				"gsUserInfo :: UserInfo",
+				"data UserInfo = UserInfo\n  { uiFullName :: Text\n  }",
+				`--   uiFullName -> "full_name"`,
+			},
+		},
+		{
+			name: "Optional and null fields",
+			records: []interface{}{
+				map[string]interface{}{"id": 1.0, "email": nil, "tags": []interface{}{"a"}},
+				map[string]interface{}{"id": 2.0, "email": "x@example.com"},
+			},
+			expectContains: []string{
+				"gsEmail :: Maybe Text",
+				"gsTags :: Maybe [Text]",
+			},
+		},
+		{
+			name: "Colliding keys",
+			data: map[string]interface{}{"a-b": 1.0, "a_b": "x"},
+			expectContains: []string{
+				"  { gsAB :: Double\n  , gsAB2 :: Text\n  }",
+				`--   gsAB2 -> "a_b"`,
+			},
+		},
+	})
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"fmt"
+	"time"
+)
+
+// now is stubbed in tests to get a stable timestamp.
+var now = time.Now
+
+// commentSuffixes closes the comments of formats without line comments.
+var commentSuffixes = map[string]string{
+	"ocaml": " *)",
+	"xsd":   " -->",
+}
+
+// commentPrefixes maps each format to the syntax starting a comment. Formats
+// missing from the map, such as the JSON based avro, cannot carry comments.
+var commentPrefixes = map[string]string{
+	"go":          "//",
+	"rust":        "//",
+	"typeddict":   "#",
+	"scala":       "//",
+	"haskell":     "--",
+	"zod":         "//",
+	"openapi":     "#",
+	"dart":        "//",
+	"c":           "//",
+	"elm":         "--",
+	"php":         "//",
+	"ocaml":       "(*",
+	"fsharp":      "//",
+	"ruby":        "#",
+	"crystal":     "#",
+	"objc":        "//",
+	"mermaid":     "%%",
+	"thrift":      "//",
+	"toml":        "#",
+	"kotlin":      "//",
+	"plantuml":    "'",
+	"clojure":     ";;",
+	"flatbuffers": "//",
+	"rbi":         "#",
+	"xsd":         "<!--",
+	"graphql":     "#",
+}
+
+// generatedComment returns the line marking output as generated from source,
+// following Go's "Code generated ... DO NOT EDIT." convention.
+func (s *Server) generatedComment(source string) string {
+	prefix, ok := commentPrefixes[s.formatType]
+	if !s.generatedHeader || !ok {
+		return ""
+	}
+	return fmt.Sprintf("%s Code generated by reqparser from %s at %s; DO NOT EDIT.%s\n\n",
+		prefix, source, now().UTC().Format(time.RFC3339), commentSuffixes[s.formatType])
+}
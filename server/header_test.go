@@ -0,0 +1,73 @@
+package server
+
+import (
+	"io"
+	"log"
+	"testing"
+	"time"
+)
+
+func TestGeneratedComment(t *testing.T) {
+	now = func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	tests := []struct {
+		name       string
+		formatType string
+		enabled    bool
+		expect     string
+	}{
+		{
+			name:       "Go format",
+			formatType: "go",
+			enabled:    true,
+			expect:     "// Code generated by reqparser from /api/data at 2024-01-02T03:04:05Z; DO NOT EDIT.\n\n",
+		},
+		{
+			name:       "Python format",
+			formatType: "typeddict",
+			enabled:    true,
+			expect:     "# Code generated by reqparser from /api/data at 2024-01-02T03:04:05Z; DO NOT EDIT.\n\n",
+		},
+		{
+			name:       "Haskell format",
+			formatType: "haskell",
+			enabled:    true,
+			expect:     "-- Code generated by reqparser from /api/data at 2024-01-02T03:04:05Z; DO NOT EDIT.\n\n",
+		},
+		{
+			name:       "OCaml block comment",
+			formatType: "ocaml",
+			enabled:    true,
+			expect:     "(* Code generated by reqparser from /api/data at 2024-01-02T03:04:05Z; DO NOT EDIT. *)\n\n",
+		},
+		{
+			name:       "PHP format after the opening tag",
+			formatType: "php",
+			enabled:    true,
+			expect:     "<?php\n\n// Code generated by reqparser from /api/data at 2024-01-02T03:04:05Z; DO NOT EDIT.\n\nclass",
+		},
+		{
+			name:       "JSON format without comments",
+			formatType: "avro",
+			enabled:    true,
+		},
+		{
+			name:       "Disabled",
+			formatType: "go",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := New(8080, tt.formatType, false, false, WithGeneratedHeader(tt.enabled))
+			result, err := srv.generate(log.New(io.Discard, "", 0), inferSchema(map[string]interface{}{"name": "test"}), "/api/data")
+			if err != nil {
+				t.Fatalf("generate() error = %v", err)
+			}
+			if got := result[:len(tt.expect)]; got != tt.expect {
+				t.Errorf("generate() header = %q, want %q", got, tt.expect)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ParseRawRequest parses a request saved as raw HTTP text: a request line,
+// headers, a blank line and the body. Lines may end in LF or CRLF, and the
+// HTTP version may be left off the request line as many .http files do.
+func ParseRawRequest(raw []byte) (*http.Request, error) {
+	raw = bytes.TrimLeft(raw, "\r\n\t ")
+
+	// Add a protocol version to request lines like "POST /api/data"
+	requestLine, rest, _ := bytes.Cut(raw, []byte("\n"))
+	line := strings.TrimSuffix(string(requestLine), "\r")
+	if len(strings.Fields(line)) == 2 {
+		raw = append([]byte(line+" HTTP/1.1\r\n"), rest...)
+	}
+
+	reader := bufio.NewReader(bytes.NewReader(raw))
+	req, err := http.ReadRequest(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	// Saved files rarely carry an accurate Content-Length, so take everything
+	// after the headers unless the body is chunked
+	var body []byte
+	if len(req.TransferEncoding) > 0 {
+		body, err = io.ReadAll(req.Body)
+	} else {
+		body, err = io.ReadAll(reader)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+	return req, nil
+}
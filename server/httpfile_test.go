@@ -0,0 +1,67 @@
+package server
+
+import (
+	"io"
+	"testing"
+)
+
+func TestParseRawRequest(t *testing.T) {
+	tests := []struct {
+		name              string
+		raw               string
+		expectMethod      string
+		expectPath        string
+		expectContentType string
+		expectBody        string
+	}{
+		{
+			name:              "LF line endings",
+			raw:               "POST /api/data HTTP/1.1\nHost: example.com\nContent-Type: application/json\n\n{\"name\":\"test\"}\n",
+			expectMethod:      "POST",
+			expectPath:        "/api/data",
+			expectContentType: "application/json",
+			expectBody:        "{\"name\":\"test\"}\n",
+		},
+		{
+			name:              "CRLF line endings",
+			raw:               "PUT /api/data HTTP/1.1\r\nHost: example.com\r\nContent-Type: application/json\r\n\r\n{\"name\":\"test\"}",
+			expectMethod:      "PUT",
+			expectPath:        "/api/data",
+			expectContentType: "application/json",
+			expectBody:        `{"name":"test"}`,
+		},
+		{
+			name:              "Missing version and stale Content-Length",
+			raw:               "\nPOST http://example.com/api/data\nContent-Type: application/json\nContent-Length: 2\n\n{\"name\":\"test\"}",
+			expectMethod:      "POST",
+			expectPath:        "/api/data",
+			expectContentType: "application/json",
+			expectBody:        `{"name":"test"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := ParseRawRequest([]byte(tt.raw))
+			if err != nil {
+				t.Fatalf("ParseRawRequest() error = %v", err)
+			}
+			if req.Method != tt.expectMethod {
+				t.Errorf("Method = %s, want %s", req.Method, tt.expectMethod)
+			}
+			if req.URL.Path != tt.expectPath {
+				t.Errorf("Path = %s, want %s", req.URL.Path, tt.expectPath)
+			}
+			if got := req.Header.Get("Content-Type"); got != tt.expectContentType {
+				t.Errorf("Content-Type = %s, want %s", got, tt.expectContentType)
+			}
+			body, err := io.ReadAll(req.Body)
+			if err != nil {
+				t.Fatalf("Failed to read body: %v", err)
+			}
+			if string(body) != tt.expectBody {
+				t.Errorf("Body = %q, want %q", body, tt.expectBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// syntaxSnippetRadius is how many bytes either side of a JSON syntax error
+// are quoted back to the client.
+const syntaxSnippetRadius = 16
+
+// describeJSONError explains why body failed to parse. Syntax errors gain
+// their offset and the surrounding bytes, so a malformed payload can be
+// located without reproducing the request.
+func describeJSONError(body []byte, err error) string {
+	var syntaxErr *json.SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		return err.Error()
+	}
+
+	// The offset counts the bytes read up to and including the bad one
+	offset := int(syntaxErr.Offset)
+	start := max(0, offset-1-syntaxSnippetRadius)
+	end := min(len(body), offset+syntaxSnippetRadius)
+	if start > end {
+		start = end
+	}
+	return fmt.Sprintf("%v at offset %d near %q", err, syntaxErr.Offset, body[start:end])
+}
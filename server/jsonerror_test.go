@@ -0,0 +1,51 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDescribeJSONError(t *testing.T) {
+	long := `{"padding": "` + strings.Repeat("x", 40) + `", "value": nope, "more": "` + strings.Repeat("y", 40) + `"}`
+
+	tests := []struct {
+		name   string
+		body   string
+		expect string
+	}{
+		{
+			name:   "Syntax error mid body",
+			body:   long,
+			expect: `invalid character 'o' in literal null (expecting 'u') at offset 67 near "xxx\", \"value\": nope, \"more\": \"yyy"`,
+		},
+		{
+			name:   "Syntax error on the first byte",
+			body:   `hello`,
+			expect: `invalid character 'h' looking for beginning of value at offset 1 near "hello"`,
+		},
+		{
+			name:   "Truncated body",
+			body:   `{"name":`,
+			expect: `unexpected end of JSON input at offset 8 near "{\"name\":"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v interface{}
+			err := json.Unmarshal([]byte(tt.body), &v)
+			if err == nil {
+				t.Fatal("json.Unmarshal() succeeded, want a syntax error")
+			}
+			if got := describeJSONError([]byte(tt.body), err); got != tt.expect {
+				t.Errorf("describeJSONError() = %s, want %s", got, tt.expect)
+			}
+		})
+	}
+
+	if got := describeJSONError(nil, errors.New("unexpected EOF")); got != "unexpected EOF" {
+		t.Errorf("describeJSONError() = %s, want the error unchanged", got)
+	}
+}
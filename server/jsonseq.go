@@ -0,0 +1,28 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// recordSeparator starts every record of a JSON text sequence (RFC 7464).
+const recordSeparator = 0x1E
+
+// decodeJSONSeq decodes a JSON text sequence, in which each value is
+// preceded by a record separator and usually followed by a newline. Empty
+// records, such as those of consecutive separators, are skipped.
+func decodeJSONSeq(body []byte) ([]interface{}, error) {
+	var records []interface{}
+	for i, text := range bytes.Split(body, []byte{recordSeparator}) {
+		if len(bytes.TrimSpace(text)) == 0 {
+			continue
+		}
+		var record interface{}
+		if err := json.Unmarshal(text, &record); err != nil {
+			return nil, fmt.Errorf("record %d: %s", i, describeJSONError(text, err))
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
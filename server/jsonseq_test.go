@@ -0,0 +1,50 @@
+package server
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDecodeJSONSeq(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []interface{}
+		wantErr bool
+	}{
+		{
+			name:  "Records with trailing newlines",
+			input: "\x1e{\"id\":1}\n\x1e[true]\n\x1e\"a\"\n",
+			want: []interface{}{
+				map[string]interface{}{"id": 1.0},
+				[]interface{}{true},
+				"a",
+			},
+		},
+		{
+			name:  "Empty records are skipped",
+			input: "\x1e\x1e{\"id\":1}\x1e \n",
+			want:  []interface{}{map[string]interface{}{"id": 1.0}},
+		},
+		{
+			name: "Empty",
+		},
+		{
+			name:    "Two values in one record",
+			input:   "\x1e{\"id\":1}\n{\"id\":2}\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeJSONSeq([]byte(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeJSONSeq() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeJSONSeq() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// kotlinStyleKotlinx annotates classes for kotlinx.serialization.
+	kotlinStyleKotlinx = "kotlinx"
+	// kotlinStyleJackson emits plain data classes with Jackson @JsonProperty.
+	kotlinStyleJackson = "jackson"
+	// kotlinStyleMoshi emits plain data classes with Moshi @Json.
+	kotlinStyleMoshi = "moshi"
+)
+
+// kotlinKeywords lists the hard keywords that must be escaped with backticks
+// when used as property names.
+var kotlinKeywords = map[string]bool{
+	"as": true, "break": true, "class": true, "continue": true, "do": true,
+	"else": true, "false": true, "for": true, "fun": true, "if": true,
+	"in": true, "interface": true, "is": true, "null": true, "object": true,
+	"package": true, "return": true, "super": true, "this": true,
+	"throw": true, "true": true, "try": true, "typealias": true,
+	"typeof": true, "val": true, "var": true, "when": true, "while": true,
+}
+
+// kotlinBuiltinTypes lists the kotlin types and imported annotations that
+// generated classes use, which a class of the same name would shadow.
+var kotlinBuiltinTypes = map[string]bool{
+	"Any": true, "Boolean": true, "Double": true, "Int": true, "Json": true,
+	"JsonClass": true, "JsonElement": true, "JsonProperty": true, "List": true,
+	"Long": true, "Map": true, "SerialName": true, "Serializable": true,
+	"String": true,
+}
+
+func (s *Server) formatAsKotlin(sch *schema) (string, error) {
+	root := sch
+	if sch.kind != kindObject {
+		root = &schema{kind: kindObject, fields: []*field{{name: "data", schema: sch}}}
+	}
+
+	types := s.nestedObjects(s.structName, root)
+	imports := make(map[string]bool)
+	classes := make([]string, 0, len(types.objects))
+	for _, obj := range types.objects {
+		classes = append(classes, s.generateKotlinClass(obj, types, imports))
+	}
+
+	// Import only the annotations in use, as the plain styles may need none
+	var header string
+	if len(imports) > 0 {
+		lines := make([]string, 0, len(imports))
+		for name := range imports {
+			lines = append(lines, "import "+name+"\n")
+		}
+		sort.Strings(lines)
+		header = strings.Join(lines, "") + "\n"
+	}
+	return header + strings.Join(classes, "\n\n"), nil
+}
+
+func (s *Server) generateKotlinClass(obj *objectType, types *objectTypes, imports map[string]bool) string {
+	var b strings.Builder
+	switch s.kotlinStyle {
+	case kotlinStyleJackson:
+		// Jackson's Kotlin module needs no class annotation
+	case kotlinStyleMoshi:
+		imports["com.squareup.moshi.JsonClass"] = true
+		b.WriteString("@JsonClass(generateAdapter = true)\n")
+	default:
+		imports["kotlinx.serialization.Serializable"] = true
+		b.WriteString("@Serializable\n")
+	}
+	fmt.Fprintf(&b, "data class %s(\n", obj.name)
+
+	used := make(map[string]bool)
+	for i, f := range obj.schema.fields {
+		name := kotlinPropertyName(f.name, i)
+		base := name
+		for n := 2; used[name]; n++ {
+			name = fmt.Sprintf("%s%d", base, n)
+		}
+		used[name] = true
+
+		fieldType := s.getKotlinType(f.schema, types, imports)
+		if f.optional {
+			fieldType = kotlinNullable(fieldType)
+		}
+		// Nullable properties default to null so missing keys decode
+		var defaultValue string
+		if strings.HasSuffix(fieldType, "?") {
+			defaultValue = " = null"
+		}
+
+		b.WriteString("    ")
+		if name != f.name {
+			key := kotlinString(f.name)
+			switch s.kotlinStyle {
+			case kotlinStyleJackson:
+				imports["com.fasterxml.jackson.annotation.JsonProperty"] = true
+				fmt.Fprintf(&b, "@JsonProperty(%s) ", key)
+			case kotlinStyleMoshi:
+				imports["com.squareup.moshi.Json"] = true
+				fmt.Fprintf(&b, "@Json(name = %s) ", key)
+			default:
+				imports["kotlinx.serialization.SerialName"] = true
+				fmt.Fprintf(&b, "@SerialName(%s) ", key)
+			}
+		}
+		fmt.Fprintf(&b, "val %s: %s%s,\n", kotlinIdentifier(name), fieldType, defaultValue)
+	}
+	b.WriteString(")")
+	return b.String()
+}
+
+func (s *Server) getKotlinType(sch *schema, types *objectTypes, imports map[string]bool) string {
+	var kotlinType string
+	switch sch.kind {
+	case kindBool:
+		kotlinType = "Boolean"
+	case kindNumber:
+		kotlinType = "Double"
+		if sch.integral() {
+			kotlinType = "Long"
+		}
+	case kindString:
+		kotlinType = "String"
+	case kindArray:
+		var elemType string
+		if sch.elem != nil {
+			elemType = s.getKotlinType(sch.elem, types, imports)
+		} else {
+			elemType = s.kotlinAny(imports)
+		}
+		kotlinType = fmt.Sprintf("List<%s>", elemType)
+	case kindObject:
+		kotlinType = types.name(sch)
+	case kindNull:
+		return kotlinNullable(s.kotlinAny(imports))
+	default:
+		kotlinType = s.kotlinAny(imports)
+	}
+	if sch.nullable {
+		return kotlinNullable(kotlinType)
+	}
+	return kotlinType
+}
+
+// kotlinAny is the catch-all type of values with no single type.
+// kotlinx.serialization cannot decode Any, so that style uses JsonElement.
+func (s *Server) kotlinAny(imports map[string]bool) string {
+	if s.kotlinStyle == kotlinStyleJackson || s.kotlinStyle == kotlinStyleMoshi {
+		return "Any"
+	}
+	imports["kotlinx.serialization.json.JsonElement"] = true
+	return "JsonElement"
+}
+
+// kotlinNullable marks a Kotlin type nullable unless it already is.
+func kotlinNullable(kotlinType string) string {
+	if strings.HasSuffix(kotlinType, "?") {
+		return kotlinType
+	}
+	return kotlinType + "?"
+}
+
+// kotlinPropertyName converts a JSON key into a camelCase property name,
+// falling back to the field position for keys without usable characters.
+func kotlinPropertyName(key string, index int) string {
+	name := toCamelCase(key)
+	if name == "" {
+		return fmt.Sprintf("field%d", index)
+	}
+	if name[0] >= '0' && name[0] <= '9' {
+		name = "n" + name
+	}
+	return name
+}
+
+// kotlinIdentifier escapes hard keywords with backticks.
+func kotlinIdentifier(name string) string {
+	if kotlinKeywords[name] {
+		return "`" + name + "`"
+	}
+	return name
+}
+
+// kotlinString quotes a value as a Kotlin string literal, escaping the $ of
+// string templates.
+func kotlinString(value string) string {
+	return strings.ReplaceAll(strconv.Quote(value), "$", `\$`)
+}
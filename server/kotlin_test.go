@@ -0,0 +1,80 @@
+package server
+
+import "testing"
+
+func TestFormatAsKotlin(t *testing.T) {
+	testFormatCases(t, "kotlin", []formatCase{
+		{
+			name: "kotlinx.serialization by default",
+			data: map[string]interface{}{"name": "test", "value": 123.0, "ratio": 0.5, "active": true},
+			expectContains: []string{
+				"import kotlinx.serialization.Serializable\n\n" +
+					"@Serializable\n" +
+					"data class GeneratedStruct(\n" +
+					"    val active: Boolean,\n" +
+					"    val name: String,\n" +
+					"    val ratio: Double,\n" +
+					"    val value: Long,\n" +
+					")",
+			},
+			expectMissing: []string{"SerialName"},
+		},
+		{
+			name: "kotlinx.serialization renamed keys and nested objects",
+			opts: []Option{WithKotlinStyle(kotlinStyleKotlinx)},
+			data: map[string]interface{}{
+				"user-info": map[string]interface{}{"id": 1.0},
+				"class":     "a",
+				"price$":    1.5,
+				"mixed":     []interface{}{1.0, "a"},
+			},
+			expectContains: []string{
+				"import kotlinx.serialization.SerialName\nimport kotlinx.serialization.Serializable\nimport kotlinx.serialization.json.JsonElement\n",
+				"    val `class`: String,\n",
+				"    val mixed: List<JsonElement>,\n",
+				"    @SerialName(\"price\\$\") val price: Double,\n",
+				"    @SerialName(\"user-info\") val userInfo: UserInfo,\n",
+				"@Serializable\ndata class UserInfo(\n    val id: Long,\n)",
+			},
+		},
+		{
+			name: "Jackson plain data classes",
+			opts: []Option{WithKotlinStyle(kotlinStyleJackson)},
+			data: map[string]interface{}{"user_id": 1.0, "mixed": []interface{}{1.0, "a"}},
+			expectContains: []string{
+				"import com.fasterxml.jackson.annotation.JsonProperty\n\ndata class GeneratedStruct(\n",
+				"    val mixed: List<Any>,\n",
+				"    @JsonProperty(\"user_id\") val userId: Long,\n",
+			},
+			expectMissing: []string{"@Serializable", "kotlinx"},
+		},
+		{
+			name: "Moshi plain data classes",
+			opts: []Option{WithKotlinStyle(kotlinStyleMoshi)},
+			data: map[string]interface{}{"user_id": 1.0},
+			expectContains: []string{
+				"import com.squareup.moshi.Json\nimport com.squareup.moshi.JsonClass\n\n",
+				"@JsonClass(generateAdapter = true)\ndata class GeneratedStruct(\n",
+				"    @Json(name = \"user_id\") val userId: Long,\n",
+			},
+		},
+		{
+			name: "Optional and null fields",
+			records: []interface{}{
+				map[string]interface{}{"id": 1.0, "email": nil, "note": nil},
+				map[string]interface{}{"id": 2.0, "email": "x@example.com"},
+			},
+			expectContains: []string{
+				"    val email: String? = null,\n",
+				"    val id: Long,\n",
+				"    val note: JsonElement? = null,\n",
+			},
+		},
+		{
+			name:           "Non-object root",
+			data:           []interface{}{"a"},
+			expectContains: []string{"    val data: List<String>,\n"},
+			expectMissing:  []string{"JsonElement"},
+		},
+	})
+}
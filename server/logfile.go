@@ -0,0 +1,98 @@
+package server
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// logBackups is how many rotated log files are kept beside the current one.
+const logBackups = 5
+
+// RotatingFile appends log output to a file. Once a write would take the file
+// past its maximum size, the file is renamed to path.1, older backups are
+// shifted to path.2 and so on up to path.5, and a fresh file is started.
+type RotatingFile struct {
+	path    string
+	maxSize int64
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// OpenRotatingFile opens path for appending, creating it if needed. A maxSize
+// of zero or less never rotates.
+func OpenRotatingFile(path string, maxSize int64) (*RotatingFile, error) {
+	f := &RotatingFile{path: path, maxSize: maxSize}
+	if err := f.open(os.O_APPEND); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *RotatingFile) open(mode int) error {
+	file, err := os.OpenFile(f.path, os.O_WRONLY|os.O_CREATE|mode, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+// Write appends p, rotating first when it would not fit. A single write
+// larger than the maximum size still goes to one file, so that log lines are
+// never split.
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+	if f.maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// rotate shifts the backups along, dropping the oldest, and starts a new file.
+func (f *RotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	f.file = nil
+	for i := logBackups - 1; i > 0; i-- {
+		err := os.Rename(fmt.Sprintf("%s.%d", f.path, i), fmt.Sprintf("%s.%d", f.path, i+1))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := os.Rename(f.path, f.path+".1"); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return f.open(os.O_TRUNC)
+}
+
+// Close closes the current file. Later writes fail.
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
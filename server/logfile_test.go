@@ -0,0 +1,102 @@
+package server
+
+import (
+	"fmt"
+	"log"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reqparser.log")
+	if err := os.WriteFile(path, []byte("earlier run\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := OpenRotatingFile(path, 32)
+	if err != nil {
+		t.Fatalf("OpenRotatingFile() error = %v", err)
+	}
+	defer file.Close()
+
+	// Each line is 10 bytes, so a fresh file takes three
+	for i := 0; i < 9; i++ {
+		if _, err := fmt.Fprintf(file, "line %04d\n", i); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+
+	expected := map[string]string{
+		path:        "line 0008\n",
+		path + ".1": "line 0005\nline 0006\nline 0007\n",
+		path + ".2": "line 0002\nline 0003\nline 0004\n",
+		path + ".3": "earlier run\nline 0000\nline 0001\n",
+	}
+	for name, want := range expected {
+		got, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("ReadFile(%s) error = %v", name, err)
+		}
+		if string(got) != want {
+			t.Errorf("%s = %q, want %q", filepath.Base(name), got, want)
+		}
+	}
+}
+
+func TestRotatingFile_KeepsBackups(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "reqparser.log")
+	file, err := OpenRotatingFile(path, 1)
+	if err != nil {
+		t.Fatalf("OpenRotatingFile() error = %v", err)
+	}
+	defer file.Close()
+
+	for i := 0; i < logBackups+3; i++ {
+		fmt.Fprintf(file, "line %d\n", i)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != logBackups+1 {
+		t.Errorf("got %d files, want %d", len(entries), logBackups+1)
+	}
+	oldest, err := os.ReadFile(fmt.Sprintf("%s.%d", path, logBackups))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := fmt.Sprintf("line %d\n", 2); string(oldest) != want {
+		t.Errorf("oldest backup = %q, want %q", oldest, want)
+	}
+}
+
+func TestRotatingFile_RequestLogs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reqparser.log")
+	file, err := OpenRotatingFile(path, 0)
+	if err != nil {
+		t.Fatalf("OpenRotatingFile() error = %v", err)
+	}
+	defer file.Close()
+
+	log.SetOutput(file)
+	defer log.SetOutput(os.Stderr)
+
+	srv := New(8080, "go", false, false)
+	req := httptest.NewRequest("POST", "/api/data", strings.NewReader(`{"id":1}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Request-ID", "abc")
+	srv.handleRequest(httptest.NewRecorder(), req)
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(got), "[abc] Struct format:\ntype GeneratedStruct struct {") {
+		t.Errorf("log file does not contain the generated struct\nGot: %s", got)
+	}
+}
@@ -0,0 +1,93 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+)
+
+// mermaidPreamble starts Mermaid output, declaring a class diagram.
+const mermaidPreamble = "classDiagram\n"
+
+func (s *Server) formatAsMermaid(sch *schema) (string, error) {
+	root := sch
+	if sch.kind != kindObject {
+		root = &schema{kind: kindObject, fields: []*field{{name: "data", schema: sch}}}
+	}
+
+	types := s.nestedObjects(s.structName, root)
+	var b strings.Builder
+	b.WriteString(mermaidPreamble)
+
+	var relations []string
+	seen := make(map[string]bool)
+	for _, obj := range types.objects {
+		fmt.Fprintf(&b, "    class %s {\n", obj.name)
+		// Keys sanitized alike, such as "a b" and "a-b", need distinct members
+		names := keyNames(obj.schema.fields, sanitizeIdentifier)
+		for i, f := range obj.schema.fields {
+			fieldType := mermaidType(f.schema, types)
+			if f.optional && !strings.HasSuffix(fieldType, "?") {
+				fieldType += "?"
+			}
+			fmt.Fprintf(&b, "        +%s %s\n", fieldType, names[i])
+
+			if relation := classRelation(obj.name, names[i], f, types); relation != "" && !seen[relation] {
+				seen[relation] = true
+				relations = append(relations, relation)
+			}
+		}
+		b.WriteString("    }\n")
+	}
+	for _, relation := range relations {
+		fmt.Fprintf(&b, "    %s\n", relation)
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+// classRelation describes the composition between an object and the object
+// type held by one of its fields, labelled with the field's member name, or
+// returns an empty string when the field holds no object. Mermaid and
+// PlantUML share the relation syntax.
+func classRelation(parent, label string, f *field, types *objectTypes) string {
+	sch, many := f.schema, false
+	for sch.kind == kindArray && sch.elem != nil {
+		sch, many = sch.elem, true
+	}
+	if sch.kind != kindObject {
+		return ""
+	}
+	if many {
+		return fmt.Sprintf("%s \"1\" *-- \"*\" %s : %s", parent, types.name(sch), label)
+	}
+	return fmt.Sprintf("%s *-- %s : %s", parent, types.name(sch), label)
+}
+
+// mermaidType names a schema in class members, with generics in Mermaid's
+// tilde syntax and a question mark for nullable values.
+func mermaidType(sch *schema, types *objectTypes) string {
+	var name string
+	switch sch.kind {
+	case kindBool:
+		name = "Boolean"
+	case kindNumber:
+		name = "Number"
+	case kindString:
+		name = "String"
+	case kindArray:
+		elemType := "Any"
+		if sch.elem != nil {
+			elemType = mermaidType(sch.elem, types)
+		}
+		name = fmt.Sprintf("List~%s~", elemType)
+	case kindObject:
+		name = types.name(sch)
+	case kindNull:
+		return "Any?"
+	default:
+		name = "Any"
+	}
+	if sch.nullable {
+		return name + "?"
+	}
+	return name
+}
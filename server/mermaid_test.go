@@ -0,0 +1,67 @@
+package server
+
+import "testing"
+
+func TestFormatAsMermaid(t *testing.T) {
+	testFormatCases(t, "mermaid", []formatCase{
+		{
+			name: "Flat object",
+			data: map[string]interface{}{"name": "test", "value": 123.0, "active": true},
+			expectContains: []string{
+				"classDiagram\n    class GeneratedStruct {\n" +
+					"        +Boolean active\n" +
+					"        +String name\n" +
+					"        +Number value\n" +
+					"    }",
+			},
+		},
+		{
+			name: "Nested objects and arrays",
+			data: map[string]interface{}{
+				"user-info": map[string]interface{}{"id": 1.0},
+				"items":     []interface{}{map[string]interface{}{"sku": "a"}},
+				"tags":      []interface{}{"a"},
+			},
+			expectContains: []string{
+				"        +List~Items~ items\n",
+				"        +List~String~ tags\n",
+				"        +UserInfo user_info\n",
+				"    class UserInfo {\n        +Number id\n    }",
+				"    GeneratedStruct \"1\" *-- \"*\" Items : items\n",
+				"    GeneratedStruct *-- UserInfo : user_info",
+			},
+		},
+		{
+			name: "Optional and null fields",
+			records: []interface{}{
+				map[string]interface{}{"id": 1.0, "email": nil, "note": nil},
+				map[string]interface{}{"id": 2.0, "email": "x@example.com"},
+			},
+			expectContains: []string{
+				"        +String? email\n",
+				"        +Number id\n",
+				"        +Any? note\n",
+			},
+		},
+		{
+			name: "Keys that are not identifiers",
+			data: map[string]interface{}{"2fa": "x", "a b": "y"},
+			expectContains: []string{
+				"        +String _2fa\n",
+				"        +String a_b\n",
+			},
+		},
+		{
+			name: "Colliding keys",
+			data: map[string]interface{}{"a b": "x", "a-b": "y", "a_b": 1.0, "e": "z", "é": "w"},
+			expectContains: []string{
+				"        +String a_b2\n        +String a_b3\n        +Number a_b\n        +String e\n        +String e2\n",
+			},
+		},
+		{
+			name:           "Non-object root",
+			data:           []interface{}{1.0},
+			expectContains: []string{"        +List~Number~ data\n"},
+		},
+	})
+}
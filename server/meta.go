@@ -0,0 +1,60 @@
+package server
+
+// bodyMeta summarizes a JSON body in the echo response, as a quick signal of
+// what the inference made of it.
+type bodyMeta struct {
+	// Fields counts the top-level fields, those of the elements for an
+	// array of objects.
+	Fields int `json:"fields"`
+	// Depth counts the levels of nested arrays and objects.
+	Depth int `json:"depth"`
+	// OptionalFields counts the fields, at any depth, missing from some of
+	// the objects merged into their type.
+	OptionalFields int `json:"optional_fields"`
+	// Bytes is the size of the body once decoded.
+	Bytes int `json:"bytes"`
+}
+
+func newBodyMeta(sch *schema, size int) *bodyMeta {
+	top := sch
+	for top.kind == kindArray && top.elem != nil {
+		top = top.elem
+	}
+	return &bodyMeta{
+		Fields:         len(top.fields),
+		Depth:          schemaDepth(sch),
+		OptionalFields: countOptional(sch),
+		Bytes:          size,
+	}
+}
+
+// schemaDepth counts the levels of nested arrays and objects in a schema,
+// zero for a scalar.
+func schemaDepth(sch *schema) int {
+	if sch.kind != kindArray && sch.kind != kindObject {
+		return 0
+	}
+	deepest := 0
+	if sch.elem != nil {
+		deepest = schemaDepth(sch.elem)
+	}
+	for _, f := range sch.fields {
+		deepest = max(deepest, schemaDepth(f.schema))
+	}
+	return deepest + 1
+}
+
+// countOptional counts the optional fields of every object in a schema.
+func countOptional(sch *schema) int {
+	count := 0
+	if sch.elem != nil {
+		count += countOptional(sch.elem)
+	}
+	for _, f := range sch.fields {
+		if f.optional {
+			count++
+		}
+		count += countOptional(f.schema)
+	}
+	return count
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewBodyMeta(t *testing.T) {
+	tests := []struct {
+		name    string
+		records []interface{}
+		size    int
+		want    bodyMeta
+	}{
+		{
+			name:    "Nested object",
+			records: []interface{}{map[string]interface{}{"id": 1.0, "owner": map[string]interface{}{"tags": []interface{}{"a"}}}},
+			size:    42,
+			want:    bodyMeta{Fields: 2, Depth: 3, Bytes: 42},
+		},
+		{
+			name: "Merged records",
+			records: []interface{}{
+				map[string]interface{}{"id": 1.0, "items": []interface{}{map[string]interface{}{"sku": "a", "qty": 1.0}, map[string]interface{}{"sku": "b"}}},
+				map[string]interface{}{"id": 2.0, "note": "x"},
+			},
+			want: bodyMeta{Fields: 3, Depth: 3, OptionalFields: 3},
+		},
+		{
+			name:    "Array of objects",
+			records: []interface{}{[]interface{}{map[string]interface{}{"a": 1.0, "b": 2.0}}},
+			want:    bodyMeta{Fields: 2, Depth: 2},
+		},
+		{
+			name:    "Scalar",
+			records: []interface{}{"text"},
+			want:    bodyMeta{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newBodyMeta(inferRecords(tt.records), tt.size); !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("newBodyMeta() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServer_Meta(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       []Option
+		body       string
+		expectMeta *bodyMeta
+	}{
+		{
+			name:       "Meta enabled",
+			opts:       []Option{WithMeta(true)},
+			body:       `{"id":1,"owner":{"name":"alice"}}`,
+			expectMeta: &bodyMeta{Fields: 2, Depth: 2, Bytes: 33},
+		},
+		{
+			name: "Meta disabled by default",
+			body: `{"id":1}`,
+		},
+		{
+			name: "No JSON body",
+			opts: []Option{WithMeta(true)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := New(8080, "", false, false, append(tt.opts, WithQuiet(true))...)
+			req := httptest.NewRequest("POST", "/api/data", strings.NewReader(tt.body))
+			if tt.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			rr := httptest.NewRecorder()
+			srv.handleRequest(rr, req)
+
+			if rr.Code != http.StatusOK {
+				t.Fatalf("Handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+			}
+			var response struct {
+				Message string    `json:"message"`
+				Meta    *bodyMeta `json:"meta"`
+			}
+			if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Handler returned invalid JSON: %v\nGot: %s", err, rr.Body.String())
+			}
+			if !reflect.DeepEqual(response.Meta, tt.expectMeta) {
+				t.Errorf("Handler returned meta %+v, want %+v\nGot: %s", response.Meta, tt.expectMeta, rr.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package server
+
+import "net/http"
+
+// Middleware wraps the server's handler, for example to add authentication
+// or logging when embedding the server in a larger service.
+type Middleware func(http.Handler) http.Handler
+
+// Use registers middleware around every route. The first middleware
+// registered is the outermost, seeing requests first. It must be called
+// before Start.
+func (s *Server) Use(mw ...Middleware) {
+	s.middleware = append(s.middleware, mw...)
+}
+
+// wrap applies the registered middleware to a handler.
+func (s *Server) wrap(handler http.Handler) http.Handler {
+	for i := len(s.middleware) - 1; i >= 0; i-- {
+		handler = s.middleware[i](handler)
+	}
+	return handler
+}
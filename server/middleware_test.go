@@ -0,0 +1,63 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestServer_Use(t *testing.T) {
+	var order []string
+	trace := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				order = append(order, name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+	auth := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer secret" {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	srv := New(8080, "", false, false, WithQuiet(true), WithMiddleware(trace("first")))
+	srv.Use(trace("second"), auth)
+	handler := srv.httpServer().Handler
+
+	tests := []struct {
+		name          string
+		path          string
+		authorization string
+		expectCode    int
+	}{
+		{name: "Rejected by middleware", path: "/api/data", expectCode: http.StatusUnauthorized},
+		{name: "Allowed by middleware", path: "/api/data", authorization: "Bearer secret", expectCode: http.StatusOK},
+		{name: "Applied to every route", path: "/version", expectCode: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order = nil
+			req := httptest.NewRequest("GET", tt.path, nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectCode {
+				t.Errorf("handler returned wrong status code: got %v want %v", rr.Code, tt.expectCode)
+			}
+			if got := strings.Join(order, ","); got != "first,second" {
+				t.Errorf("middleware ran in order %q want %q", got, "first,second")
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package server
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// toPascalCase converts a JSON key such as "created_at" or "user-id" into a
+// PascalCase identifier. It returns an empty string when the key has no
+// letters or digits.
+func toPascalCase(key string) string {
+	var b strings.Builder
+	upperNext := true
+	for _, r := range transliterate(key) {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upperNext = true
+			continue
+		}
+		if upperNext {
+			r = unicode.ToUpper(r)
+			upperNext = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// toCamelCase converts a JSON key into a camelCase identifier.
+func toCamelCase(key string) string {
+	name := []rune(toPascalCase(key))
+	for i := 0; i < len(name) && unicode.IsUpper(name[i]); i++ {
+		// Lower a leading acronym as a whole, keeping the start of the next word
+		if i > 0 && i+1 < len(name) && unicode.IsLower(name[i+1]) {
+			break
+		}
+		name[i] = unicode.ToLower(name[i])
+	}
+	return string(name)
+}
+
+// toSnakeCase converts a JSON key or type name into a snake_case identifier,
+// splitting words at separators and at changes of case. Acronyms stay whole.
+func toSnakeCase(key string) string {
+	name := []rune(toPascalCase(key))
+	var b strings.Builder
+	for i, r := range name {
+		if i > 0 && unicode.IsUpper(r) {
+			prevLower := !unicode.IsUpper(name[i-1])
+			nextLower := i+1 < len(name) && unicode.IsLower(name[i+1])
+			if prevLower || nextLower {
+				b.WriteRune('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// isIdentifier reports whether name is an ASCII identifier: letters, digits
+// and underscores, not starting with a digit.
+func isIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case i > 0 && r >= '0' && r <= '9':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// sanitizeIdentifier turns a JSON key into an ASCII identifier. Accents are
+// stripped, other ASCII characters become underscores and a leading digit is
+// prefixed. Characters without an ASCII form, such as CJK or emoji, are
+// dropped and a hash of the key appended, so distinct keys keep distinct
+// names; so are keys left without a letter or digit.
+func sanitizeIdentifier(key string) string {
+	var b strings.Builder
+	lossy, named := false, false
+	for _, r := range transliterate(key) {
+		switch {
+		case r == '_':
+			b.WriteRune(r)
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+			b.WriteRune(r)
+			named = true
+		case r >= '0' && r <= '9':
+			if b.Len() == 0 {
+				b.WriteRune('_')
+			}
+			b.WriteRune(r)
+			named = true
+		case r > unicode.MaxASCII:
+			lossy = true
+		default:
+			b.WriteRune('_')
+		}
+	}
+	if lossy || !named {
+		h := fnv.New32a()
+		h.Write([]byte(key))
+		fmt.Fprintf(&b, "_%08x", h.Sum32())
+	}
+	return b.String()
+}
+
+// goKeywords lists the Go keywords, which cannot name a struct field.
+var goKeywords = map[string]bool{
+	"break": true, "case": true, "chan": true, "const": true, "continue": true,
+	"default": true, "defer": true, "else": true, "fallthrough": true,
+	"for": true, "func": true, "go": true, "goto": true, "if": true,
+	"import": true, "interface": true, "map": true, "package": true,
+	"range": true, "return": true, "select": true, "struct": true,
+	"switch": true, "type": true, "var": true,
+}
+
+// rustKeywords lists the strict and reserved Rust keywords. Some, such as
+// self and crate, cannot be raw identifiers either.
+var rustKeywords = map[string]bool{
+	"as": true, "async": true, "await": true, "break": true, "const": true,
+	"continue": true, "crate": true, "dyn": true, "else": true, "enum": true,
+	"extern": true, "false": true, "fn": true, "for": true, "gen": true,
+	"if": true, "impl": true, "in": true, "let": true, "loop": true,
+	"match": true, "mod": true, "move": true, "mut": true, "pub": true,
+	"ref": true, "return": true, "self": true, "Self": true, "static": true,
+	"struct": true, "super": true, "trait": true, "true": true, "type": true,
+	"unsafe": true, "use": true, "where": true, "while": true,
+	"abstract": true, "become": true, "box": true, "do": true, "final": true,
+	"macro": true, "override": true, "priv": true, "try": true,
+	"typeof": true, "unsized": true, "virtual": true, "yield": true,
+}
+
+// rustBuiltinTypes lists the prelude types that generated fields use, which a
+// struct of the same name would shadow, and Self, which is a keyword.
+var rustBuiltinTypes = map[string]bool{
+	"Box": true, "Option": true, "Result": true, "Self": true, "String": true,
+	"Vec": true,
+}
+
+// goFieldName sanitizes a JSON key into a Go field name, appending an
+// underscore to keywords.
+func goFieldName(key string) string {
+	name := sanitizeIdentifier(key)
+	if goKeywords[name] {
+		name += "_"
+	}
+	return name
+}
+
+// rustFieldName sanitizes a JSON key into a Rust field name, appending an
+// underscore to keywords. The key differs from the name, so the field gets a
+// serde rename.
+func rustFieldName(key string) string {
+	name := sanitizeIdentifier(key)
+	if rustKeywords[name] {
+		name += "_"
+	}
+	return name
+}
+
+// asciiLetters spells the letters that have no decomposition into an ASCII
+// letter and accents.
+var asciiLetters = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'Æ': "AE", 'œ': "oe", 'Œ': "OE", 'ø': "o", 'Ø': "O",
+	'ł': "l", 'Ł': "L", 'đ': "d", 'Đ': "D", 'þ': "th", 'Þ': "Th", 'ð': "d",
+	'Ð': "D", 'ı': "i",
+}
+
+// transliterate strips the accents from the letters of a key, so that
+// "prénom" becomes "prenom".
+func transliterate(key string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(key) {
+		if spelled, ok := asciiLetters[r]; ok {
+			b.WriteString(spelled)
+		} else if !unicode.Is(unicode.Mn, r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// uniqueName returns name, or name with the first free numeric suffix when
+// already used, and marks the result used.
+func uniqueName(name string, used map[string]bool) string {
+	base := name
+	for n := 2; used[name]; n++ {
+		name = fmt.Sprintf("%s%d", base, n)
+	}
+	used[name] = true
+	return name
+}
+
+// keyNames names each field with name, in order. Keys that name keeps as they
+// are come first, as formats matching fields by name need the data's own
+// names, and sanitized keys take numeric suffixes around them.
+func keyNames(fields []*field, name func(string) string) []string {
+	names := make([]string, len(fields))
+	used := make(map[string]bool, len(fields))
+	for i, f := range fields {
+		if name(f.name) == f.name {
+			names[i] = f.name
+			used[f.name] = true
+		}
+	}
+	for i, f := range fields {
+		if names[i] == "" {
+			names[i] = uniqueName(name(f.name), used)
+		}
+	}
+	return names
+}
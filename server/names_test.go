@@ -0,0 +1,177 @@
+package server
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNameConversions(t *testing.T) {
+	tests := []struct {
+		input        string
+		expectPascal string
+		expectCamel  string
+		expectSnake  string
+	}{
+		{input: "name", expectPascal: "Name", expectCamel: "name", expectSnake: "name"},
+		{input: "created_at", expectPascal: "CreatedAt", expectCamel: "createdAt", expectSnake: "created_at"},
+		{input: "user-id", expectPascal: "UserId", expectCamel: "userId", expectSnake: "user_id"},
+		{input: "URLPath", expectPascal: "URLPath", expectCamel: "urlPath", expectSnake: "url_path"},
+		{input: "ID", expectPascal: "ID", expectCamel: "id", expectSnake: "id"},
+		{input: "GeneratedStruct2", expectPascal: "GeneratedStruct2", expectCamel: "generatedStruct2", expectSnake: "generated_struct2"},
+		{input: "--", expectPascal: "", expectCamel: "", expectSnake: ""},
+		{input: "prénom", expectPascal: "Prenom", expectCamel: "prenom", expectSnake: "prenom"},
+		{input: "straße_nr", expectPascal: "StrasseNr", expectCamel: "strasseNr", expectSnake: "strasse_nr"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := toPascalCase(tt.input); got != tt.expectPascal {
+				t.Errorf("toPascalCase(%q) = %q, want %q", tt.input, got, tt.expectPascal)
+			}
+			if got := toCamelCase(tt.input); got != tt.expectCamel {
+				t.Errorf("toCamelCase(%q) = %q, want %q", tt.input, got, tt.expectCamel)
+			}
+			if got := toSnakeCase(tt.input); got != tt.expectSnake {
+				t.Errorf("toSnakeCase(%q) = %q, want %q", tt.input, got, tt.expectSnake)
+			}
+		})
+	}
+}
+
+func TestSanitizeIdentifier(t *testing.T) {
+	tests := []struct {
+		input  string
+		expect string
+	}{
+		{input: "name", expect: "name"},
+		{input: "user-name", expect: "user_name"},
+		{input: "2fa", expect: "_2fa"},
+		{input: "prénom", expect: "prenom"},
+		{input: "Ærø", expect: "AEro"},
+		{input: "名前", expect: "_0073e150"},
+		{input: "名字", expect: "_aa1c40b2"},
+		{input: "emoji😀", expect: "emoji_b18953e0"},
+		{input: "", expect: "_811c9dc5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := sanitizeIdentifier(tt.input)
+			if got != tt.expect {
+				t.Errorf("sanitizeIdentifier(%q) = %q, want %q", tt.input, got, tt.expect)
+			}
+			if !isIdentifier(got) {
+				t.Errorf("sanitizeIdentifier(%q) = %q, which is not an identifier", tt.input, got)
+			}
+		})
+	}
+}
+
+func TestFieldNames_Keywords(t *testing.T) {
+	tests := []struct {
+		input      string
+		expectGo   string
+		expectRust string
+	}{
+		{input: "type", expectGo: "type_", expectRust: "type_"},
+		{input: "func", expectGo: "func_", expectRust: "func"},
+		{input: "match", expectGo: "match", expectRust: "match_"},
+		{input: "self", expectGo: "self", expectRust: "self_"},
+		{input: "name", expectGo: "name", expectRust: "name"},
+		{input: "user-type", expectGo: "user_type", expectRust: "user_type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := goFieldName(tt.input); got != tt.expectGo {
+				t.Errorf("goFieldName(%q) = %q, want %q", tt.input, got, tt.expectGo)
+			}
+			if got := rustFieldName(tt.input); got != tt.expectRust {
+				t.Errorf("rustFieldName(%q) = %q, want %q", tt.input, got, tt.expectRust)
+			}
+		})
+	}
+
+	goSrv := New(8080, "go", false, false)
+	result, err := goSrv.formatData(map[string]interface{}{"type": "x", "func": "y"})
+	if err != nil {
+		t.Fatalf("formatData() error = %v", err)
+	}
+	for _, expect := range []string{"type_ string `json:\"type\"`", "func_ string `json:\"func\"`"} {
+		if !strings.Contains(result, expect) {
+			t.Errorf("formatData() result does not contain expected string: %s\nGot: %s", expect, result)
+		}
+	}
+
+	rustSrv := New(8080, "rust", false, false)
+	result, err = rustSrv.formatData(map[string]interface{}{"type": "x", "match": "y"})
+	if err != nil {
+		t.Fatalf("formatData() error = %v", err)
+	}
+	for _, expect := range []string{"    #[serde(rename = \"type\")]\n    type_: String,", "    #[serde(rename = \"match\")]\n    match_: String,"} {
+		if !strings.Contains(result, expect) {
+			t.Errorf("formatData() result does not contain expected string: %s\nGot: %s", expect, result)
+		}
+	}
+}
+
+func TestKeyNames(t *testing.T) {
+	fields := []*field{{name: "a b"}, {name: "a-b"}, {name: "a_b"}, {name: "c"}}
+	got := keyNames(fields, sanitizeIdentifier)
+	expect := []string{"a_b2", "a_b3", "a_b", "c"}
+	if strings.Join(got, ",") != strings.Join(expect, ",") {
+		t.Errorf("keyNames() = %v, want %v", got, expect)
+	}
+}
+
+func TestFormatData_UnicodeKeys(t *testing.T) {
+	data := map[string]interface{}{
+		"e":      1.0,
+		"é":      2.0,
+		"prénom": "Zoé",
+		"名前":     "太郎",
+		"名字":     "山田",
+	}
+
+	tests := []struct {
+		formatType     string
+		expectContains []string
+	}{
+		{
+			formatType: "go",
+			expectContains: []string{
+				"    e float64 `json:\"e\"`\n",
+				"    e2 float64 `json:\"é\"`\n",
+				"    prenom string `json:\"prénom\"`\n",
+				"    _0073e150 string `json:\"名前\"`\n",
+				"    _aa1c40b2 string `json:\"名字\"`\n",
+			},
+		},
+		{
+			formatType: "rust",
+			expectContains: []string{
+				"    e: f64,\n",
+				"    #[serde(rename = \"é\")]\n    e2: f64,\n",
+				"    #[serde(rename = \"prénom\")]\n    prenom: String,\n",
+				"    #[serde(rename = \"名前\")]\n    _0073e150: String,\n",
+				"    #[serde(rename = \"名字\")]\n    _aa1c40b2: String,\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.formatType, func(t *testing.T) {
+			srv := New(8080, tt.formatType, false, false)
+			result, err := srv.formatData(data)
+			if err != nil {
+				t.Fatalf("formatData() error = %v", err)
+			}
+
+			for _, expect := range tt.expectContains {
+				if !strings.Contains(result, expect) {
+					t.Errorf("formatData() result does not contain expected string: %s\nGot: %s", expect, result)
+				}
+			}
+		})
+	}
+}
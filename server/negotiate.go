@@ -0,0 +1,56 @@
+package server
+
+import (
+	"mime"
+	"strconv"
+	"strings"
+)
+
+// negotiatedFormats maps the media types a client may Accept to the format
+// generated into the echo response in place of the JSON acknowledgement.
+var negotiatedFormats = map[string]string{
+	"text/x-go":                   "go",
+	"text/x-rust":                 "rust",
+	"text/x-python":               "typeddict",
+	"text/x-kotlin":               "kotlin",
+	"application/vnd.oai.openapi": "openapi",
+	"application/schema+json":     "schema-json",
+}
+
+// negotiateFormat picks the response format from an Accept header, honoring
+// quality values and preferring earlier entries on ties. It returns empty
+// strings when the client prefers JSON, or accepts none of the generated
+// media types.
+func negotiateFormat(accept string) (mediaType, format string) {
+	bestQuality := 0.0
+	for _, part := range strings.Split(accept, ",") {
+		candidate, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+		if quality <= bestQuality {
+			continue
+		}
+
+		if f, ok := negotiatedFormats[candidate]; ok {
+			mediaType, format, bestQuality = candidate, f, quality
+		} else if candidate == "application/json" || candidate == "application/*" || candidate == "*/*" {
+			mediaType, format, bestQuality = "", "", quality
+		}
+	}
+	return mediaType, format
+}
+
+// negotiatedContentType is the Content-Type of a negotiated response.
+func negotiatedContentType(mediaType string) string {
+	if strings.HasPrefix(mediaType, "text/") {
+		return mediaType + "; charset=utf-8"
+	}
+	return mediaType
+}
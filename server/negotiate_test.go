@@ -0,0 +1,153 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNegotiateFormat(t *testing.T) {
+	tests := []struct {
+		accept          string
+		expectMediaType string
+		expectFormat    string
+	}{
+		{accept: "", expectMediaType: "", expectFormat: ""},
+		{accept: "application/json", expectMediaType: "", expectFormat: ""},
+		{accept: "*/*", expectMediaType: "", expectFormat: ""},
+		{accept: "text/x-go", expectMediaType: "text/x-go", expectFormat: "go"},
+		{accept: "text/x-rust; charset=utf-8", expectMediaType: "text/x-rust", expectFormat: "rust"},
+		{accept: "application/schema+json", expectMediaType: "application/schema+json", expectFormat: "schema-json"},
+		{accept: "text/html, text/x-python", expectMediaType: "text/x-python", expectFormat: "typeddict"},
+		{accept: "text/x-go, text/x-rust", expectMediaType: "text/x-go", expectFormat: "go"},
+		{accept: "text/x-go;q=0.5, text/x-rust", expectMediaType: "text/x-rust", expectFormat: "rust"},
+		{accept: "application/json, text/x-go;q=0.9", expectMediaType: "", expectFormat: ""},
+		{accept: "application/json;q=0.1, text/x-kotlin", expectMediaType: "text/x-kotlin", expectFormat: "kotlin"},
+		{accept: "text/x-go;q=0", expectMediaType: "", expectFormat: ""},
+		{accept: "text/x-go;q=high, text/html", expectMediaType: "", expectFormat: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			mediaType, format := negotiateFormat(tt.accept)
+			if mediaType != tt.expectMediaType || format != tt.expectFormat {
+				t.Errorf("negotiateFormat(%q) = %q, %q, want %q, %q", tt.accept, mediaType, format, tt.expectMediaType, tt.expectFormat)
+			}
+		})
+	}
+}
+
+func TestServer_HandleRequestAccept(t *testing.T) {
+	tests := []struct {
+		name              string
+		formatType        string
+		accept            string
+		rawBody           string
+		contentType       string
+		options           []Option
+		expectStatus      int
+		expectContentType string
+		expectContains    []string
+	}{
+		{
+			name:              "Go source",
+			accept:            "text/x-go",
+			rawBody:           `{"name":"test"}`,
+			expectContentType: "text/x-go; charset=utf-8",
+			expectContains:    []string{"type GeneratedStruct struct {\n    name string `json:\"name\"`\n}\n"},
+		},
+		{
+			name:              "Overrides the configured format",
+			formatType:        "go",
+			accept:            "text/x-rust",
+			rawBody:           `{"name":"test"}`,
+			expectContentType: "text/x-rust; charset=utf-8",
+			expectContains:    []string{"struct GeneratedStruct {\n    name: String,\n}"},
+		},
+		{
+			name:              "Schema JSON",
+			accept:            "application/schema+json",
+			rawBody:           `{"name":"test"}`,
+			expectContentType: "application/schema+json",
+			expectContains:    []string{`"kind": "object"`},
+		},
+		{
+			name:              "JSON acknowledgement",
+			formatType:        "go",
+			accept:            "application/json",
+			rawBody:           `{"name":"test"}`,
+			expectContentType: "application/json",
+			expectContains:    []string{`"message": "Request processed successfully"`},
+		},
+		{
+			name:              "Refused without a body",
+			accept:            "text/x-go",
+			expectStatus:      http.StatusNotAcceptable,
+			expectContentType: "application/json",
+			expectContains:    []string{`{"error":"No JSON body to generate code from","detail":"Accept asks for text/x-go","code":"not_acceptable"}`},
+		},
+		{
+			name:              "Refused with an empty JSON body",
+			formatType:        "go",
+			accept:            "text/x-rust",
+			contentType:       "application/json",
+			expectStatus:      http.StatusNotAcceptable,
+			expectContentType: "application/json",
+			expectContains:    []string{`"code":"not_acceptable"`},
+		},
+		{
+			name:              "Refused with a non-JSON body",
+			accept:            "text/x-go",
+			rawBody:           "name=test",
+			contentType:       "application/x-www-form-urlencoded",
+			expectStatus:      http.StatusNotAcceptable,
+			expectContentType: "application/json",
+			expectContains:    []string{`"code":"not_acceptable"`},
+		},
+		{
+			name:              "Acknowledgement when validating",
+			accept:            "text/x-go",
+			rawBody:           `{"name":"test"}`,
+			options:           []Option{WithValidate(true)},
+			expectContentType: "application/json",
+			expectContains:    []string{`"message": "Request processed successfully"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := New(8080, tt.formatType, false, false, append([]Option{WithQuiet(true)}, tt.options...)...)
+			req := httptest.NewRequest("POST", "/api/data", strings.NewReader(tt.rawBody))
+			contentType := tt.contentType
+			if contentType == "" && tt.rawBody != "" {
+				contentType = "application/json"
+			}
+			if contentType != "" {
+				req.Header.Set("Content-Type", contentType)
+			}
+			req.Header.Set("Accept", tt.accept)
+			rr := httptest.NewRecorder()
+			srv.handleRequest(rr, req)
+
+			expectStatus := tt.expectStatus
+			if expectStatus == 0 {
+				expectStatus = http.StatusOK
+			}
+			if rr.Code != expectStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v", rr.Code, expectStatus)
+			}
+			if contentType := rr.Header().Get("Content-Type"); contentType != tt.expectContentType {
+				t.Errorf("Content-Type = %s, want %s", contentType, tt.expectContentType)
+			}
+			if vary := rr.Header().Get("Vary"); vary != "Accept" {
+				t.Errorf("Vary = %s, want Accept", vary)
+			}
+			for _, expect := range tt.expectContains {
+				if !strings.Contains(rr.Body.String(), expect) {
+					t.Errorf("Response body does not contain expected string: %s\nGot: %s", expect, rr.Body.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+)
+
+// objcReserved lists names that cannot be used as properties in addition to
+// the C keywords, either because they are Objective-C keywords or because
+// they would override NSObject methods.
+var objcReserved = map[string]bool{
+	"BOOL": true, "Class": true, "NO": true, "SEL": true, "YES": true,
+	"class": true, "copy": true, "debugDescription": true, "description": true,
+	"hash": true, "id": true, "init": true, "nil": true, "self": true,
+	"super": true, "superclass": true,
+}
+
+func (s *Server) formatAsObjC(sch *schema) (string, error) {
+	root := sch
+	if sch.kind != kindObject {
+		root = &schema{kind: kindObject, fields: []*field{{name: "data", schema: sch}}}
+	}
+
+	types := s.nestedObjects(s.structName, root)
+	names := make([]string, 0, len(types.objects))
+	interfaces := make([]string, 0, len(types.objects))
+	for _, obj := range types.objects {
+		names = append(names, obj.name)
+		interfaces = append(interfaces, s.generateObjCInterface(obj, types))
+	}
+
+	var b strings.Builder
+	b.WriteString("#import <Foundation/Foundation.h>\n\nNS_ASSUME_NONNULL_BEGIN\n\n")
+	// Forward declarations let interfaces refer to each other in any order
+	if len(names) > 1 {
+		fmt.Fprintf(&b, "@class %s;\n\n", strings.Join(names, ", "))
+	}
+	b.WriteString(strings.Join(interfaces, "\n\n"))
+	b.WriteString("\n\nNS_ASSUME_NONNULL_END")
+	return b.String(), nil
+}
+
+func (s *Server) generateObjCInterface(obj *objectType, types *objectTypes) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "@interface %s : NSObject\n", obj.name)
+
+	used := make(map[string]bool)
+	for _, f := range obj.schema.fields {
+		name := objcName(f.name)
+		base := name
+		for n := 2; used[name]; n++ {
+			name = fmt.Sprintf("%s%d", base, n)
+		}
+		used[name] = true
+
+		nullable := f.optional || f.schema.nullable || f.schema.kind == kindNull
+		fieldType := s.getObjCType(f.schema, types, nullable)
+		attrs := "nonatomic, " + objcOwnership(fieldType)
+		if nullable {
+			attrs += ", nullable"
+		}
+		var comment string
+		if name != f.name {
+			comment = fmt.Sprintf(" // JSON key: %s", f.name)
+		}
+		fmt.Fprintf(&b, "@property (%s) %s;%s\n", attrs, cDeclaration(fieldType, name), comment)
+	}
+	b.WriteString("@end")
+	return b.String()
+}
+
+// getObjCType maps a schema to an Objective-C type. Scalars are boxed in
+// NSNumber when they may be nil or sit inside a collection.
+func (s *Server) getObjCType(sch *schema, types *objectTypes, boxed bool) string {
+	boxed = boxed || sch.nullable
+	switch sch.kind {
+	case kindBool:
+		if boxed {
+			return "NSNumber *"
+		}
+		return "BOOL"
+	case kindNumber:
+		if boxed {
+			return "NSNumber *"
+		}
+		return "double"
+	case kindString:
+		return "NSString *"
+	case kindArray:
+		if sch.elem == nil {
+			return "NSArray *"
+		}
+		return fmt.Sprintf("NSArray<%s> *", s.getObjCType(sch.elem, types, true))
+	case kindObject:
+		return types.name(sch) + " *"
+	default:
+		return "id"
+	}
+}
+
+// objcOwnership picks the property attribute managing a value's memory.
+// Strings and arrays are copied so mutable instances cannot change under the
+// object.
+func objcOwnership(objcType string) string {
+	switch {
+	case objcType == "BOOL", objcType == "double":
+		return "assign"
+	case objcType == "NSString *", strings.HasPrefix(objcType, "NSArray"):
+		return "copy"
+	default:
+		return "strong"
+	}
+}
+
+// objcName converts a JSON key into a camelCase property name, avoiding
+// reserved words.
+func objcName(key string) string {
+	name := sanitizeIdentifier(toCamelCase(key))
+	if name == "_" {
+		return "field"
+	}
+	if cKeywords[name] || objcReserved[name] {
+		name += "Value"
+	}
+	return name
+}
@@ -0,0 +1,63 @@
+package server
+
+import "testing"
+
+func TestFormatAsObjC(t *testing.T) {
+	testFormatCases(t, "objc", []formatCase{
+		{
+			name: "Flat object",
+			data: map[string]interface{}{"name": "test", "value": 123.0, "active": true},
+			expectContains: []string{
+				"#import <Foundation/Foundation.h>\n\nNS_ASSUME_NONNULL_BEGIN\n\n@interface GeneratedStruct : NSObject\n",
+				"@property (nonatomic, assign) BOOL active;\n",
+				"@property (nonatomic, copy) NSString *name;\n",
+				"@property (nonatomic, assign) double value;\n",
+				"@end\n\nNS_ASSUME_NONNULL_END",
+			},
+		},
+		{
+			name: "Nested objects, arrays and renamed keys",
+			data: map[string]interface{}{
+				"user_info":   map[string]interface{}{"id": 1.0},
+				"tags":        []interface{}{"a", "b"},
+				"scores":      []interface{}{1.0, 2.0},
+				"description": "text",
+			},
+			expectContains: []string{
+				"@class GeneratedStruct, UserInfo;\n",
+				"@property (nonatomic, copy) NSString *descriptionValue; // JSON key: description\n",
+				"@property (nonatomic, copy) NSArray<NSNumber *> *scores;\n",
+				"@property (nonatomic, copy) NSArray<NSString *> *tags;\n",
+				"@property (nonatomic, strong) UserInfo *userInfo; // JSON key: user_info\n",
+				"@interface UserInfo : NSObject\n@property (nonatomic, assign) double idValue; // JSON key: id\n@end",
+			},
+		},
+		{
+			name: "Optional and null fields",
+			records: []interface{}{
+				map[string]interface{}{"count": 1.0, "email": nil, "note": nil},
+				map[string]interface{}{"email": "x@example.com"},
+			},
+			expectContains: []string{
+				"@property (nonatomic, strong, nullable) NSNumber *count;\n",
+				"@property (nonatomic, copy, nullable) NSString *email;\n",
+				"@property (nonatomic, strong, nullable) id note;\n",
+			},
+		},
+		{
+			name: "Reserved words and invalid identifiers",
+			data: map[string]interface{}{"class": 1.0, "id": "i", "self": "s", "2fa": "x"},
+			expectContains: []string{
+				"@property (nonatomic, copy) NSString *_2fa; // JSON key: 2fa\n",
+				"@property (nonatomic, assign) double classValue; // JSON key: class\n",
+				"@property (nonatomic, copy) NSString *idValue; // JSON key: id\n",
+				"@property (nonatomic, copy) NSString *selfValue; // JSON key: self\n",
+			},
+		},
+		{
+			name:           "Non-object root",
+			data:           []interface{}{true},
+			expectContains: []string{"@property (nonatomic, copy) NSArray<NSNumber *> *data;\n"},
+		},
+	})
+}
@@ -0,0 +1,198 @@
+package server
+
+import "fmt"
+
+// objectType is an object schema that a formatter emits as its own named type.
+type objectType struct {
+	name   string
+	schema *schema
+}
+
+// objectTypes lists the named types needed to describe a root schema.
+type objectTypes struct {
+	// objects is ordered parent first, starting with the root.
+	objects []*objectType
+	names   map[*schema]string
+}
+
+// Nested naming schemes, selecting how nested types are named.
+const (
+	// nestedNamingKey names a nested type after the key it appears under.
+	nestedNamingKey = "key"
+	// nestedNamingPath prefixes the key with the name of the parent type.
+	nestedNamingPath = "path"
+)
+
+// nestedObjects names the root object schema and every object nested in it,
+// including objects inside arrays. Nested types are named after the key they
+// appear under, prefixed with the parent type name in path naming. Objects
+// with the same name and shape share one type; otherwise numeric suffixes
+// keep names unique, also avoiding the server's reserved names and the
+// format's built-in types.
+func (s *Server) nestedObjects(rootName string, root *schema) *objectTypes {
+	types := &objectTypes{names: make(map[*schema]string)}
+	taken := s.takenNames()
+	byBase := make(map[string][]*objectType)
+
+	var visit func(name string, sch *schema)
+	visit = func(name string, sch *schema) {
+		switch sch.kind {
+		case kindObject:
+			base := name
+			for _, obj := range byBase[base] {
+				if sameShape(obj.schema, sch) {
+					types.alias(sch, obj.schema)
+					return
+				}
+			}
+			for n := 2; taken[s.typeKey(name)]; n++ {
+				name = fmt.Sprintf("%s%d", base, n)
+			}
+			taken[s.typeKey(name)] = true
+			obj := &objectType{name: name, schema: sch}
+			byBase[base] = append(byBase[base], obj)
+			types.objects = append(types.objects, obj)
+			types.names[sch] = name
+			for _, f := range sch.fields {
+				childName := typeNameFor(f.name)
+				if s.nestedNaming == nestedNamingPath {
+					childName = name + childName
+				}
+				visit(childName, f.schema)
+			}
+		case kindArray:
+			if sch.elem != nil {
+				visit(name, sch.elem)
+			}
+		}
+	}
+	visit(rootName, root)
+	return types
+}
+
+// takenNames returns the type names no generated type may use, by typeKey:
+// the server's reserved names and the built-in types of the output format.
+func (s *Server) takenNames() map[string]bool {
+	taken := make(map[string]bool)
+	for name := range s.reservedNames {
+		taken[s.typeKey(name)] = true
+	}
+	for name := range s.builtinTypeNames() {
+		taken[name] = true
+	}
+	return taken
+}
+
+// typeKey returns the identifier a type name is declared as in the output
+// format, which must be unique: the name itself, except in OCaml, which
+// lowercases type names.
+func (s *Server) typeKey(name string) string {
+	if s.formatType == "ocaml" {
+		return ocamlName(name)
+	}
+	return name
+}
+
+// builtinTypeNames returns the built-in types of the output format that a
+// generated type of the same name would shadow, spelled as typeKey returns
+// type names.
+func (s *Server) builtinTypeNames() map[string]bool {
+	switch s.formatType {
+	case "rust":
+		return rustBuiltinTypes
+	case "elm":
+		return elmBuiltinTypes
+	case "typeddict":
+		return typedDictBuiltinTypes
+	case "scala":
+		return scalaBuiltinTypes
+	case "kotlin":
+		return kotlinBuiltinTypes
+	case "dart":
+		return dartBuiltinTypes
+	case "haskell":
+		return haskellBuiltinTypes
+	case "crystal":
+		return crystalBuiltinTypes
+	case "fsharp":
+		return fsharpBuiltinTypes
+	case "ocaml":
+		return ocamlBuiltinTypes
+	case "thrift":
+		return thriftBuiltinTypes
+	case "graphql":
+		return s.graphqlReservedNames()
+	}
+	return nil
+}
+
+// alias gives a schema, and every object nested in it, the names already
+// assigned to an identically shaped schema.
+func (t *objectTypes) alias(sch, named *schema) {
+	if sch == nil {
+		return
+	}
+	if sch.kind == kindObject {
+		t.names[sch] = t.names[named]
+		for i, f := range sch.fields {
+			t.alias(f.schema, named.fields[i].schema)
+		}
+	}
+	if sch.kind == kindArray {
+		t.alias(sch.elem, named.elem)
+	}
+}
+
+// childrenFirst orders the object types so that every type comes before the
+// types using it, for languages that need definitions before use. Shared
+// types come before all of their parents.
+func childrenFirst(types *objectTypes) []*objectType {
+	byName := make(map[string]*objectType, len(types.objects))
+	for _, obj := range types.objects {
+		byName[obj.name] = obj
+	}
+
+	var ordered []*objectType
+	emitted := make(map[string]bool)
+	var visit func(sch *schema)
+	visit = func(sch *schema) {
+		switch sch.kind {
+		case kindObject:
+			name := types.name(sch)
+			if emitted[name] {
+				return
+			}
+			emitted[name] = true
+			// Visit fields last to first, matching the reverse of the parent
+			// first order when nothing is shared
+			fields := byName[name].schema.fields
+			for i := len(fields) - 1; i >= 0; i-- {
+				visit(fields[i].schema)
+			}
+			ordered = append(ordered, byName[name])
+		case kindArray:
+			if sch.elem != nil {
+				visit(sch.elem)
+			}
+		}
+	}
+	visit(types.objects[0].schema)
+	return ordered
+}
+
+// name returns the type name assigned to an object schema.
+func (t *objectTypes) name(sch *schema) string {
+	return t.names[sch]
+}
+
+// typeNameFor derives a PascalCase type name from a JSON key.
+func typeNameFor(key string) string {
+	name := toPascalCase(key)
+	if name == "" {
+		return "Object"
+	}
+	if name[0] >= '0' && name[0] <= '9' {
+		return "T" + name
+	}
+	return name
+}
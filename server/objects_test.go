@@ -0,0 +1,238 @@
+package server
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNestedObjects(t *testing.T) {
+	data := map[string]interface{}{
+		"billing":  map[string]interface{}{"address": map[string]interface{}{"city": "Paris"}},
+		"shipping": map[string]interface{}{"address": map[string]interface{}{"city": "Lyon"}},
+		"user":     map[string]interface{}{"metadata": map[string]interface{}{"id": 1}},
+		"order":    map[string]interface{}{"metadata": map[string]interface{}{"total": 2}},
+	}
+
+	tests := []struct {
+		name        string
+		naming      string
+		expectNames []string
+	}{
+		{
+			name:   "Key naming",
+			naming: nestedNamingKey,
+			// Both addresses share a type, the differing metadata objects do not
+			expectNames: []string{"GeneratedStruct", "Billing", "Address", "Order", "Metadata", "Shipping", "User", "Metadata2"},
+		},
+		{
+			name:   "Path naming",
+			naming: nestedNamingPath,
+			expectNames: []string{
+				"GeneratedStruct",
+				"GeneratedStructBilling", "GeneratedStructBillingAddress",
+				"GeneratedStructOrder", "GeneratedStructOrderMetadata",
+				"GeneratedStructShipping", "GeneratedStructShippingAddress",
+				"GeneratedStructUser", "GeneratedStructUserMetadata",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := New(8080, "go", false, false, WithNestedNaming(tt.naming))
+			types := srv.nestedObjects("GeneratedStruct", inferSchema(data))
+
+			var names []string
+			for _, obj := range types.objects {
+				names = append(names, obj.name)
+			}
+			if strings.Join(names, ",") != strings.Join(tt.expectNames, ",") {
+				t.Errorf("nestedObjects() names = %v, want %v", names, tt.expectNames)
+			}
+		})
+	}
+}
+
+func TestNestedObjectsSharedType(t *testing.T) {
+	srv := New(8080, "avro", false, false)
+	result, err := srv.formatData(map[string]interface{}{
+		"billing":  map[string]interface{}{"address": map[string]interface{}{"city": "Paris"}},
+		"shipping": map[string]interface{}{"address": map[string]interface{}{"city": "Lyon"}},
+	})
+	if err != nil {
+		t.Fatalf("formatData() error = %v", err)
+	}
+
+	// The shared record is defined once and referenced by name afterwards
+	if n := strings.Count(result, `"name": "Address"`); n != 1 {
+		t.Errorf("formatData() defined Address %d times\nGot: %s", n, result)
+	}
+	if !strings.Contains(result, `"type": "Address"`) {
+		t.Errorf("formatData() result does not reference Address by name\nGot: %s", result)
+	}
+}
+
+func TestNestedObjectsBuiltinNames(t *testing.T) {
+	tests := []struct {
+		formatType     string
+		opts           []Option
+		data           map[string]interface{}
+		expectContains []string
+		expectMissing  []string
+	}{
+		{
+			formatType: "rust",
+			data: map[string]interface{}{
+				"option": map[string]interface{}{"a": 1.0},
+				"string": map[string]interface{}{"b": "x"},
+				"vec":    map[string]interface{}{"c": []interface{}{1.0}},
+				"name":   "n",
+			},
+			expectContains: []string{"struct Option2 {", "struct String2 {", "struct Vec2 {", "name: String,", "string: String2,", "c: Vec<f64>,"},
+			expectMissing:  []string{"struct Option {", "struct String {", "struct Vec {"},
+		},
+		{
+			formatType: "graphql",
+			opts:       []Option{WithGraphQLScalars(map[string]string{"date": "DateTime"})},
+			data: map[string]interface{}{
+				"string":    map[string]interface{}{"v": 1.0},
+				"JSON":      map[string]interface{}{"v": 2.0},
+				"date_time": map[string]interface{}{"v": 3.0},
+				"name":      "n",
+			},
+			expectContains: []string{"type String2 {", "type JSON2 {", "type DateTime2 {", "name: String!", "string: String2!"},
+			expectMissing:  []string{"type String {", "type JSON {", "type DateTime {"},
+		},
+		{
+			formatType: "elm",
+			data: map[string]interface{}{
+				"list":   map[string]interface{}{"a": 1.0},
+				"int":    map[string]interface{}{"b": 2.0},
+				"string": map[string]interface{}{"c": "x"},
+				"name":   "n",
+			},
+			expectContains: []string{"type alias List2 =", "type alias Int2 =", "type alias String2 =", "name : String", "string : String2"},
+			expectMissing:  []string{"type alias List =", "type alias Int =", "type alias String ="},
+		},
+		{
+			formatType: "typeddict",
+			data: map[string]interface{}{
+				"list":     map[string]interface{}{"a": 1.0},
+				"optional": map[string]interface{}{"b": 2.0},
+				"any":      map[string]interface{}{"c": "x"},
+				"tags":     []interface{}{"t"},
+			},
+			expectContains: []string{"class List2(TypedDict):", "class Optional2(TypedDict):", "class Any2(TypedDict):", "tags: List[str]", "list: List2"},
+			expectMissing:  []string{"class List(TypedDict):", "class Optional(TypedDict):", "class Any(TypedDict):"},
+		},
+		{
+			formatType: "scala",
+			data: map[string]interface{}{
+				"list":   map[string]interface{}{"a": 1.0},
+				"string": map[string]interface{}{"b": "x"},
+				"int":    map[string]interface{}{"c": 2.0},
+				"tags":   []interface{}{"t"},
+			},
+			expectContains: []string{"case class List2(", "case class String2(", "case class Int2(", "tags: List[String]", "string: String2"},
+			expectMissing:  []string{"case class List(", "case class String(", "case class Int("},
+		},
+		{
+			formatType: "kotlin",
+			data: map[string]interface{}{
+				"list":         map[string]interface{}{"a": 1.0},
+				"string":       map[string]interface{}{"b": "x"},
+				"serializable": map[string]interface{}{"c": 2.0},
+				"tags":         []interface{}{"t"},
+			},
+			expectContains: []string{"data class List2(", "data class String2(", "data class Serializable2(", "val tags: List<String>,", "val string: String2,"},
+			expectMissing:  []string{"data class List(", "data class String(", "data class Serializable("},
+		},
+		{
+			formatType: "dart",
+			data: map[string]interface{}{
+				"list":   map[string]interface{}{"a": 1.0},
+				"string": map[string]interface{}{"b": "x"},
+				"map":    map[string]interface{}{"c": 2.0},
+				"name":   "n",
+			},
+			expectContains: []string{"class List2 {", "class String2 {", "class Map2 {", "final String name;", "final String2 string;", "factory List2.fromJson(Map<String, dynamic> json)"},
+			expectMissing:  []string{"class List {", "class String {", "class Map {"},
+		},
+		{
+			formatType: "haskell",
+			data: map[string]interface{}{
+				"string": map[string]interface{}{"a": 1.0},
+				"int":    map[string]interface{}{"b": 2.0},
+				"text":   map[string]interface{}{"c": "x"},
+				"name":   "n",
+			},
+			expectContains: []string{"data String2 = String2", "data Int2 = Int2", "data Text2 = Text2", ":: Text", ":: String2"},
+			expectMissing:  []string{"data String =", "data Int =", "data Text ="},
+		},
+		{
+			formatType: "crystal",
+			data: map[string]interface{}{
+				"string": map[string]interface{}{"a": 1.0},
+				"int32":  map[string]interface{}{"b": 2.0},
+				"array":  map[string]interface{}{"c": "x"},
+				"tags":   []interface{}{"t"},
+			},
+			expectContains: []string{"struct String2\n", "struct Int322\n", "struct Array2\n", "property tags : Array(String)", "property string : String2"},
+			expectMissing:  []string{"struct String\n", "struct Int32\n", "struct Array\n"},
+		},
+		{
+			formatType: "fsharp",
+			data: map[string]interface{}{
+				"string":       map[string]interface{}{"a": 1.0},
+				"list":         map[string]interface{}{"b": 2.0},
+				"json_element": map[string]interface{}{"c": "x"},
+				"mixed":        []interface{}{1.0, "a"},
+			},
+			expectContains: []string{"type String2 =", "type List2 =", "type JsonElement2 =", "Mixed: JsonElement list", "String: String2"},
+			expectMissing:  []string{"type String =", "type List =", "type JsonElement ="},
+		},
+		{
+			formatType: "ocaml",
+			data: map[string]interface{}{
+				"string": map[string]interface{}{"a": 1.0},
+				"INT":    map[string]interface{}{"b": 2.0},
+				"list":   map[string]interface{}{"c": "x"},
+				"tags":   []interface{}{"t"},
+			},
+			expectContains: []string{"type string2 = {", "type int2 = {", "type list2 = {", "tags : string list;", "string : string2;"},
+			expectMissing:  []string{"type string = {", "type int = {", "type list = {"},
+		},
+		{
+			formatType: "thrift",
+			data: map[string]interface{}{
+				"string": map[string]interface{}{"a": 1.0},
+				"i64":    map[string]interface{}{"b": 2.0},
+				"list":   map[string]interface{}{"c": "x"},
+				"tags":   []interface{}{"t"},
+			},
+			expectContains: []string{"struct String2 {", "struct I642 {", "struct List2 {", "list<string> tags", "String2 string_"},
+			expectMissing:  []string{"struct String {", "struct I64 {", "struct List {"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.formatType, func(t *testing.T) {
+			srv := New(8080, tt.formatType, false, false, tt.opts...)
+			result, err := srv.formatData(tt.data)
+			if err != nil {
+				t.Fatalf("formatData() error = %v", err)
+			}
+
+			for _, expect := range tt.expectContains {
+				if !strings.Contains(result, expect) {
+					t.Errorf("formatData() result does not contain expected string: %s\nGot: %s", expect, result)
+				}
+			}
+			for _, missing := range tt.expectMissing {
+				if strings.Contains(result, missing) {
+					t.Errorf("formatData() result contains unexpected string: %s\nGot: %s", missing, result)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package server
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ocamlKeywords lists the reserved words that cannot be used as field or type
+// names.
+var ocamlKeywords = map[string]bool{
+	"and": true, "as": true, "assert": true, "begin": true, "class": true,
+	"constraint": true, "do": true, "done": true, "downto": true, "else": true,
+	"end": true, "exception": true, "external": true, "false": true,
+	"for": true, "fun": true, "function": true, "functor": true, "if": true,
+	"in": true, "include": true, "inherit": true, "initializer": true,
+	"lazy": true, "let": true, "match": true, "method": true, "module": true,
+	"mutable": true, "new": true, "nonrec": true, "object": true, "of": true,
+	"open": true, "or": true, "private": true, "rec": true, "sig": true,
+	"struct": true, "then": true, "to": true, "true": true, "try": true,
+	"type": true, "val": true, "virtual": true, "when": true, "while": true,
+	"with": true,
+}
+
+// ocamlBuiltinTypes lists the predefined and Stdlib types, which a record of
+// the same name would shadow for the types declared after it.
+var ocamlBuiltinTypes = map[string]bool{
+	"array": true, "bool": true, "bytes": true, "char": true, "exn": true,
+	"float": true, "int": true, "int32": true, "int64": true, "lazy_t": true,
+	"list": true, "nativeint": true, "option": true, "ref": true,
+	"result": true, "string": true, "unit": true,
+}
+
+func (s *Server) formatAsOCaml(sch *schema) (string, error) {
+	root := sch
+	if sch.kind != kindObject {
+		root = &schema{kind: kindObject, fields: []*field{{name: "data", schema: sch}}}
+	}
+
+	// OCaml needs types defined before use, so emit children first
+	types := s.nestedObjects(s.structName, root)
+	records := make([]string, 0, len(types.objects))
+	for _, obj := range childrenFirst(types) {
+		records = append(records, s.generateOCamlRecord(obj, types))
+	}
+	return strings.Join(records, "\n\n"), nil
+}
+
+func (s *Server) generateOCamlRecord(obj *objectType, types *objectTypes) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "type %s = {\n", ocamlName(obj.name))
+
+	used := make(map[string]bool)
+	for _, f := range obj.schema.fields {
+		name := ocamlName(f.name)
+		base := name
+		for n := 2; used[name]; n++ {
+			name = fmt.Sprintf("%s_%d", base, n)
+		}
+		used[name] = true
+
+		fieldType := s.getOCamlType(f.schema, types)
+		var attrs string
+		if name != f.name {
+			attrs += fmt.Sprintf(" [@key %s]", strconv.Quote(f.name))
+		}
+		if f.optional {
+			fieldType = ocamlOption(fieldType)
+			attrs += " [@default None]"
+		}
+		fmt.Fprintf(&b, "  %s : %s%s;\n", name, fieldType, attrs)
+	}
+	b.WriteString("} [@@deriving yojson]")
+	return b.String()
+}
+
+func (s *Server) getOCamlType(sch *schema, types *objectTypes) string {
+	var ocamlType string
+	switch sch.kind {
+	case kindBool:
+		ocamlType = "bool"
+	case kindNumber:
+		ocamlType = "float"
+	case kindString:
+		ocamlType = "string"
+	case kindArray:
+		elemType := "Yojson.Safe.t"
+		if sch.elem != nil {
+			elemType = s.getOCamlType(sch.elem, types)
+		}
+		ocamlType = elemType + " list"
+	case kindObject:
+		ocamlType = ocamlName(types.name(sch))
+	case kindNull:
+		return "Yojson.Safe.t option"
+	default:
+		ocamlType = "Yojson.Safe.t"
+	}
+	if sch.nullable {
+		return ocamlOption(ocamlType)
+	}
+	return ocamlType
+}
+
+// ocamlOption makes an OCaml type optional unless it already is.
+func ocamlOption(ocamlType string) string {
+	if strings.HasSuffix(ocamlType, " option") {
+		return ocamlType
+	}
+	return ocamlType + " option"
+}
+
+// ocamlName converts a JSON key or type name into a lowercase snake_case
+// identifier, which OCaml requires for both record fields and type names.
+func ocamlName(key string) string {
+	name := sanitizeIdentifier(toSnakeCase(key))
+	if name == "_" {
+		return "field"
+	}
+	if ocamlKeywords[name] {
+		name += "_"
+	}
+	return name
+}
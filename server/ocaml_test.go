@@ -0,0 +1,48 @@
+package server
+
+import "testing"
+
+func TestFormatAsOCaml(t *testing.T) {
+	testFormatCases(t, "ocaml", []formatCase{
+		{
+			name: "Flat object",
+			data: map[string]interface{}{"name": "test", "value": 123.0, "active": true},
+			expectContains: []string{
+				"type generated_struct = {\n  active : bool;\n  name : string;\n  value : float;\n} [@@deriving yojson]",
+			},
+		},
+		{
+			name: "Nested objects, arrays and renamed keys",
+			data: map[string]interface{}{
+				"userInfo": map[string]interface{}{"full-name": "test"},
+				"tags":     []interface{}{"a", "b"},
+				"type":     "admin",
+			},
+			expectContains: []string{
+				"type user_info = {\n  full_name : string [@key \"full-name\"];\n} [@@deriving yojson]\n\ntype generated_struct = {",
+				"  tags : string list;",
+				"  type_ : string [@key \"type\"];",
+				"  user_info : user_info [@key \"userInfo\"];",
+			},
+		},
+		{
+			name: "Optional and null fields",
+			records: []interface{}{
+				map[string]interface{}{"id": 1.0, "email": nil, "tags": []interface{}{"a", nil}},
+				map[string]interface{}{"id": 2.0, "email": "x@example.com"},
+			},
+			expectContains: []string{
+				"  email : string option;",
+				"  id : float;",
+				"  tags : string option list option [@default None];",
+			},
+		},
+		{
+			name: "Non-object root",
+			data: []interface{}{1.0, 2.0},
+			expectContains: []string{
+				"type generated_struct = {\n  data : float list;\n}",
+			},
+		},
+	})
+}
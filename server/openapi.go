@@ -0,0 +1,159 @@
+package server
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+func (s *Server) formatAsOpenAPI(sch *schema) (string, error) {
+	// Nested objects become their own components referenced with $ref
+	rootName := s.structName
+	if sch.kind != kindObject {
+		rootName = s.structName + "Item"
+	}
+	types := s.nestedObjects(rootName, sch)
+	ref := func(obj *schema) string {
+		return "#/components/schemas/" + types.name(obj)
+	}
+
+	var lines []string
+	if sch.kind != kindObject {
+		lines = append(lines, openAPIYAML(s.structName, openAPISchema(sch, ref), "")...)
+	}
+	for _, obj := range types.objects {
+		lines = append(lines, openAPIYAML(obj.name, openAPIObject(obj.schema, ref), "")...)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// openAPISchema converts a schema into an OpenAPI schema object, the
+// document of both the openapi format and /openapi.json. Objects are
+// referenced through ref when it is set, and written inline otherwise. Mixed
+// types and values only ever seen as null are the empty schema, accepting
+// any value, as OpenAPI 3.0 has no null type and nullable requires a type.
+func openAPISchema(sch *schema, ref func(*schema) string) map[string]interface{} {
+	result := make(map[string]interface{})
+	switch sch.kind {
+	case kindBool:
+		result["type"] = "boolean"
+	case kindNumber:
+		if sch.integral() {
+			result["type"] = "integer"
+		} else {
+			result["type"] = "number"
+		}
+	case kindString:
+		result["type"] = "string"
+	case kindArray:
+		result["type"] = "array"
+		items := map[string]interface{}{}
+		if sch.elem != nil {
+			items = openAPISchema(sch.elem, ref)
+		}
+		result["items"] = items
+	case kindObject:
+		if ref == nil {
+			result = openAPIObject(sch, ref)
+			break
+		}
+		reference := map[string]interface{}{"$ref": ref(sch)}
+		if !sch.nullable {
+			return reference
+		}
+		// Siblings of $ref are ignored in OpenAPI 3.0, so wrap it
+		result["allOf"] = []map[string]interface{}{reference}
+	default:
+		return result
+	}
+	if sch.nullable {
+		result["nullable"] = true
+	}
+	return result
+}
+
+// openAPIObject converts the fields of an object schema into an OpenAPI
+// object, with its required properties.
+func openAPIObject(sch *schema, ref func(*schema) string) map[string]interface{} {
+	result := map[string]interface{}{"type": "object"}
+	var required []string
+	properties := make(map[string]interface{}, len(sch.fields))
+	for _, f := range sch.fields {
+		properties[f.name] = openAPISchema(f.schema, ref)
+		if !f.optional {
+			required = append(required, f.name)
+		}
+	}
+	if len(required) > 0 {
+		result["required"] = required
+	}
+	if len(properties) > 0 {
+		result["properties"] = properties
+	}
+	return result
+}
+
+// openAPIKeywords orders the keywords of schema objects in YAML output.
+var openAPIKeywords = []string{"$ref", "allOf", "type", "required", "properties", "items", "nullable"}
+
+// openAPIYAML renders a key followed by the schema object under it, writing
+// the empty schema inline.
+func openAPIYAML(key string, obj map[string]interface{}, indent string) []string {
+	if len(obj) == 0 {
+		return []string{indent + key + ": {}"}
+	}
+	return append([]string{indent + key + ":"}, openAPIYAMLFields(obj, indent+"  ")...)
+}
+
+// openAPIYAMLFields renders the keywords of a schema object, one per line.
+// Property names are quoted when needed, keywords never are.
+func openAPIYAMLFields(obj map[string]interface{}, indent string) []string {
+	var lines []string
+	for _, keyword := range openAPIKeywords {
+		switch value := obj[keyword].(type) {
+		case string:
+			lines = append(lines, indent+keyword+": "+openAPIScalar(value))
+		case bool:
+			lines = append(lines, fmt.Sprintf("%s%s: %t", indent, keyword, value))
+		case []string:
+			lines = append(lines, indent+keyword+":")
+			for _, item := range value {
+				lines = append(lines, indent+"  - "+openAPIKey(item))
+			}
+		case []map[string]interface{}:
+			lines = append(lines, indent+keyword+":")
+			for _, item := range value {
+				itemLines := openAPIYAMLFields(item, indent+"    ")
+				itemLines[0] = indent + "  - " + strings.TrimPrefix(itemLines[0], indent+"    ")
+				lines = append(lines, itemLines...)
+			}
+		case map[string]interface{}:
+			if keyword != "properties" {
+				lines = append(lines, openAPIYAML(keyword, value, indent)...)
+				break
+			}
+			lines = append(lines, indent+keyword+":")
+			for _, name := range sortedKeys(value) {
+				lines = append(lines, openAPIYAML(openAPIKey(name), value[name].(map[string]interface{}), indent+"  ")...)
+			}
+		}
+	}
+	return lines
+}
+
+// openAPIScalar quotes string values that are not plain identifiers, such as
+// $ref targets.
+func openAPIScalar(value string) string {
+	if isIdentifier(value) {
+		return value
+	}
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// openAPIKey quotes property names that are not plain identifiers.
+func openAPIKey(name string) string {
+	if isIdentifier(name) {
+		return name
+	}
+	return strconv.Quote(name)
+}
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatAsOpenAPI(t *testing.T) {
+	tests := []struct {
+		name           string
+		data           interface{}
+		records        []interface{}
+		expectContains []string
+		expectMissing  []string
+	}{
+		{
+			name: "Flat object",
+			data: map[string]interface{}{"name": "test", "value": 123.0, "ratio": 0.5},
+			expectContains: []string{
+				"GeneratedStruct:\n  type: object\n  required:\n    - name\n    - ratio\n    - value\n  properties:\n",
+				"    name:\n      type: string\n",
+				"    ratio:\n      type: number\n",
+				"    value:\n      type: integer",
+			},
+		},
+		{
+			name: "Nested objects become components",
+			data: map[string]interface{}{
+				"user":  map[string]interface{}{"id": 1.0},
+				"items": []interface{}{map[string]interface{}{"sku": "a"}},
+				"any":   []interface{}{},
+			},
+			expectContains: []string{
+				"    any:\n      type: array\n      items: {}\n",
+				"    items:\n      type: array\n      items:\n        $ref: '#/components/schemas/Items'\n",
+				"    user:\n      $ref: '#/components/schemas/User'",
+				"User:\n  type: object\n  required:\n    - id\n  properties:\n    id:\n      type: integer",
+				"Items:\n  type: object",
+			},
+		},
+		{
+			name: "Optional and nullable fields",
+			records: []interface{}{
+				map[string]interface{}{"id": 1.0, "email": nil, "owner": nil},
+				map[string]interface{}{"id": 2.0, "email": "x@example.com", "owner": map[string]interface{}{"id": 3.0}},
+				map[string]interface{}{"id": 3.0},
+			},
+			expectContains: []string{
+				"  required:\n    - id\n  properties:\n",
+				"    email:\n      type: string\n      nullable: true\n",
+				"    owner:\n      allOf:\n        - $ref: '#/components/schemas/Owner'\n      nullable: true",
+			},
+		},
+		{
+			name: "Null and mixed values",
+			data: map[string]interface{}{
+				"note":  nil,
+				"mixed": []interface{}{1.0, "a", nil},
+			},
+			expectContains: []string{
+				"    mixed:\n      type: array\n      items: {}\n",
+				"    note: {}",
+			},
+			expectMissing: []string{"nullable"},
+		},
+		{
+			name: "Array root",
+			data: []interface{}{map[string]interface{}{"id": 1.0}},
+			expectContains: []string{
+				"GeneratedStruct:\n  type: array\n  items:\n    $ref: '#/components/schemas/GeneratedStructItem'\n",
+				"GeneratedStructItem:\n  type: object",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sch := inferSchema(tt.data)
+			if tt.records != nil {
+				sch = inferRecords(tt.records)
+			}
+
+			srv := New(8080, "openapi", false, false)
+			result, err := srv.formatSchema(sch)
+			if err != nil {
+				t.Fatalf("formatSchema() error = %v", err)
+			}
+
+			for _, expect := range tt.expectContains {
+				if !strings.Contains(result, expect) {
+					t.Errorf("formatSchema() result does not contain expected string: %s\nGot: %s", expect, result)
+				}
+			}
+			for _, missing := range tt.expectMissing {
+				if strings.Contains(result, missing) {
+					t.Errorf("formatSchema() result contains unexpected string: %s\nGot: %s", missing, result)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,457 @@
+package server
+
+import (
+	"io"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Option configures optional Server behavior.
+type Option func(*Server)
+
+// WithDetectEnums emits typed enums for string fields that only take a small
+// set of values across samples.
+func WithDetectEnums(enabled bool) Option {
+	return func(s *Server) {
+		s.detectEnums = enabled
+	}
+}
+
+// WithIndent sets the indentation used for pretty printed JSON.
+func WithIndent(indent string) Option {
+	return func(s *Server) {
+		s.indent = indent
+	}
+}
+
+// WithColor highlights generated Go and Rust code with ANSI colors.
+func WithColor(enabled bool) Option {
+	return func(s *Server) {
+		s.color = enabled
+	}
+}
+
+// WithDetectUUID types strings that look like UUIDs as uuid.UUID in Go and
+// uuid::Uuid in Rust.
+func WithDetectUUID(enabled bool) Option {
+	return func(s *Server) {
+		s.detectUUID = enabled
+	}
+}
+
+// WithQuiet suppresses informational logging such as the per-request
+// "Received" line, leaving only the JSON and struct output.
+func WithQuiet(enabled bool) Option {
+	return func(s *Server) {
+		s.quiet = enabled
+	}
+}
+
+// WithExtraPorts makes Start listen on the given ports in addition to the one
+// passed to New, all serving the same handler.
+func WithExtraPorts(ports ...int) Option {
+	return func(s *Server) {
+		s.extraPorts = ports
+	}
+}
+
+// WithReadTimeout sets the maximum duration for reading an entire request.
+func WithReadTimeout(timeout time.Duration) Option {
+	return func(s *Server) {
+		s.readTimeout = timeout
+	}
+}
+
+// WithWriteTimeout sets the maximum duration for writing a response.
+func WithWriteTimeout(timeout time.Duration) Option {
+	return func(s *Server) {
+		s.writeTimeout = timeout
+	}
+}
+
+// WithH2C accepts HTTP/2 over cleartext connections alongside HTTP/1.1.
+func WithH2C(enabled bool) Option {
+	return func(s *Server) {
+		s.h2c = enabled
+	}
+}
+
+// WithMiddleware registers middleware around every route, as Use does.
+func WithMiddleware(mw ...Middleware) Option {
+	return func(s *Server) {
+		s.Use(mw...)
+	}
+}
+
+// WithOnce makes Start return after the first request processed successfully.
+func WithOnce(enabled bool) Option {
+	return func(s *Server) {
+		s.once = enabled
+	}
+}
+
+// WithFormatHeaders generates a struct for the request headers in addition to
+// the body.
+func WithFormatHeaders(enabled bool) Option {
+	return func(s *Server) {
+		s.formatHeaders = enabled
+	}
+}
+
+// WithGeneratedHeader starts generated code with a "Code generated ... DO NOT
+// EDIT." comment naming the request path or input file.
+func WithGeneratedHeader(enabled bool) Option {
+	return func(s *Server) {
+		s.generatedHeader = enabled
+	}
+}
+
+// WithVersion sets the version reported by the /version endpoint.
+func WithVersion(version string) Option {
+	return func(s *Server) {
+		s.version = version
+	}
+}
+
+// WithDetectBase64 types strings holding base64 encoded binary as []byte in Go
+// and Vec<u8> in Rust.
+func WithDetectBase64(enabled bool) Option {
+	return func(s *Server) {
+		s.detectBase64 = enabled
+	}
+}
+
+// WithDetectDurations types strings that parse with time.ParseDuration, such
+// as "5m30s", as a wrapper of time.Duration decoding them in Go and as
+// std::time::Duration in Rust.
+func WithDetectDurations(enabled bool) Option {
+	return func(s *Server) {
+		s.detectDurations = enabled
+	}
+}
+
+// WithGraphQLKind declares the GraphQL types as object types with "type",
+// the default, or as input types for mutation arguments with "input".
+func WithGraphQLKind(kind string) Option {
+	return func(s *Server) {
+		s.graphqlKind = kind
+	}
+}
+
+// WithGraphQLScalars types the fields of the detected kinds, date, id or
+// uuid, with the mapped GraphQL scalars, such as DateTime for dates.
+func WithGraphQLScalars(scalars map[string]string) Option {
+	return func(s *Server) {
+		s.graphqlScalars = scalars
+	}
+}
+
+// WithPackage starts Go output with a package clause naming pkg and an import
+// block for the packages its field types use. An empty name leaves both out.
+func WithPackage(pkg string) Option {
+	return func(s *Server) {
+		s.goPackage = pkg
+	}
+}
+
+// WithDetectNet types strings holding IP addresses as net.IP in Go and
+// std::net::IpAddr in Rust, and CIDR networks as *net.IPNet and ipnet::IpNet.
+func WithDetectNet(enabled bool) Option {
+	return func(s *Server) {
+		s.detectNet = enabled
+	}
+}
+
+// WithPointers makes every generated Go field a pointer with omitempty, so
+// absent values can be told apart from zero values.
+func WithPointers(enabled bool) Option {
+	return func(s *Server) {
+		s.pointers = enabled
+	}
+}
+
+// WithGoTags sets the struct tag keys written for every generated Go field,
+// such as json, bson and yaml. Without tags only json is written.
+func WithGoTags(tags []string) Option {
+	return func(s *Server) {
+		s.goTags = tags
+	}
+}
+
+// WithGoAny spells the Go type of untyped values any, available since Go 1.18,
+// instead of interface{}.
+func WithGoAny(enabled bool) Option {
+	return func(s *Server) {
+		s.goAnyAlias = enabled
+	}
+}
+
+// WithNarrowInts types whole number fields in Go and Rust as int32 and i32
+// when every observed value fits, and as int64 and i64 otherwise.
+func WithNarrowInts(enabled bool) Option {
+	return func(s *Server) {
+		s.narrowInts = enabled
+	}
+}
+
+// WithValidate logs a report of each request's inferred schema, with
+// warnings about ambiguous types, in place of generated code.
+func WithValidate(enabled bool) Option {
+	return func(s *Server) {
+		s.validate = enabled
+	}
+}
+
+// WithGzip compresses responses of at least 1 KiB, such as large generated
+// code or the accumulated OpenAPI document, for clients that send
+// Accept-Encoding: gzip.
+func WithGzip(enabled bool) Option {
+	return func(s *Server) {
+		s.gzip = enabled
+	}
+}
+
+// WithMeta adds a "meta" object to the JSON acknowledgement of requests with
+// a JSON body, counting its top-level fields, nesting depth, optional fields
+// and bytes.
+func WithMeta(enabled bool) Option {
+	return func(s *Server) {
+		s.meta = enabled
+	}
+}
+
+// WithVerbose logs extra detail, such as the files each field of a merged
+// type was seen in.
+func WithVerbose(enabled bool) Option {
+	return func(s *Server) {
+		s.verbose = enabled
+	}
+}
+
+// WithNumberType types every number field of Go or Rust output as numberType,
+// such as int64 or json.Number, whatever the observed values. Types that are
+// not valid for the format, listed by NumberTypes, are ignored.
+func WithNumberType(numberType string) Option {
+	return func(s *Server) {
+		s.numberType = numberType
+	}
+}
+
+// WithStrictKeys refuses JSON bodies that repeat a key within one object with
+// 400 Bad Request, instead of logging a warning and keeping the last value.
+func WithStrictKeys(enabled bool) Option {
+	return func(s *Server) {
+		s.strictKeys = enabled
+	}
+}
+
+// WithPaths only processes requests whose path matches one of the path.Match
+// patterns, or lies below a match, such as /api/users/1 for /api/*. Other
+// requests are acknowledged without being logged or formatted. Without
+// patterns every path is processed.
+func WithPaths(patterns []string) Option {
+	return func(s *Server) {
+		s.paths = patterns
+	}
+}
+
+// WithWebSocket serves a /ws WebSocket endpoint replying to each JSON message
+// with the code generated for it, in the server's format.
+func WithWebSocket(enabled bool) Option {
+	return func(s *Server) {
+		s.websocket = enabled
+	}
+}
+
+// WithAnnotateTypes logs each JSON body a second time, indented, with a
+// comment naming the type of every leaf value: string, int, float, bool or
+// null. It is a diagnostic view, independent of the generated code.
+func WithAnnotateTypes(enabled bool) Option {
+	return func(s *Server) {
+		s.annotateTypes = enabled
+	}
+}
+
+// WithTypeOverrides forces the type of the Go or Rust fields at the given
+// dotted JSON paths, such as owner.created_at, in place of the inferred one.
+// Optional fields are still made pointers or Options.
+func WithTypeOverrides(overrides map[string]string) Option {
+	return func(s *Server) {
+		s.typeOverrides = overrides
+	}
+}
+
+// WithRustDerives adds derives to generated Rust structs, after the default
+// Debug, Serialize and Deserialize.
+func WithRustDerives(derives []string) Option {
+	return func(s *Server) {
+		s.rustDerives = derives
+	}
+}
+
+// WithRustPub makes generated Rust structs, enums and fields pub.
+func WithRustPub(enabled bool) Option {
+	return func(s *Server) {
+		s.rustPub = enabled
+	}
+}
+
+// WithFlatten inlines nested objects in Go output as anonymous structs
+// instead of declaring a named type for each.
+func WithFlatten(enabled bool) Option {
+	return func(s *Server) {
+		s.flatten = enabled
+	}
+}
+
+// WithRubySorbet generates Sorbet T::Struct classes with typed props instead
+// of plain Ruby Structs.
+func WithRubySorbet(enabled bool) Option {
+	return func(s *Server) {
+		s.rubySorbet = enabled
+	}
+}
+
+// WithKotlinStyle selects the Kotlin annotations: kotlinx for
+// kotlinx.serialization, or jackson or moshi for plain data classes.
+func WithKotlinStyle(style string) Option {
+	return func(s *Server) {
+		s.kotlinStyle = style
+	}
+}
+
+// WithComments ends generated Go and Rust fields with a comment showing an
+// observed example value.
+func WithComments(enabled bool) Option {
+	return func(s *Server) {
+		s.comments = enabled
+	}
+}
+
+// WithMaxDepth sets how many levels of nested arrays and objects are typed
+// before falling back to the format's catch-all type. Zero disables the limit.
+func WithMaxDepth(depth int) Option {
+	return func(s *Server) {
+		s.maxDepth = depth
+	}
+}
+
+// WithStrictDepth refuses bodies nested deeper than the maximum depth instead
+// of typing the deeper values with the format's catch-all type.
+func WithStrictDepth(enabled bool) Option {
+	return func(s *Server) {
+		s.strictDepth = enabled
+	}
+}
+
+// WithMaxFields sets how many fields the objects of one body may have between
+// them before the body is refused rather than generated. Zero disables the
+// limit.
+func WithMaxFields(fields int) Option {
+	return func(s *Server) {
+		s.maxFields = fields
+	}
+}
+
+// WithNestedNaming sets how nested types are named: "key" names them after
+// their JSON key and "path" also prefixes the parent type name.
+func WithNestedNaming(naming string) Option {
+	return func(s *Server) {
+		s.nestedNaming = naming
+	}
+}
+
+// WithTracerProvider records a span for every request handled by the server
+// using the given provider. Without it tracing is a no-op.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(s *Server) {
+		s.tracer = provider.Tracer(tracerName)
+	}
+}
+
+// WithRateLimit rejects requests beyond requestsPerSecond with 429 Too Many
+// Requests, counting each remote IP separately when perIP is set. Zero
+// disables the limit.
+func WithRateLimit(requestsPerSecond float64, perIP bool) Option {
+	return func(s *Server) {
+		s.rateLimit = requestsPerSecond
+		s.rateLimitPerIP = perIP
+	}
+}
+
+// WithConcurrency formats at most n requests at once. Further requests wait
+// briefly for a free worker, then get 503 Service Unavailable. Zero disables
+// the limit.
+func WithConcurrency(n int) Option {
+	return func(s *Server) {
+		s.pool = nil
+		if n > 0 {
+			s.pool = newWorkerPool(n)
+		}
+	}
+}
+
+// WithMaxBodySize rejects request bodies larger than size bytes with 413
+// Request Entity Too Large. Zero leaves bodies unlimited.
+func WithMaxBodySize(size int64) Option {
+	return func(s *Server) {
+		s.maxBodySize = size
+	}
+}
+
+// WithOutputFile saves the code generated for each request to path,
+// replacing the file every time. With appendMode, each body of a new shape
+// is appended instead, with type names made unique across the file.
+func WithOutputFile(path string, appendMode bool) Option {
+	return func(s *Server) {
+		s.output = nil
+		if path != "" {
+			s.output = newOutputFile(path, appendMode)
+		}
+	}
+}
+
+// WithCapture appends the records of every JSON, NDJSON, JSON text
+// sequence or CSV body received to w as newline-delimited JSON, one record
+// per line, which Replay reads back. A nil w disables capturing.
+func WithCapture(w io.Writer) Option {
+	return func(s *Server) {
+		s.capture = nil
+		if w != nil {
+			s.capture = newCaptureWriter(w)
+		}
+	}
+}
+
+// WithCacheSize remembers the code generated for up to size distinct bodies,
+// so repeated payloads skip inference. Zero disables the cache.
+func WithCacheSize(size int) Option {
+	return func(s *Server) {
+		s.cache = nil
+		if size > 0 {
+			s.cache = newOutputCache(size)
+		}
+	}
+}
+
+// WithOpenAPISpec makes the server remember the method, path and merged body
+// schema of every request, serving them as an OpenAPI document at
+// /openapi.json.
+func WithOpenAPISpec(enabled bool) Option {
+	return func(s *Server) {
+		if enabled {
+			s.spec = newSpecStore()
+		} else {
+			s.spec = nil
+		}
+	}
+}
+
+// WithCoerceNumericStrings types string fields whose values are all numbers,
+// such as "42", as numbers read from strings.
+func WithCoerceNumericStrings(enabled bool) Option {
+	return func(s *Server) {
+		s.coerceNumericStrings = enabled
+	}
+}
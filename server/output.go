@@ -0,0 +1,178 @@
+package server
+
+import (
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync"
+)
+
+// outputFile saves generated code to a file, either replacing its contents
+// on every request or accumulating one declaration per distinct body shape.
+type outputFile struct {
+	path   string
+	append bool
+
+	mu      sync.Mutex
+	started bool
+	// rendered maps the code generated for each appended body, before any
+	// name is reserved, to the name its root type took in the file.
+	rendered map[string]string
+	// rows holds the root types given a CSV rows alias in the file.
+	rows map[string]bool
+	// declared holds every type name already appended.
+	declared map[string]bool
+	// goDuration names the Go duration wrapper already appended, if any.
+	goDuration string
+}
+
+func newOutputFile(path string, appendMode bool) *outputFile {
+	return &outputFile{
+		path:     path,
+		append:   appendMode,
+		rendered: make(map[string]string),
+		rows:     make(map[string]bool),
+		declared: make(map[string]bool),
+	}
+}
+
+// writeOutput saves the code generated for a request body to the output
+// file. In append mode, bodies generating the same code as one already
+// written are skipped, and others are generated again with every type name
+// in the file reserved, so that no type is declared twice, and appended
+// without the code the file needs only once. The Go duration wrapper is
+// declared once and shared. CSV bodies get the rows alias in both modes,
+// once per root type.
+func (s *Server) writeOutput(logger *log.Logger, sch *schema, formatted, source string, csv bool) error {
+	o := s.output
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if !o.append {
+		return os.WriteFile(o.path, []byte(formatted+"\n"), 0o644)
+	}
+
+	// Bodies of the same shape may still differ in enum values or integer
+	// width, so they are told apart by their code. Warnings were logged when
+	// the body was formatted
+	key, err := s.formatLimited(log.New(io.Discard, "", 0), sch)
+	if err != nil {
+		return err
+	}
+	reserved := *s
+	reserved.reservedNames = o.declared
+	reserved.goDurationName = o.goDuration
+	root, written := o.rendered[key]
+	var code string
+	var names []string
+	var goDuration string
+	if !written {
+		if code, err = reserved.formatLimited(logger, sch); err != nil {
+			return err
+		}
+		names, root, goDuration = reserved.declaredNames(sch)
+	}
+	var rows string
+	if csv && !o.rows[root] && s.rowsAlias("Rows", root) != "" {
+		rows = s.rowsAlias(uniqueName("Rows", o.declared), root)
+	}
+	if code == "" && rows == "" {
+		return nil
+	}
+
+	// Each run starts the file afresh, as names from earlier runs are unknown.
+	// Only the first block keeps the preamble, footer and generated comment
+	flags := os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	if !o.started {
+		flags |= os.O_TRUNC
+		code = reserved.withGeneratedComment(code, source)
+	} else if code != "" {
+		preamble, footer := s.filePreamble()
+		code = strings.TrimSuffix(strings.TrimPrefix(code, preamble), footer)
+	}
+	if rows != "" {
+		code = strings.TrimPrefix(code+"\n\n"+rows, "\n\n")
+	}
+	if o.started {
+		code = "\n" + code
+	}
+	file, err := os.OpenFile(o.path, flags, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.WriteString(code + "\n"); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	o.started = true
+	o.rendered[key] = root
+	if rows != "" {
+		o.rows[root] = true
+	}
+	for _, name := range names {
+		o.declared[name] = true
+	}
+	if goDuration != "" {
+		o.declared[goDuration] = true
+		o.goDuration = goDuration
+	}
+	return nil
+}
+
+// declaredNames lists the object and enum type names generated for a root
+// schema, and returns the name of the root type and of the Go duration
+// wrapper the code uses.
+func (s *Server) declaredNames(sch *schema) ([]string, string, string) {
+	root := sch
+	if sch.kind != kindObject {
+		root = &schema{kind: kindObject, fields: []*field{{name: "data", schema: sch}}}
+	}
+	types := s.nestedObjects(s.structName, root)
+	enums, enumNames := s.enumTypes(types)
+
+	names := make([]string, 0, len(types.objects)+len(enums))
+	for _, obj := range types.objects {
+		names = append(names, obj.name)
+	}
+	for _, enum := range enums {
+		names = append(names, enum.name)
+	}
+	if s.formatType == "go" && s.usesGoDuration(types) {
+		return names, types.name(root), s.goDurationType(types, enumNames)
+	}
+	return names, types.name(root), ""
+}
+
+// filePreamble returns the code the output format starts with, ahead of any
+// declaration, and ends with, after the last one. A file needs either only
+// once.
+func (s *Server) filePreamble() (preamble, footer string) {
+	switch s.formatType {
+	case "php":
+		return phpOpenTag, ""
+	case "crystal":
+		return crystalPreamble, ""
+	case "haskell":
+		return haskellPreamble, ""
+	case "fsharp":
+		return fsharpPreamble, ""
+	case "dart":
+		return dartPreamble(s.structName), ""
+	case "elm":
+		return elmPreamble(s.structName), elmFooter
+	case "clojure":
+		return clojurePreamble(s.structName), ""
+	case "mermaid":
+		return mermaidPreamble, ""
+	case "ruby":
+		if s.rubySorbet {
+			return sorbetPreamble, ""
+		}
+	}
+	return "", ""
+}
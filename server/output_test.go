@@ -0,0 +1,269 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestServer_OutputFile(t *testing.T) {
+	bodies := []string{
+		`{"id":1,"address":{"city":"Paris"}}`,
+		`{"id":2,"address":{"city":"Lyon"}}`,
+		`{"name":"alice","address":{"zip":75001}}`,
+		`{"id":3,"address":{"city":"Nice"}}`,
+	}
+
+	tests := []struct {
+		name       string
+		appendMode bool
+		expect     string
+	}{
+		{
+			name: "Replace with the latest request",
+			expect: "type GeneratedStruct struct {\n" +
+				"    address Address `json:\"address\"`\n" +
+				"    id float64 `json:\"id\"`\n" +
+				"}\n\n" +
+				"type Address struct {\n" +
+				"    city string `json:\"city\"`\n" +
+				"}\n",
+		},
+		{
+			name:       "Append each distinct shape once",
+			appendMode: true,
+			expect: "type GeneratedStruct struct {\n" +
+				"    address Address `json:\"address\"`\n" +
+				"    id float64 `json:\"id\"`\n" +
+				"}\n\n" +
+				"type Address struct {\n" +
+				"    city string `json:\"city\"`\n" +
+				"}\n\n" +
+				"type GeneratedStruct2 struct {\n" +
+				"    address Address2 `json:\"address\"`\n" +
+				"    name string `json:\"name\"`\n" +
+				"}\n\n" +
+				"type Address2 struct {\n" +
+				"    zip float64 `json:\"zip\"`\n" +
+				"}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "types.go")
+			// A file left by an earlier run is started afresh
+			if err := os.WriteFile(path, []byte("type Stale struct{}\n"), 0o644); err != nil {
+				t.Fatalf("WriteFile() error = %v", err)
+			}
+
+			srv := New(8080, "go", false, false, WithQuiet(true), WithOutputFile(path, tt.appendMode))
+			for _, body := range bodies {
+				req := httptest.NewRequest("POST", "/api/data", strings.NewReader(body))
+				req.Header.Set("Content-Type", "application/json")
+				rr := httptest.NewRecorder()
+				srv.handleRequest(rr, req)
+				if rr.Code != http.StatusOK {
+					t.Fatalf("handler returned wrong status code: got %v want %v\n%s", rr.Code, http.StatusOK, rr.Body.String())
+				}
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("ReadFile() error = %v", err)
+			}
+			if string(data) != tt.expect {
+				t.Errorf("output file = %s\nwant %s", data, tt.expect)
+			}
+		})
+	}
+}
+
+func TestServer_OutputFileReservesEnums(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "types.go")
+	srv := New(8080, "go", false, false, WithDetectEnums(true), WithOutputFile(path, true))
+
+	for _, records := range [][]interface{}{
+		{
+			map[string]interface{}{"status": "on"},
+			map[string]interface{}{"status": "off"},
+			map[string]interface{}{"status": "on"},
+		},
+		{
+			map[string]interface{}{"status": "open", "id": 1.0},
+			map[string]interface{}{"status": "closed", "id": 2.0},
+			map[string]interface{}{"status": "open", "id": 3.0},
+		},
+	} {
+		if err := srv.writeOutput(requestLogger("test"), inferRecords(records), "", "/api/data", false); err != nil {
+			t.Fatalf("writeOutput() error = %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	for _, expect := range []string{"type Status string", "type Status2 string", "status Status2 `json:\"status\"`"} {
+		if !strings.Contains(string(data), expect) {
+			t.Errorf("output file does not contain expected string: %s\nGot: %s", expect, data)
+		}
+	}
+}
+
+func TestServer_OutputFileAppendsPreambleOnce(t *testing.T) {
+	tests := []struct {
+		formatType string
+		once       []string
+	}{
+		{formatType: "php", once: []string{"<?php", "Code generated by reqparser"}},
+		{formatType: "elm", once: []string{"module GeneratedStruct exposing", "andMap =", "Code generated by reqparser"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.formatType, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "types")
+			srv := New(8080, tt.formatType, false, false, WithGeneratedHeader(true), WithOutputFile(path, true))
+			for _, data := range []interface{}{
+				map[string]interface{}{"id": 1.0},
+				map[string]interface{}{"name": "alice"},
+			} {
+				if err := srv.writeOutput(requestLogger("test"), inferSchema(data), "", "/api/data", false); err != nil {
+					t.Fatalf("writeOutput() error = %v", err)
+				}
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("ReadFile() error = %v", err)
+			}
+			for _, once := range tt.once {
+				if n := strings.Count(string(data), once); n != 1 {
+					t.Errorf("output file contains %q %d times\nGot: %s", once, n, data)
+				}
+			}
+			if !strings.Contains(string(data), "GeneratedStruct2") {
+				t.Errorf("output file does not contain the second shape\nGot: %s", data)
+			}
+		})
+	}
+}
+
+func TestServer_OutputFileSharesGoDuration(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "types.go")
+	srv := New(8080, "go", false, false, WithDetectDurations(true), WithOutputFile(path, true))
+	for _, data := range []interface{}{
+		map[string]interface{}{"timeout": "5m30s"},
+		map[string]interface{}{"timeout": "1h", "id": 1.0},
+	} {
+		if err := srv.writeOutput(requestLogger("test"), inferSchema(data), "", "/api/data", false); err != nil {
+			t.Fatalf("writeOutput() error = %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if n := strings.Count(string(data), "type Duration struct"); n != 1 {
+		t.Errorf("Duration declared %d times\nGot: %s", n, data)
+	}
+	if strings.Contains(string(data), "Duration2") {
+		t.Errorf("output file declares a second duration wrapper\nGot: %s", data)
+	}
+	if n := strings.Count(string(data), "timeout Duration `json:\"timeout\"`"); n != 2 {
+		t.Errorf("%d fields use the shared Duration, want 2\nGot: %s", n, data)
+	}
+}
+
+func TestServer_OutputFileAppendsSameShapeWithOtherCode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "types.go")
+	srv := New(8080, "go", false, false, WithDetectEnums(true), WithOutputFile(path, true))
+
+	// Both bodies have a status string field, with different enum values
+	for _, records := range [][]interface{}{
+		{
+			map[string]interface{}{"status": "on"},
+			map[string]interface{}{"status": "off"},
+			map[string]interface{}{"status": "on"},
+		},
+		{
+			map[string]interface{}{"status": "open"},
+			map[string]interface{}{"status": "closed"},
+			map[string]interface{}{"status": "open"},
+		},
+		{
+			map[string]interface{}{"status": "off"},
+			map[string]interface{}{"status": "on"},
+			map[string]interface{}{"status": "off"},
+		},
+	} {
+		if err := srv.writeOutput(requestLogger("test"), inferRecords(records), "", "/api/data", false); err != nil {
+			t.Fatalf("writeOutput() error = %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	for _, expect := range []string{`StatusOn Status = "on"`, `Status2Open Status2 = "open"`, "type GeneratedStruct2 struct"} {
+		if !strings.Contains(string(data), expect) {
+			t.Errorf("output file does not contain expected string: %s\nGot: %s", expect, data)
+		}
+	}
+	if strings.Contains(string(data), "GeneratedStruct3") {
+		t.Errorf("output file repeats the first body\nGot: %s", data)
+	}
+}
+
+func TestServer_OutputFileRowsAlias(t *testing.T) {
+	bodies := []struct {
+		contentType string
+		body        string
+	}{
+		{"text/csv", "id,name\n1,a\n"},
+		{"text/csv", "id,name\n2,b\n"},
+		{"application/json", `{"zip":75001}`},
+		{"text/csv", "zip\n75001\n"},
+	}
+
+	for _, appendMode := range []bool{false, true} {
+		path := filepath.Join(t.TempDir(), "types.go")
+		srv := New(8080, "go", false, false, WithQuiet(true), WithOutputFile(path, appendMode))
+		for _, body := range bodies[:2] {
+			req := httptest.NewRequest("POST", "/api/data", strings.NewReader(body.body))
+			req.Header.Set("Content-Type", body.contentType)
+			srv.handleRequest(httptest.NewRecorder(), req)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("ReadFile() error = %v", err)
+		}
+		if n := strings.Count(string(data), "type Rows []GeneratedStruct\n"); n != 1 {
+			t.Errorf("append = %v: rows alias declared %d times\nGot: %s", appendMode, n, data)
+		}
+	}
+
+	// Each root type gets its own alias, named apart
+	path := filepath.Join(t.TempDir(), "types.go")
+	srv := New(8080, "go", false, false, WithQuiet(true), WithOutputFile(path, true))
+	for _, body := range bodies {
+		req := httptest.NewRequest("POST", "/api/data", strings.NewReader(body.body))
+		req.Header.Set("Content-Type", body.contentType)
+		srv.handleRequest(httptest.NewRecorder(), req)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	for _, expect := range []string{"type Rows []GeneratedStruct\n", "type GeneratedStruct2 struct", "\n\ntype Rows2 []GeneratedStruct2\n"} {
+		if !strings.Contains(string(data), expect) {
+			t.Errorf("output file does not contain expected string: %s\nGot: %s", expect, data)
+		}
+	}
+}
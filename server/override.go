@@ -0,0 +1,101 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseTypeOverrides parses a -type-override value such as
+// "user_id=int64,owner.created_at=time.Time" into field paths and the types
+// forced on them. Commas inside brackets, as in HashMap<String, i64>, belong
+// to the type.
+func ParseTypeOverrides(value string) (map[string]string, error) {
+	overrides := make(map[string]string)
+	for _, entry := range splitTopLevel(value) {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		path, typ, ok := strings.Cut(entry, "=")
+		path, typ = strings.TrimSpace(path), strings.TrimSpace(typ)
+		if !ok || path == "" || typ == "" {
+			return nil, fmt.Errorf("%q is not path=type", entry)
+		}
+		for _, key := range strings.Split(path, ".") {
+			if key == "" {
+				return nil, fmt.Errorf("%q has an empty path segment", path)
+			}
+		}
+		if _, dup := overrides[path]; dup {
+			return nil, fmt.Errorf("%q is overridden twice", path)
+		}
+		overrides[path] = typ
+	}
+	return overrides, nil
+}
+
+// splitTopLevel splits a comma-separated list, keeping the commas between
+// brackets inside their element.
+func splitTopLevel(value string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range value {
+		switch r {
+		case '<', '[', '(', '{':
+			depth++
+		case '>', ']', ')', '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, value[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, value[start:])
+}
+
+// typeOverrideFormats lists the formats whose generators apply -type-override.
+var typeOverrideFormats = map[string]bool{"go": true, "rust": true}
+
+// TypeOverrideFormats reports whether a format supports -type-override.
+func TypeOverrideFormats(format string) bool {
+	return typeOverrideFormats[format]
+}
+
+// withTypeOverrides returns a copy of the schema in which every field whose
+// dotted JSON path has an override is replaced by a schema carrying the forced
+// type, so that no nested type is generated for it. Arrays are transparent to
+// paths: items.id names the id of the objects in the items array, and id the
+// id of the objects of an array root.
+func withTypeOverrides(sch *schema, overrides map[string]string) *schema {
+	if len(overrides) == 0 || sch == nil {
+		return sch
+	}
+	return overrideAt(sch, "", overrides)
+}
+
+func overrideAt(sch *schema, prefix string, overrides map[string]string) *schema {
+	switch sch.kind {
+	case kindArray:
+		if sch.elem == nil {
+			return sch
+		}
+		overridden := *sch
+		overridden.elem = overrideAt(sch.elem, prefix, overrides)
+		return &overridden
+	case kindObject:
+		overridden := *sch
+		overridden.fields = make([]*field, len(sch.fields))
+		for i, f := range sch.fields {
+			path := prefix + f.name
+			fieldSchema := overrideAt(f.schema, path+".", overrides)
+			if typ, ok := overrides[path]; ok {
+				fieldSchema = &schema{kind: kindMixed, nullable: f.schema.nullable, override: typ}
+			}
+			overridden.fields[i] = &field{name: f.name, schema: fieldSchema, optional: f.optional}
+		}
+		return &overridden
+	}
+	return sch
+}
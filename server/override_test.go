@@ -0,0 +1,140 @@
+package server
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTypeOverrides(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     string
+		expected  map[string]string
+		expectErr string
+	}{
+		{
+			name:     "Empty",
+			value:    "",
+			expected: map[string]string{},
+		},
+		{
+			name:     "Several paths",
+			value:    "user_id=int64, owner.created_at = time.Time",
+			expected: map[string]string{"user_id": "int64", "owner.created_at": "time.Time"},
+		},
+		{
+			name:     "Commas inside brackets",
+			value:    "meta=HashMap<String, i64>,pair=(i64, i64),id=u64",
+			expected: map[string]string{"meta": "HashMap<String, i64>", "pair": "(i64, i64)", "id": "u64"},
+		},
+		{name: "Missing type", value: "id=", expectErr: `"id=" is not path=type`},
+		{name: "Missing separator", value: "id", expectErr: `"id" is not path=type`},
+		{name: "Empty segment", value: "owner..id=int64", expectErr: `"owner..id" has an empty path segment`},
+		{name: "Duplicate path", value: "id=int64,id=int32", expectErr: `"id" is overridden twice`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTypeOverrides(tt.value)
+			if tt.expectErr != "" {
+				if err == nil || err.Error() != tt.expectErr {
+					t.Fatalf("ParseTypeOverrides() error = %v, want %s", err, tt.expectErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseTypeOverrides() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("ParseTypeOverrides() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFormatSchema_TypeOverrides(t *testing.T) {
+	records := []interface{}{
+		map[string]interface{}{
+			"user_id": 1.0,
+			"owner":   map[string]interface{}{"created_at": "2024-01-01T00:00:00Z", "meta": map[string]interface{}{"a": 1.0}},
+			"items":   []interface{}{map[string]interface{}{"n": 1.0}, map[string]interface{}{"m": 2.0}},
+			"x":       map[string]interface{}{"id": 1.0},
+			"list":    []interface{}{map[string]interface{}{"x": map[string]interface{}{"id": 2.0}}},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		formatType     string
+		overrides      map[string]string
+		expectContains []string
+		expectMissing  []string
+	}{
+		{
+			name:       "Go",
+			formatType: "go",
+			overrides: map[string]string{
+				"user_id":          "int64",
+				"owner.created_at": "time.Time",
+				"owner.meta":       "map[string]any",
+				"items.n":          "uint8",
+			},
+			expectContains: []string{
+				"    user_id int64 `json:\"user_id\"`\n",
+				"    created_at time.Time `json:\"created_at\"`\n",
+				"    meta map[string]any `json:\"meta\"`\n",
+				"    n *uint8 `json:\"n,omitempty\"`\n",
+			},
+			expectMissing: []string{"type Meta struct"},
+		},
+		{
+			name:       "Rust",
+			formatType: "rust",
+			overrides:  map[string]string{"user_id": "i64", "owner.meta": "HashMap<String, i64>", "items.n": "u8"},
+			expectContains: []string{
+				"    user_id: i64,\n",
+				"    meta: HashMap<String, i64>,\n",
+				"    n: Option<u8>,\n",
+			},
+		},
+		{
+			name:       "Only the overridden path of a shared shape",
+			formatType: "go",
+			overrides:  map[string]string{"x.id": "int64"},
+			expectContains: []string{
+				"    x X2 `json:\"x\"`\n",
+				"type X struct {\n    id float64 `json:\"id\"`\n}",
+				"type X2 struct {\n    id int64 `json:\"id\"`\n}",
+			},
+		},
+		{
+			name:           "Ignored by other formats",
+			formatType:     "typeddict",
+			overrides:      map[string]string{"user_id": "int64"},
+			expectContains: []string{"    user_id: float\n"},
+			expectMissing:  []string{"int64"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := New(8080, tt.formatType, false, false, WithTypeOverrides(tt.overrides))
+			result, err := srv.formatSchema(inferRecords(records))
+			if err != nil {
+				t.Fatalf("formatSchema() error = %v", err)
+			}
+
+			for _, expect := range tt.expectContains {
+				if !strings.Contains(result, expect) {
+					t.Errorf("formatSchema() result does not contain expected string: %s\nGot: %s", expect, result)
+				}
+			}
+			for _, missing := range tt.expectMissing {
+				if strings.Contains(result, missing) {
+					t.Errorf("formatSchema() result contains unexpected string: %s\nGot: %s", missing, result)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package server
+
+import (
+	"path"
+	"strings"
+)
+
+// matchesPath reports whether a request path matches one of the path.Match
+// patterns. A pattern matching a parent of the path matches it too, so /api/*
+// covers /api/users/1 as well as /api/users. An empty list matches every path.
+func matchesPath(patterns []string, requestPath string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		for prefix := requestPath; prefix != ""; {
+			if matched, _ := path.Match(pattern, prefix); matched {
+				return true
+			}
+			i := strings.LastIndex(prefix, "/")
+			if i <= 0 {
+				break
+			}
+			prefix = prefix[:i]
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestMatchesPath(t *testing.T) {
+	patterns := []string{"/api/*", "/v2/users", "/health?"}
+	tests := []struct {
+		path     string
+		expected bool
+	}{
+		{"/api/users", true},
+		{"/api/users/1/orders", true},
+		{"/v2/users", true},
+		{"/v2/users/1", true},
+		{"/healthz", true},
+		{"/api", false},
+		{"/v2/orders", false},
+		{"/other/api/users", false},
+		{"/", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := matchesPath(patterns, tt.path); got != tt.expected {
+				t.Errorf("matchesPath(%q) = %v, want %v", tt.path, got, tt.expected)
+			}
+		})
+	}
+
+	if !matchesPath(nil, "/anything") {
+		t.Error("matchesPath() without patterns should match every path")
+	}
+}
+
+func TestServer_Paths(t *testing.T) {
+	tests := []struct {
+		name      string
+		target    string
+		formatted bool
+	}{
+		{name: "Matching path", target: "/api/users", formatted: true},
+		{name: "Path below a match", target: "/api/users/1", formatted: true},
+		{name: "Other path", target: "/metrics"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logBuf strings.Builder
+			log.SetOutput(&logBuf)
+			defer log.SetOutput(os.Stderr)
+
+			srv := New(8080, "go", false, false, WithPaths([]string{"/api/*"}))
+			req := httptest.NewRequest("POST", tt.target, strings.NewReader(`{"id":1}`))
+			req.Header.Set("Content-Type", "application/json")
+			rr := httptest.NewRecorder()
+			srv.handleRequest(rr, req)
+
+			if rr.Code != http.StatusOK {
+				t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+			}
+			if !strings.Contains(rr.Body.String(), "Request processed successfully") {
+				t.Errorf("response is not the acknowledgement\nGot: %s", rr.Body.String())
+			}
+			if got := strings.Contains(logBuf.String(), "Struct format:"); got != tt.formatted {
+				t.Errorf("formatted = %v, want %v\nLog: %s", got, tt.formatted, logBuf.String())
+			}
+		})
+	}
+}
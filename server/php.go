@@ -0,0 +1,149 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+)
+
+// phpReservedNames lists the reserved words that cannot be used as class
+// names, including the built-in type names.
+var phpReservedNames = map[string]bool{
+	"abstract": true, "and": true, "array": true, "as": true, "bool": true,
+	"break": true, "callable": true, "case": true, "catch": true, "class": true,
+	"clone": true, "const": true, "continue": true, "declare": true,
+	"default": true, "do": true, "echo": true, "else": true, "elseif": true,
+	"empty": true, "enum": true, "eval": true, "exit": true, "extends": true,
+	"false": true, "final": true, "finally": true, "float": true, "fn": true,
+	"for": true, "foreach": true, "function": true, "global": true, "goto": true,
+	"if": true, "implements": true, "include": true, "instanceof": true,
+	"insteadof": true, "int": true, "interface": true, "isset": true,
+	"iterable": true, "list": true, "match": true, "mixed": true,
+	"namespace": true, "never": true, "new": true, "null": true, "object": true,
+	"or": true, "parent": true, "print": true, "private": true,
+	"protected": true, "public": true, "readonly": true, "require": true,
+	"return": true, "self": true, "static": true, "string": true,
+	"switch": true, "throw": true, "trait": true, "true": true, "try": true,
+	"unset": true, "use": true, "var": true, "void": true, "while": true,
+	"xor": true, "yield": true,
+}
+
+// phpOpenTag starts PHP output, ahead of any generated code comment.
+const phpOpenTag = "<?php\n\n"
+
+func (s *Server) formatAsPHP(sch *schema) (string, error) {
+	root := sch
+	if sch.kind != kindObject {
+		root = &schema{kind: kindObject, fields: []*field{{name: "data", schema: sch}}}
+	}
+
+	types := s.nestedObjects(s.structName, root)
+	classes := make([]string, 0, len(types.objects))
+	for _, obj := range types.objects {
+		classes = append(classes, s.generatePHPClass(obj, types))
+	}
+	return phpOpenTag + strings.Join(classes, "\n\n"), nil
+}
+
+func (s *Server) generatePHPClass(obj *objectType, types *objectTypes) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "class %s\n{\n", phpClassName(obj.name))
+
+	used := make(map[string]bool)
+	for _, f := range obj.schema.fields {
+		name := phpPropertyName(f.name)
+		base := name
+		for n := 2; used[name]; n++ {
+			name = fmt.Sprintf("%s_%d", base, n)
+		}
+		used[name] = true
+
+		// Arrays lose their element type, so keep it in a docblock for
+		// static analysers
+		if f.schema.kind == kindArray {
+			fmt.Fprintf(&b, "    /** @var %s */\n", s.getPHPDocType(f.schema, types))
+		}
+		if name != f.name {
+			fmt.Fprintf(&b, "    // JSON key: %s\n", f.name)
+		}
+
+		propType := s.getPHPType(f.schema, types)
+		if f.optional || f.schema.nullable || f.schema.kind == kindNull {
+			fmt.Fprintf(&b, "    public %s $%s = null;\n", phpNullable(propType), name)
+		} else {
+			fmt.Fprintf(&b, "    public %s $%s;\n", propType, name)
+		}
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// getPHPType returns the property type of a schema, without nullability.
+func (s *Server) getPHPType(sch *schema, types *objectTypes) string {
+	switch sch.kind {
+	case kindBool:
+		return "bool"
+	case kindNumber:
+		return "float"
+	case kindString:
+		return "string"
+	case kindArray:
+		return "array"
+	case kindObject:
+		return phpClassName(types.name(sch))
+	default:
+		return "mixed"
+	}
+}
+
+// getPHPDocType returns the PHPDoc type of a schema, which unlike property
+// types can describe array elements.
+func (s *Server) getPHPDocType(sch *schema, types *objectTypes) string {
+	var docType string
+	switch sch.kind {
+	case kindArray:
+		elemType := "mixed"
+		if sch.elem != nil {
+			elemType = s.getPHPDocType(sch.elem, types)
+		}
+		if strings.Contains(elemType, "|") {
+			docType = fmt.Sprintf("array<%s>", elemType)
+		} else {
+			docType = elemType + "[]"
+		}
+	case kindNull:
+		return "null"
+	default:
+		docType = s.getPHPType(sch, types)
+	}
+	if sch.nullable && docType != "mixed" {
+		return docType + "|null"
+	}
+	return docType
+}
+
+// phpNullable makes a PHP property type nullable. mixed already includes null.
+func phpNullable(phpType string) string {
+	if phpType == "mixed" {
+		return phpType
+	}
+	return "?" + phpType
+}
+
+// phpClassName avoids reserved words, which PHP rejects as class names in
+// any letter case.
+func phpClassName(typeName string) string {
+	if phpReservedNames[strings.ToLower(typeName)] {
+		return typeName + "Type"
+	}
+	return typeName
+}
+
+// phpPropertyName turns a JSON key into a property name. Reserved words are
+// fine after the $ sigil, except for $this.
+func phpPropertyName(key string) string {
+	name := sanitizeIdentifier(key)
+	if name == "this" {
+		return "this_"
+	}
+	return name
+}
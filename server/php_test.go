@@ -0,0 +1,53 @@
+package server
+
+import "testing"
+
+func TestFormatAsPHP(t *testing.T) {
+	testFormatCases(t, "php", []formatCase{
+		{
+			name: "Flat object",
+			data: map[string]interface{}{"name": "test", "value": 123.0, "active": true},
+			expectContains: []string{
+				"<?php\n\nclass GeneratedStruct\n{\n    public bool $active;\n    public string $name;\n    public float $value;\n}",
+			},
+		},
+		{
+			name: "Nested objects, arrays and reserved words",
+			data: map[string]interface{}{
+				"user_info": map[string]interface{}{"full-name": "test"},
+				"tags":      []interface{}{"a", "b"},
+				"list":      map[string]interface{}{"id": 1.0},
+				"class":     "admin",
+			},
+			expectContains: []string{
+				"    public string $class;",
+				"    public ListType $list;",
+				"    /** @var string[] */\n    public array $tags;",
+				"    public UserInfo $user_info;",
+				"class ListType\n{",
+				"class UserInfo\n{\n    // JSON key: full-name\n    public string $full_name;\n}",
+			},
+		},
+		{
+			name: "Optional and null fields",
+			records: []interface{}{
+				map[string]interface{}{"id": 1.0, "email": nil, "tags": []interface{}{"a", nil}},
+				map[string]interface{}{"id": 2.0, "email": "x@example.com", "extra": nil},
+			},
+			expectContains: []string{
+				"    public ?string $email = null;",
+				"    public mixed $extra = null;",
+				"    public float $id;",
+				"    /** @var array<string|null> */\n    public ?array $tags = null;",
+			},
+		},
+		{
+			name: "Non-object root",
+			data: []interface{}{map[string]interface{}{"id": 1.0}},
+			expectContains: []string{
+				"    /** @var Data[] */\n    public array $data;",
+				"class Data\n{\n    public float $id;\n}",
+			},
+		},
+	})
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+)
+
+func (s *Server) formatAsPlantUML(sch *schema) (string, error) {
+	root := sch
+	if sch.kind != kindObject {
+		root = &schema{kind: kindObject, fields: []*field{{name: "data", schema: sch}}}
+	}
+
+	types := s.nestedObjects(s.structName, root)
+	var b strings.Builder
+	b.WriteString("@startuml\n")
+
+	var relations []string
+	seen := make(map[string]bool)
+	for _, obj := range types.objects {
+		fmt.Fprintf(&b, "class %s {\n", obj.name)
+		// Keys sanitized alike, such as "a b" and "a-b", need distinct members
+		names := keyNames(obj.schema.fields, sanitizeIdentifier)
+		for i, f := range obj.schema.fields {
+			fieldType := plantUMLType(f.schema, types)
+			if f.optional && !strings.HasSuffix(fieldType, "?") {
+				fieldType += "?"
+			}
+			fmt.Fprintf(&b, "    %s : %s\n", names[i], fieldType)
+
+			if relation := classRelation(obj.name, names[i], f, types); relation != "" && !seen[relation] {
+				seen[relation] = true
+				relations = append(relations, relation)
+			}
+		}
+		b.WriteString("}\n")
+	}
+	for _, relation := range relations {
+		fmt.Fprintf(&b, "%s\n", relation)
+	}
+	b.WriteString("@enduml")
+	return b.String(), nil
+}
+
+// plantUMLType names a schema in class members, with a question mark for
+// nullable values.
+func plantUMLType(sch *schema, types *objectTypes) string {
+	var name string
+	switch sch.kind {
+	case kindBool:
+		name = "Boolean"
+	case kindNumber:
+		name = "Number"
+	case kindString:
+		name = "String"
+	case kindArray:
+		elemType := "Any"
+		if sch.elem != nil {
+			elemType = plantUMLType(sch.elem, types)
+		}
+		name = fmt.Sprintf("List<%s>", elemType)
+	case kindObject:
+		name = types.name(sch)
+	case kindNull:
+		return "Any?"
+	default:
+		name = "Any"
+	}
+	if sch.nullable {
+		return name + "?"
+	}
+	return name
+}
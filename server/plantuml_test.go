@@ -0,0 +1,67 @@
+package server
+
+import "testing"
+
+func TestFormatAsPlantUML(t *testing.T) {
+	testFormatCases(t, "plantuml", []formatCase{
+		{
+			name: "Flat object",
+			data: map[string]interface{}{"name": "test", "value": 123.0, "active": true},
+			expectContains: []string{
+				"@startuml\nclass GeneratedStruct {\n" +
+					"    active : Boolean\n" +
+					"    name : String\n" +
+					"    value : Number\n" +
+					"}\n@enduml",
+			},
+		},
+		{
+			name: "Nested objects and arrays",
+			data: map[string]interface{}{
+				"user-info": map[string]interface{}{"id": 1.0},
+				"items":     []interface{}{map[string]interface{}{"sku": "a"}},
+				"tags":      []interface{}{"a"},
+			},
+			expectContains: []string{
+				"    items : List<Items>\n",
+				"    tags : List<String>\n",
+				"    user_info : UserInfo\n",
+				"class UserInfo {\n    id : Number\n}\n",
+				"GeneratedStruct \"1\" *-- \"*\" Items : items\n",
+				"GeneratedStruct *-- UserInfo : user_info\n@enduml",
+			},
+		},
+		{
+			name: "Optional and null fields",
+			records: []interface{}{
+				map[string]interface{}{"id": 1.0, "email": nil, "note": nil},
+				map[string]interface{}{"id": 2.0, "email": "x@example.com"},
+			},
+			expectContains: []string{
+				"    email : String?\n",
+				"    id : Number\n",
+				"    note : Any?\n",
+			},
+		},
+		{
+			name: "Keys that are not identifiers",
+			data: map[string]interface{}{"2fa": "x", "a b": "y"},
+			expectContains: []string{
+				"    _2fa : String\n",
+				"    a_b : String\n",
+			},
+		},
+		{
+			name: "Colliding keys",
+			data: map[string]interface{}{"a b": "x", "a-b": "y", "a_b": 1.0, "e": "z", "é": "w"},
+			expectContains: []string{
+				"    a_b2 : String\n    a_b3 : String\n    a_b : Number\n    e : String\n    e2 : String\n",
+			},
+		},
+		{
+			name:           "Non-object root",
+			data:           []interface{}{1.0},
+			expectContains: []string{"    data : List<Number>\n"},
+		},
+	})
+}
@@ -0,0 +1,52 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// poolQueueTimeout is how long a request waits for a free worker before it is
+// turned away.
+const poolQueueTimeout = time.Second
+
+// workerPool bounds how many requests are formatted at once. Requests over the
+// limit queue briefly, then get 503 Service Unavailable.
+type workerPool struct {
+	slots chan struct{}
+}
+
+func newWorkerPool(size int) *workerPool {
+	return &workerPool{slots: make(chan struct{}, size)}
+}
+
+// acquire waits for a free worker, reporting false when none frees up within
+// poolQueueTimeout or the request is cancelled first.
+func (p *workerPool) acquire(ctx context.Context) bool {
+	select {
+	case p.slots <- struct{}{}:
+		return true
+	default:
+	}
+
+	timer := time.NewTimer(poolQueueTimeout)
+	defer timer.Stop()
+	select {
+	case p.slots <- struct{}{}:
+		return true
+	case <-timer.C:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (p *workerPool) release() {
+	<-p.slots
+}
+
+// busy replies that every worker is taken.
+func busy(w http.ResponseWriter) {
+	w.Header().Set("Retry-After", "1")
+	writeJSONError(w, errBusy)
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServer_Concurrency(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		method     string
+		cancel     bool
+		releaseIn  time.Duration
+		expectCode int
+	}{
+		{
+			name:       "Queued until a worker frees up",
+			path:       "/api/data",
+			method:     "POST",
+			releaseIn:  50 * time.Millisecond,
+			expectCode: http.StatusOK,
+		},
+		{
+			name:       "Rejected when no worker frees up",
+			path:       "/api/data",
+			method:     "POST",
+			cancel:     true,
+			expectCode: http.StatusServiceUnavailable,
+		},
+		{
+			name:       "Format endpoint rejected when no worker frees up",
+			path:       "/format?lang=go",
+			method:     "POST",
+			cancel:     true,
+			expectCode: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := New(8080, "", false, false, WithQuiet(true), WithConcurrency(1))
+			handler := srv.httpServer().Handler
+
+			// Take the only worker
+			if !srv.pool.acquire(context.Background()) {
+				t.Fatal("acquire() on an idle pool = false")
+			}
+			if tt.releaseIn > 0 {
+				time.AfterFunc(tt.releaseIn, srv.pool.release)
+			}
+
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(`{"a":1}`))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.cancel {
+				ctx, cancel := context.WithCancel(req.Context())
+				cancel()
+				req = req.WithContext(ctx)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectCode {
+				t.Errorf("handler returned wrong status code: got %v want %v", rr.Code, tt.expectCode)
+			}
+			if rr.Code == http.StatusServiceUnavailable && rr.Header().Get("Retry-After") == "" {
+				t.Error("503 response without Retry-After")
+			}
+		})
+	}
+}
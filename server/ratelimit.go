@@ -0,0 +1,93 @@
+package server
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// clientIdleTimeout is how long a per-IP limiter is kept after its client's
+// last request.
+const clientIdleTimeout = time.Minute
+
+// rateLimiter rejects requests beyond a rate, either across all clients or
+// per remote IP.
+type rateLimiter struct {
+	limit rate.Limit
+	burst int
+	perIP bool
+
+	mu        sync.Mutex
+	global    *rate.Limiter
+	clients   map[string]*clientLimiter
+	lastPrune time.Time
+}
+
+type clientLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// newRateLimiter allows requestsPerSecond requests, with bursts of the same
+// size.
+func newRateLimiter(requestsPerSecond float64, perIP bool) *rateLimiter {
+	burst := int(requestsPerSecond)
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{
+		limit:   rate.Limit(requestsPerSecond),
+		burst:   burst,
+		perIP:   perIP,
+		global:  rate.NewLimiter(rate.Limit(requestsPerSecond), burst),
+		clients: make(map[string]*clientLimiter),
+	}
+}
+
+// allow reports whether a request from the given remote address may proceed.
+func (l *rateLimiter) allow(remoteAddr string) bool {
+	if !l.perIP {
+		return l.global.Allow()
+	}
+
+	ip, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		ip = remoteAddr
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.lastPrune) > clientIdleTimeout {
+		for key, client := range l.clients {
+			if now.Sub(client.lastSeen) > clientIdleTimeout {
+				delete(l.clients, key)
+			}
+		}
+		l.lastPrune = now
+	}
+
+	client, ok := l.clients[ip]
+	if !ok {
+		client = &clientLimiter{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.clients[ip] = client
+	}
+	client.lastSeen = now
+	return client.limiter.Allow()
+}
+
+// middleware replies 429 Too Many Requests once the rate is exceeded.
+func (l *rateLimiter) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !l.allow(r.RemoteAddr) {
+			w.Header().Set("Retry-After", "1")
+			writeJSONError(w, errRateLimited)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestServer_RateLimit(t *testing.T) {
+	tests := []struct {
+		name        string
+		perIP       bool
+		remoteAddrs []string
+		expectCodes []int
+	}{
+		{
+			name:        "Burst across all clients",
+			remoteAddrs: []string{"10.0.0.1:1000", "10.0.0.1:1001", "10.0.0.2:1000", "10.0.0.2:1001"},
+			expectCodes: []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests, http.StatusTooManyRequests},
+		},
+		{
+			name:        "Burst per IP",
+			perIP:       true,
+			remoteAddrs: []string{"10.0.0.1:1000", "10.0.0.1:1001", "10.0.0.1:1002", "10.0.0.2:1000"},
+			expectCodes: []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests, http.StatusOK},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := New(8080, "", false, false, WithQuiet(true), WithRateLimit(2, tt.perIP), WithOpenAPISpec(true))
+			handler := srv.httpServer().Handler
+
+			for i, remoteAddr := range tt.remoteAddrs {
+				req := httptest.NewRequest("GET", "/api/data", nil)
+				req.RemoteAddr = remoteAddr
+				rr := httptest.NewRecorder()
+				handler.ServeHTTP(rr, req)
+
+				if rr.Code != tt.expectCodes[i] {
+					t.Errorf("Request %d from %s: got status %v want %v", i, remoteAddr, rr.Code, tt.expectCodes[i])
+				}
+				if rr.Code == http.StatusTooManyRequests && rr.Header().Get("Retry-After") == "" {
+					t.Errorf("Request %d: 429 response without Retry-After", i)
+				}
+			}
+
+			// Version checks and the format list are never limited, while
+			// the other endpoints share the limit
+			for path, expectCode := range map[string]int{
+				"/version":      http.StatusOK,
+				"/formats":      http.StatusOK,
+				"/openapi.json": http.StatusTooManyRequests,
+			} {
+				req := httptest.NewRequest("GET", path, nil)
+				req.RemoteAddr = tt.remoteAddrs[0]
+				rr := httptest.NewRecorder()
+				handler.ServeHTTP(rr, req)
+				if rr.Code != expectCode {
+					t.Errorf("%s got status %v want %v", path, rr.Code, expectCode)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+)
+
+// formatAsRBI generates a Sorbet signature file for the plain Ruby Structs of
+// formatAsRuby, typing the keyword initializer and every accessor.
+func (s *Server) formatAsRBI(sch *schema) (string, error) {
+	root := sch
+	if sch.kind != kindObject {
+		root = &schema{kind: kindObject, fields: []*field{{name: "data", schema: sch}}}
+	}
+
+	types := s.nestedObjects(s.structName, root)
+	classes := make([]string, 0, len(types.objects))
+	for _, obj := range childrenFirst(types) {
+		classes = append(classes, s.generateRBIClass(obj, types))
+	}
+	return "# typed: strict\n\n" + strings.Join(classes, "\n\n"), nil
+}
+
+func (s *Server) generateRBIClass(obj *objectType, types *objectTypes) string {
+	names := rubyMemberNames(obj.schema.fields)
+	fieldTypes := make([]string, len(names))
+	params := make([]string, len(names))
+	args := make([]string, len(names))
+	for i, f := range obj.schema.fields {
+		fieldTypes[i] = s.getSorbetType(f.schema, types)
+		if f.optional {
+			fieldTypes[i] = sorbetNilable(fieldTypes[i])
+		}
+		params[i] = fmt.Sprintf("%s: %s", names[i], fieldTypes[i])
+		// Struct leaves keys missing from the initializer nil
+		args[i] = names[i] + ":"
+		if f.optional {
+			args[i] += " nil"
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "class %s < Struct\n", rubyClassName(obj.name))
+	if len(names) > 0 {
+		fmt.Fprintf(&b, "  sig { params(%s).void }\n", strings.Join(params, ", "))
+		fmt.Fprintf(&b, "  def initialize(%s); end\n", strings.Join(args, ", "))
+	}
+	for i, name := range names {
+		fmt.Fprintf(&b, "\n  sig { returns(%s) }\n", fieldTypes[i])
+		fmt.Fprintf(&b, "  def %s; end\n", name)
+		fmt.Fprintf(&b, "\n  sig { params(%s: %s).returns(%s) }\n", name, fieldTypes[i], fieldTypes[i])
+		fmt.Fprintf(&b, "  def %s=(%s); end\n", name, name)
+	}
+	b.WriteString("end")
+	return b.String()
+}
@@ -0,0 +1,59 @@
+package server
+
+import "testing"
+
+func TestFormatAsRBI(t *testing.T) {
+	testFormatCases(t, "rbi", []formatCase{
+		{
+			name: "Flat object",
+			data: map[string]interface{}{"name": "test", "value": 123.0, "active": true},
+			expectContains: []string{
+				"# typed: strict\n\nclass GeneratedStruct < Struct\n" +
+					"  sig { params(active: T::Boolean, name: String, value: Float).void }\n" +
+					"  def initialize(active:, name:, value:); end\n" +
+					"\n  sig { returns(T::Boolean) }\n" +
+					"  def active; end\n" +
+					"\n  sig { params(active: T::Boolean).returns(T::Boolean) }\n" +
+					"  def active=(active); end\n",
+				"  sig { returns(Float) }\n  def value; end\n",
+			},
+		},
+		{
+			name: "Nested objects and arrays",
+			data: map[string]interface{}{
+				"userInfo": map[string]interface{}{"id": 1.0},
+				"tags":     []interface{}{"a"},
+				"empty":    []interface{}{},
+			},
+			expectContains: []string{
+				"class UserInfo < Struct\n",
+				"  sig { returns(UserInfo) }\n  def user_info; end\n",
+				"  sig { returns(T::Array[String]) }\n  def tags; end\n",
+				"  sig { returns(T::Array[T.untyped]) }\n  def empty; end\n",
+			},
+		},
+		{
+			name: "Optional and null fields",
+			records: []interface{}{
+				map[string]interface{}{"id": 1.0, "email": nil, "class": "a"},
+				map[string]interface{}{"id": 2.0, "email": "x@example.com"},
+			},
+			expectContains: []string{
+				"  def initialize(class_: nil, email:, id:); end\n",
+				"  sig { returns(T.nilable(String)) }\n  def class_; end\n",
+				"  sig { returns(T.nilable(String)) }\n  def email; end\n",
+			},
+		},
+		{
+			name:           "Empty object",
+			data:           map[string]interface{}{},
+			expectContains: []string{"class GeneratedStruct < Struct\nend"},
+			expectMissing:  []string{"initialize"},
+		},
+		{
+			name:           "Non-object root",
+			data:           []interface{}{1.0},
+			expectContains: []string{"  sig { returns(T::Array[Float]) }\n  def data; end\n"},
+		},
+	})
+}
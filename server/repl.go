@@ -0,0 +1,131 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+)
+
+const (
+	replPrompt             = "> "
+	replContinuationPrompt = "... "
+)
+
+// replHelp describes the meta-commands of the REPL.
+const replHelp = `Paste a JSON value, over as many lines as needed, to see the code generated for it.
+Commands:
+  :format           show the current format
+  :format <name>    switch to another format
+  :name <Name>      rename the generated root type
+  :help             show this help
+  :quit             exit (as does end of input)
+`
+
+// RunREPL reads JSON values from in and writes the code generated for each to
+// out, prompting for the next one, without starting the server. A value may
+// span several lines; input is buffered until it forms a complete value.
+// Lines starting with a colon are meta-commands, such as ":format rust".
+// The REPL defaults to Go output when the server has no format.
+func (s *Server) RunREPL(in io.Reader, out io.Writer) error {
+	repl := *s
+	if repl.formatType == "" {
+		repl.formatType = "go"
+	}
+	// Warnings such as truncated nesting are shown inline
+	logger := log.New(out, "", 0)
+
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	var pending bytes.Buffer
+	for {
+		if pending.Len() == 0 {
+			fmt.Fprint(out, replPrompt)
+		} else {
+			fmt.Fprint(out, replContinuationPrompt)
+		}
+		if !scanner.Scan() {
+			break
+		}
+		line := scanner.Text()
+
+		if pending.Len() == 0 {
+			trimmed := strings.TrimSpace(line)
+			if trimmed == "" {
+				continue
+			}
+			if strings.HasPrefix(trimmed, ":") {
+				if quit := repl.replCommand(out, trimmed); quit {
+					return nil
+				}
+				continue
+			}
+		}
+
+		pending.WriteString(line)
+		pending.WriteByte('\n')
+		var value interface{}
+		if err := json.Unmarshal(pending.Bytes(), &value); isIncompleteJSON(err) {
+			continue
+		} else if err != nil {
+			fmt.Fprintf(out, "Error parsing JSON: %s\n", describeJSONError(pending.Bytes(), err))
+			pending.Reset()
+			continue
+		}
+
+		formatted, err := repl.generateRecords(logger, "application/json", pending.Bytes(), []interface{}{value}, "REPL input")
+		pending.Reset()
+		if err != nil {
+			fmt.Fprintf(out, "Error formatting data: %v\n", err)
+			continue
+		}
+		if repl.color {
+			formatted = colorize(repl.formatType, formatted)
+		}
+		fmt.Fprintln(out, formatted)
+	}
+	fmt.Fprintln(out)
+	return scanner.Err()
+}
+
+// replCommand runs a meta-command, reporting whether the REPL should exit.
+func (s *Server) replCommand(out io.Writer, command string) bool {
+	name, arg, _ := strings.Cut(strings.TrimPrefix(command, ":"), " ")
+	arg = strings.TrimSpace(arg)
+	switch name {
+	case "format":
+		if arg == "" {
+			fmt.Fprintf(out, "Format: %s\n", s.formatType)
+		} else if !IsFormat(arg) {
+			fmt.Fprintf(out, "Unknown format %q. Formats are: %s\n", arg, strings.Join(FormatNames(), ", "))
+		} else {
+			s.formatType = arg
+			fmt.Fprintf(out, "Format: %s\n", arg)
+		}
+	case "name":
+		if !isIdentifier(arg) {
+			fmt.Fprintf(out, "Invalid name %q: not an identifier\n", arg)
+		} else {
+			s.structName = arg
+			fmt.Fprintf(out, "Name: %s\n", arg)
+		}
+	case "help":
+		fmt.Fprint(out, replHelp)
+	case "quit", "q", "exit":
+		return true
+	default:
+		fmt.Fprintf(out, "Unknown command :%s, see :help\n", name)
+	}
+	return false
+}
+
+// isIncompleteJSON reports whether err means the input ended before the JSON
+// value did, so more lines are needed.
+func isIncompleteJSON(err error) bool {
+	var syntaxErr *json.SyntaxError
+	return errors.As(err, &syntaxErr) && syntaxErr.Error() == "unexpected end of JSON input"
+}
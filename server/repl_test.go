@@ -0,0 +1,90 @@
+package server
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestServer_RunREPL(t *testing.T) {
+	tests := []struct {
+		name           string
+		formatType     string
+		input          string
+		expectContains []string
+		expectMissing  []string
+	}{
+		{
+			name:  "Defaults to Go, one value per line",
+			input: "{\"a\":1}\n\n{\"b\":\"x\"}\n",
+			expectContains: []string{
+				"> type GeneratedStruct struct {\n    a float64 `json:\"a\"`\n}\n",
+				"> type GeneratedStruct struct {\n    b string `json:\"b\"`\n}\n",
+			},
+		},
+		{
+			name:       "Values spanning several lines",
+			formatType: "rust",
+			input:      "{\n  \"tags\": [\n    \"a\"\n  ]\n}\n",
+			expectContains: []string{
+				"> ... ... ... ... #[derive(Debug, Serialize, Deserialize)]\nstruct GeneratedStruct {\n    tags: Vec<String>,\n}\n",
+			},
+		},
+		{
+			name:  "Switching format and name",
+			input: ":format\n:format typeddict\n:name Order\n{\"id\":1}\n",
+			expectContains: []string{
+				"Format: go\n",
+				"Format: typeddict\n",
+				"Name: Order\n",
+				"class Order(TypedDict):\n    id: float\n",
+			},
+		},
+		{
+			name:  "Invalid input keeps the loop going",
+			input: ":format cobol\n:name 1x\n:bogus\n{bad\n{\"ok\":true}\n",
+			expectContains: []string{
+				`Unknown format "cobol". Formats are: go, rust,`,
+				`Invalid name "1x": not an identifier`,
+				"Unknown command :bogus, see :help",
+				`Error parsing JSON: invalid character 'b' looking for beginning of object key string at offset 2`,
+				"ok bool `json:\"ok\"`",
+			},
+		},
+		{
+			name:           "Quit stops reading",
+			input:          ":help\n:quit\n{\"a\":1}\n",
+			expectContains: []string{":format <name>    switch to another format"},
+			expectMissing:  []string{"GeneratedStruct"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := New(8080, tt.formatType, false, false)
+			var out strings.Builder
+			if err := srv.RunREPL(strings.NewReader(tt.input), &out); err != nil {
+				t.Fatalf("RunREPL() error = %v", err)
+			}
+
+			for _, expect := range tt.expectContains {
+				if !strings.Contains(out.String(), expect) {
+					t.Errorf("RunREPL() output does not contain expected string: %s\nGot: %s", expect, out.String())
+				}
+			}
+			for _, missing := range tt.expectMissing {
+				if strings.Contains(out.String(), missing) {
+					t.Errorf("RunREPL() output contains unexpected string: %s\nGot: %s", missing, out.String())
+				}
+			}
+		})
+	}
+
+	// The REPL switches formats on its own copy of the server
+	srv := New(8080, "go", false, false)
+	if err := srv.RunREPL(strings.NewReader(":format rust\n"), &strings.Builder{}); err != nil {
+		t.Fatalf("RunREPL() error = %v", err)
+	}
+	if srv.formatType != "go" {
+		t.Errorf("RunREPL() changed the server format to %s", srv.formatType)
+	}
+}
@@ -0,0 +1,45 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+// Replay generates one type for the request bodies captured in files, such
+// as those written with -capture, merging their schemas like the records of
+// an NDJSON body. Files are read as newline-delimited JSON, or as JSON text
+// sequences when they contain record separators.
+func (s *Server) Replay(paths []string) (string, error) {
+	var records []interface{}
+	var bodies [][]byte
+	mediaType := "application/x-ndjson"
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		var captured []interface{}
+		if bytes.IndexByte(data, recordSeparator) >= 0 {
+			mediaType = "application/json-seq"
+			captured, err = decodeJSONSeq(data)
+			if err != nil {
+				return "", fmt.Errorf("%s: invalid JSON text sequence: %w", path, err)
+			}
+		} else {
+			captured, err = decodeNDJSON(bytes.NewReader(data))
+			if err != nil {
+				return "", fmt.Errorf("%s: invalid JSON: %s", path, describeJSONError(data, err))
+			}
+		}
+		records = append(records, captured...)
+		bodies = append(bodies, data)
+		s.infof(log.Default(), "Replaying %s: %d JSON records found", path, len(captured))
+	}
+	if len(records) == 0 {
+		return "", fmt.Errorf("no JSON records found in %s", strings.Join(paths, ", "))
+	}
+	return s.generateRecords(log.Default(), mediaType, bytes.Join(bodies, []byte("\n")), records, "replay of "+strings.Join(paths, ", "))
+}
@@ -0,0 +1,119 @@
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCapture(t *testing.T) {
+	var captured bytes.Buffer
+	srv := New(8080, "", false, false, WithQuiet(true), WithCapture(&captured))
+
+	bodies := []struct {
+		contentType string
+		body        string
+	}{
+		{contentType: "application/json", body: "{\n  \"id\": 1,\n  \"html\": \"<b>\"\n}"},
+		{contentType: "application/x-ndjson", body: "{\"id\":2}\n{\"id\":3}\n"},
+		{contentType: "text/plain", body: "not captured"},
+		{contentType: "application/json", body: "{\"id\":"},
+	}
+	for _, b := range bodies {
+		req := httptest.NewRequest(http.MethodPost, "/api", strings.NewReader(b.body))
+		req.Header.Set("Content-Type", b.contentType)
+		srv.handleRequest(httptest.NewRecorder(), req)
+	}
+
+	want := "{\"html\":\"<b>\",\"id\":1}\n{\"id\":2}\n{\"id\":3}\n"
+	if captured.String() != want {
+		t.Errorf("captured %q, want %q", captured.String(), want)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	dir := t.TempDir()
+	older := filepath.Join(dir, "bodies.ndjson.1")
+	current := filepath.Join(dir, "bodies.ndjson")
+	seq := filepath.Join(dir, "bodies.json-seq")
+	empty := filepath.Join(dir, "empty.ndjson")
+	broken := filepath.Join(dir, "broken.ndjson")
+	files := map[string]string{
+		older:   "{\"id\":1,\"name\":\"a\"}\n",
+		current: "{\"id\":2}\n",
+		seq:     "\x1e{\"id\":1,\"name\":\"a\"}\n\x1e{\"id\":2}\n",
+		empty:   "",
+		broken:  "{\"id\":2}\n{\"id\":\n",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name           string
+		paths          []string
+		expectContains []string
+		expectErr      string
+	}{
+		{
+			name:  "Rotated captures",
+			paths: []string{older, current},
+			expectContains: []string{
+				"// Code generated by reqparser from replay of " + older + ", " + current,
+				"id float64 `json:\"id\"`",
+				"name *string `json:\"name,omitempty\"`",
+			},
+		},
+		{
+			name:  "JSON text sequence",
+			paths: []string{seq},
+			expectContains: []string{
+				"id float64 `json:\"id\"`",
+				"name *string `json:\"name,omitempty\"`",
+			},
+		},
+		{
+			name:      "No records",
+			paths:     []string{empty},
+			expectErr: "no JSON records found in " + empty,
+		},
+		{
+			name:      "Invalid record",
+			paths:     []string{broken},
+			expectErr: broken + ": invalid JSON",
+		},
+		{
+			name:      "Missing capture",
+			paths:     []string{filepath.Join(dir, "missing.ndjson")},
+			expectErr: "no such file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := New(8080, "go", false, false, WithQuiet(true), WithGeneratedHeader(true))
+			result, err := srv.Replay(tt.paths)
+			if tt.expectErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectErr) {
+					t.Fatalf("Replay() error = %v, want %q", err, tt.expectErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Replay() error = %v", err)
+			}
+
+			for _, expect := range tt.expectContains {
+				if !strings.Contains(result, expect) {
+					t.Errorf("Replay() result does not contain expected string: %s\nGot: %s", expect, result)
+				}
+			}
+		})
+	}
+}
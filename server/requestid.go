@@ -0,0 +1,45 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"net/http"
+)
+
+// maxRequestIDLength caps the length of client supplied request IDs.
+const maxRequestIDLength = 128
+
+// requestID returns the X-Request-ID sent by the client, or a new short random
+// ID when the header is missing or unsafe to log.
+func requestID(r *http.Request) string {
+	if id := r.Header.Get("X-Request-ID"); isSafeRequestID(id) {
+		return id
+	}
+
+	buf := make([]byte, 4)
+	if _, err := rand.Read(buf); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(buf)
+}
+
+// isSafeRequestID rejects empty, overly long or non-printable IDs so a client
+// cannot forge log lines.
+func isSafeRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		if r < 0x21 || r > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// requestLogger returns a logger that prefixes every message with the request
+// ID, writing to the standard logger's current output.
+func requestLogger(id string) *log.Logger {
+	return log.New(log.Writer(), log.Prefix()+"["+id+"] ", log.Flags()|log.Lmsgprefix)
+}
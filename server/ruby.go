@@ -0,0 +1,150 @@
+package server
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// rubyKeywords lists the reserved words avoided as member names. Most are
+// legal as method names, but accessors such as class would shadow core
+// methods.
+var rubyKeywords = map[string]bool{
+	"alias": true, "and": true, "begin": true, "break": true, "case": true,
+	"class": true, "def": true, "do": true, "else": true,
+	"elsif": true, "end": true, "ensure": true, "false": true, "for": true,
+	"if": true, "in": true, "module": true, "next": true, "nil": true,
+	"not": true, "or": true, "redo": true, "rescue": true, "retry": true,
+	"return": true, "self": true, "super": true, "then": true, "true": true,
+	"undef": true, "unless": true, "until": true, "when": true, "while": true,
+	"yield": true,
+}
+
+// rubyReservedConstants lists class names that would shadow the constants
+// generated Sorbet code relies on.
+var rubyReservedConstants = map[string]bool{
+	"Array": true, "Float": true, "Hash": true, "Integer": true, "Object": true,
+	"String": true, "Struct": true, "T": true,
+}
+
+// sorbetPreamble starts Ruby output typed with Sorbet.
+const sorbetPreamble = "# typed: strict\n\nrequire \"sorbet-runtime\"\n\n"
+
+func (s *Server) formatAsRuby(sch *schema) (string, error) {
+	root := sch
+	if sch.kind != kindObject {
+		root = &schema{kind: kindObject, fields: []*field{{name: "data", schema: sch}}}
+	}
+
+	// Ruby evaluates class bodies in order, so emit children first
+	types := s.nestedObjects(s.structName, root)
+	classes := make([]string, 0, len(types.objects))
+	for _, obj := range childrenFirst(types) {
+		if s.rubySorbet {
+			classes = append(classes, s.generateSorbetStruct(obj, types))
+		} else {
+			classes = append(classes, generateRubyStruct(obj))
+		}
+	}
+
+	header := ""
+	if s.rubySorbet {
+		header = sorbetPreamble
+	}
+	return header + strings.Join(classes, "\n\n"), nil
+}
+
+func generateRubyStruct(obj *objectType) string {
+	names := rubyMemberNames(obj.schema.fields)
+	members := make([]string, 0, len(names)+1)
+	for _, name := range names {
+		members = append(members, ":"+name)
+	}
+	members = append(members, "keyword_init: true")
+	return fmt.Sprintf("%s = Struct.new(%s)", rubyClassName(obj.name), strings.Join(members, ", "))
+}
+
+func (s *Server) generateSorbetStruct(obj *objectType, types *objectTypes) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "class %s < T::Struct\n", rubyClassName(obj.name))
+
+	names := rubyMemberNames(obj.schema.fields)
+	for i, f := range obj.schema.fields {
+		fieldType := s.getSorbetType(f.schema, types)
+		if f.optional {
+			fieldType = sorbetNilable(fieldType)
+		}
+		var key string
+		if names[i] != f.name {
+			key = ", name: " + strconv.Quote(f.name)
+		}
+		fmt.Fprintf(&b, "  const :%s, %s%s\n", names[i], fieldType, key)
+	}
+	b.WriteString("end")
+	return b.String()
+}
+
+func (s *Server) getSorbetType(sch *schema, types *objectTypes) string {
+	var sorbetType string
+	switch sch.kind {
+	case kindBool:
+		sorbetType = "T::Boolean"
+	case kindNumber:
+		sorbetType = "Float"
+	case kindString:
+		sorbetType = "String"
+	case kindArray:
+		elemType := "T.untyped"
+		if sch.elem != nil {
+			elemType = s.getSorbetType(sch.elem, types)
+		}
+		sorbetType = fmt.Sprintf("T::Array[%s]", elemType)
+	case kindObject:
+		sorbetType = rubyClassName(types.name(sch))
+	default:
+		return "T.untyped"
+	}
+	if sch.nullable {
+		return sorbetNilable(sorbetType)
+	}
+	return sorbetType
+}
+
+// sorbetNilable makes a Sorbet type nilable. T.untyped already accepts nil.
+func sorbetNilable(sorbetType string) string {
+	if sorbetType == "T.untyped" || strings.HasPrefix(sorbetType, "T.nilable(") {
+		return sorbetType
+	}
+	return fmt.Sprintf("T.nilable(%s)", sorbetType)
+}
+
+// rubyMemberNames converts the keys of an object into distinct snake_case
+// member names.
+func rubyMemberNames(fields []*field) []string {
+	names := make([]string, len(fields))
+	used := make(map[string]bool)
+	for i, f := range fields {
+		name := sanitizeIdentifier(toSnakeCase(f.name))
+		if name == "_" {
+			name = "field"
+		}
+		if rubyKeywords[name] {
+			name += "_"
+		}
+		base := name
+		for n := 2; used[name]; n++ {
+			name = fmt.Sprintf("%s_%d", base, n)
+		}
+		used[name] = true
+		names[i] = name
+	}
+	return names
+}
+
+// rubyClassName renames classes that would shadow core constants.
+func rubyClassName(name string) string {
+	if rubyReservedConstants[name] {
+		return name + "Type"
+	}
+	return name
+}
@@ -0,0 +1,62 @@
+package server
+
+import "testing"
+
+func TestFormatAsRuby(t *testing.T) {
+	testFormatCases(t, "ruby", []formatCase{
+		{
+			name: "Plain structs",
+			data: map[string]interface{}{
+				"userName":  "test",
+				"class":     "admin",
+				"user_info": map[string]interface{}{"id": 1.0},
+			},
+			expectContains: []string{
+				"UserInfo = Struct.new(:id, keyword_init: true)\n\nGeneratedStruct = Struct.new(:class_, :user_name, :user_info, keyword_init: true)",
+			},
+		},
+		{
+			name: "Sorbet structs",
+			opts: []Option{WithRubySorbet(true)},
+			data: map[string]interface{}{
+				"name":     "test",
+				"value":    123.0,
+				"active":   true,
+				"tags":     []interface{}{"a", "b"},
+				"userInfo": map[string]interface{}{"id": 1.0},
+				"t":        map[string]interface{}{"x": "y"},
+				"anything": []interface{}{1.0, "a"},
+			},
+			expectContains: []string{
+				"# typed: strict\n\nrequire \"sorbet-runtime\"",
+				"class TType < T::Struct\n  const :x, String\nend",
+				"class UserInfo < T::Struct\n  const :id, Float\nend",
+				"class GeneratedStruct < T::Struct\n",
+				"  const :active, T::Boolean\n",
+				"  const :anything, T::Array[T.untyped]\n",
+				"  const :t, TType\n",
+				"  const :tags, T::Array[String]\n",
+				"  const :user_info, UserInfo, name: \"userInfo\"\n",
+			},
+		},
+		{
+			name: "Sorbet optional and null fields",
+			opts: []Option{WithRubySorbet(true)},
+			records: []interface{}{
+				map[string]interface{}{"id": 1.0, "email": nil, "note": nil},
+				map[string]interface{}{"id": 2.0, "email": "x@example.com"},
+			},
+			expectContains: []string{
+				"  const :email, T.nilable(String)\n",
+				"  const :id, Float\n",
+				"  const :note, T.untyped\n",
+			},
+		},
+		{
+			name:           "Non-object root",
+			data:           []interface{}{"a"},
+			opts:           []Option{WithRubySorbet(true)},
+			expectContains: []string{"  const :data, T::Array[String]\n"},
+		},
+	})
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+)
+
+// scalaKeywords lists the reserved words that must be escaped with backticks
+// when used as parameter names.
+var scalaKeywords = map[string]bool{
+	"abstract": true, "case": true, "catch": true, "class": true, "def": true,
+	"do": true, "else": true, "extends": true, "false": true, "final": true,
+	"finally": true, "for": true, "forSome": true, "if": true, "implicit": true,
+	"import": true, "lazy": true, "match": true, "new": true, "null": true,
+	"object": true, "override": true, "package": true, "private": true,
+	"protected": true, "return": true, "sealed": true, "super": true,
+	"this": true, "throw": true, "trait": true, "true": true, "try": true,
+	"type": true, "val": true, "var": true, "while": true, "with": true,
+	"yield": true, "given": true, "enum": true, "export": true, "then": true,
+}
+
+// scalaBuiltinTypes lists the scala and Predef types that generated fields
+// use, which a case class of the same name would shadow.
+var scalaBuiltinTypes = map[string]bool{
+	"Any": true, "Boolean": true, "Double": true, "Int": true, "List": true,
+	"Long": true, "Map": true, "Option": true, "Seq": true, "String": true,
+}
+
+func (s *Server) formatAsScala(sch *schema) (string, error) {
+	root := sch
+	if sch.kind != kindObject {
+		root = &schema{kind: kindObject, fields: []*field{{name: "data", schema: sch}}}
+	}
+
+	types := s.nestedObjects(s.structName, root)
+	classes := make([]string, 0, len(types.objects))
+	for _, obj := range types.objects {
+		classes = append(classes, fmt.Sprintf("case class %s(\n%s\n)", obj.name, s.generateScalaFields(obj.schema, types)))
+	}
+	return strings.Join(classes, "\n\n"), nil
+}
+
+func (s *Server) generateScalaFields(sch *schema, types *objectTypes) string {
+	params := make([]string, 0, len(sch.fields))
+	names := keyNames(sch.fields, scalaFieldName)
+	for i, f := range sch.fields {
+		fieldType := s.getScalaType(f.schema, types)
+		if f.optional {
+			fieldType = scalaOption(fieldType)
+		}
+		params = append(params, fmt.Sprintf("    %s: %s", scalaIdentifier(names[i]), fieldType))
+	}
+	return strings.Join(params, ",\n")
+}
+
+func (s *Server) getScalaType(sch *schema, types *objectTypes) string {
+	var scalaType string
+	switch sch.kind {
+	case kindBool:
+		scalaType = "Boolean"
+	case kindNumber:
+		scalaType = "Double"
+	case kindString:
+		scalaType = "String"
+	case kindArray:
+		elemType := "Any"
+		if sch.elem != nil {
+			elemType = s.getScalaType(sch.elem, types)
+		}
+		scalaType = fmt.Sprintf("List[%s]", elemType)
+	case kindObject:
+		scalaType = types.name(sch)
+	case kindNull:
+		return "Option[Any]"
+	default:
+		scalaType = "Any"
+	}
+	if sch.nullable {
+		return scalaOption(scalaType)
+	}
+	return scalaType
+}
+
+// scalaOption wraps a Scala type in Option unless it already is one.
+func scalaOption(scalaType string) string {
+	if strings.HasPrefix(scalaType, "Option[") {
+		return scalaType
+	}
+	return "Option[" + scalaType + "]"
+}
+
+// scalaFieldName keeps a JSON key as the field name, to be quoted by
+// scalaIdentifier, unless backticks cannot quote it: keys that are empty or
+// hold a backtick or line break are sanitized instead.
+func scalaFieldName(key string) string {
+	if key == "" || strings.ContainsAny(key, "`\r\n") {
+		return sanitizeIdentifier(key)
+	}
+	return key
+}
+
+// scalaIdentifier escapes reserved words and keys that are not plain
+// identifiers with backticks.
+func scalaIdentifier(name string) string {
+	if scalaKeywords[name] || !isIdentifier(name) {
+		return "`" + name + "`"
+	}
+	return name
+}
@@ -0,0 +1,60 @@
+package server
+
+import "testing"
+
+func TestFormatAsScala(t *testing.T) {
+	testFormatCases(t, "scala", []formatCase{
+		{
+			name: "Flat object",
+			data: map[string]interface{}{"name": "test", "value": 123.0, "active": true},
+			expectContains: []string{
+				"case class GeneratedStruct(\n",
+				"    active: Boolean,\n",
+				"    name: String,\n",
+				"    value: Double\n)",
+			},
+		},
+		{
+			name: "Nested objects and arrays",
+			data: map[string]interface{}{
+				"user": map[string]interface{}{"name": "test"},
+				"tags": []interface{}{"a", "b"},
+			},
+			expectContains: []string{
+				"    tags: List[String],",
+				"    user: User\n)",
+				"case class User(\n    name: String\n)",
+			},
+		},
+		{
+			name: "Optional and null fields",
+			records: []interface{}{
+				map[string]interface{}{"id": 1.0, "email": nil},
+				map[string]interface{}{"id": 2.0, "email": "x@example.com", "nickname": "a"},
+			},
+			expectContains: []string{
+				"    email: Option[String],",
+				"    nickname: Option[String]",
+			},
+		},
+		{
+			name: "Reserved words",
+			data: map[string]interface{}{"type": "a", "val": 1.0, "created-at": "2024-01-01"},
+			expectContains: []string{
+				"    `created-at`: String,",
+				"    `type`: String,",
+				"    `val`: Double",
+			},
+		},
+		{
+			name: "Keys backticks cannot quote",
+			data: map[string]interface{}{"": 1.0, "a`b": "x", "a_b": true},
+			expectContains: []string{
+				"    _811c9dc5: Double,",
+				"    a_b: Boolean,",
+				"    a_b2: String\n)",
+			},
+			expectMissing: []string{"``", "`a`b`"},
+		},
+	})
+}
@@ -0,0 +1,317 @@
+package server
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+)
+
+// kind classifies the JSON type observed for a value.
+type kind int
+
+const (
+	kindNull kind = iota
+	kindBool
+	kindNumber
+	kindString
+	kindArray
+	kindObject
+	// kindMixed marks values observed with more than one non-null type.
+	kindMixed
+)
+
+// schema describes the shape of one or more decoded JSON values. Schemas
+// built from several samples are merged so that a single struct can cover
+// all of them.
+type schema struct {
+	kind kind
+	// nullable is set when null was observed alongside another kind.
+	nullable bool
+	// fields holds the object members sorted by name.
+	fields []*field
+	// elem describes array elements; nil for arrays that were always empty.
+	elem *schema
+	// samples holds the observed scalar values.
+	samples []interface{}
+	// override is the type forced by -type-override, replacing the inferred
+	// one in the generators supporting it.
+	override string
+}
+
+// field is a named member of an object schema.
+type field struct {
+	name   string
+	schema *schema
+	// optional is set when the key was missing from at least one sample.
+	optional bool
+}
+
+// inferSchema builds the schema of a single decoded JSON value.
+func inferSchema(v interface{}) *schema {
+	switch val := v.(type) {
+	case nil:
+		return &schema{kind: kindNull}
+	case bool:
+		return &schema{kind: kindBool, samples: []interface{}{val}}
+	case float64, float32, int, int64, int32, json.Number:
+		return &schema{kind: kindNumber, samples: []interface{}{val}}
+	case string:
+		return &schema{kind: kindString, samples: []interface{}{val}}
+	case []interface{}:
+		elems := make([]*schema, len(val))
+		for i, elem := range val {
+			elems[i] = inferSchema(elem)
+		}
+		return &schema{kind: kindArray, elem: mergeAll(elems)}
+	case map[string]interface{}:
+		s := &schema{kind: kindObject, fields: make([]*field, 0, len(val))}
+		for _, key := range sortedKeys(val) {
+			s.fields = append(s.fields, &field{name: key, schema: inferSchema(val[key])})
+		}
+		return s
+	default:
+		return &schema{kind: kindMixed}
+	}
+}
+
+// integral reports whether every observed sample of a number schema is a
+// whole number.
+func (s *schema) integral() bool {
+	if s.kind != kindNumber || len(s.samples) == 0 {
+		return false
+	}
+	for _, sample := range s.samples {
+		switch n := sample.(type) {
+		case int, int32, int64:
+		case float32:
+			if float64(n) != math.Trunc(float64(n)) {
+				return false
+			}
+		case float64:
+			if n != math.Trunc(n) {
+				return false
+			}
+		case json.Number:
+			if _, err := n.Int64(); err != nil {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// intBits returns the narrowest signed integer width, 32 or 64, that holds
+// every observed sample of a number schema. It returns 0 when a sample is
+// fractional or out of the int64 range.
+func (s *schema) intBits() int {
+	if !s.integral() {
+		return 0
+	}
+	bits := 32
+	for _, sample := range s.samples {
+		var n float64
+		switch v := sample.(type) {
+		case int:
+			n = float64(v)
+		case int32:
+			n = float64(v)
+		case int64:
+			n = float64(v)
+		case float32:
+			n = float64(v)
+		case float64:
+			n = v
+		case json.Number:
+			i, _ := v.Int64()
+			n = float64(i)
+		}
+		// float64(math.MaxInt64) rounds up to 2^63, itself out of range
+		if n < math.MinInt64 || n >= math.MaxInt64 {
+			return 0
+		}
+		if n < math.MinInt32 || n > math.MaxInt32 {
+			bits = 64
+		}
+	}
+	return bits
+}
+
+// inferRecords merges the schemas of several records, such as the lines of an
+// NDJSON stream, into one.
+func inferRecords(records []interface{}) *schema {
+	schemas := make([]*schema, len(records))
+	for i, record := range records {
+		schemas[i] = inferSchema(record)
+	}
+	return mergeAll(schemas)
+}
+
+// mergeAll merges schemas in order, pairing halves rather than folding one at
+// a time so that each sample is copied a logarithmic number of times instead
+// of once per later schema.
+func mergeAll(schemas []*schema) *schema {
+	switch len(schemas) {
+	case 0:
+		return nil
+	case 1:
+		return schemas[0]
+	}
+	mid := len(schemas) / 2
+	return mergeSchemas(mergeAll(schemas[:mid]), mergeAll(schemas[mid:]))
+}
+
+// mergeSchemas returns a schema covering both a and b. Object keys are
+// unioned, with keys missing on either side marked optional.
+func mergeSchemas(a, b *schema) *schema {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+
+	if a.kind == kindNull && b.kind != kindNull {
+		merged := *b
+		merged.nullable = true
+		return &merged
+	}
+	if b.kind == kindNull && a.kind != kindNull {
+		merged := *a
+		merged.nullable = true
+		return &merged
+	}
+
+	merged := &schema{kind: a.kind, nullable: a.nullable || b.nullable}
+	if a.kind != b.kind {
+		merged.kind = kindMixed
+		return merged
+	}
+
+	merged.samples = append(append(make([]interface{}, 0, len(a.samples)+len(b.samples)), a.samples...), b.samples...)
+	switch a.kind {
+	case kindArray:
+		merged.elem = mergeSchemas(a.elem, b.elem)
+	case kindObject:
+		merged.fields = mergeFields(a.fields, b.fields)
+	}
+	return merged
+}
+
+// mergeFields unions two sorted field lists.
+func mergeFields(a, b []*field) []*field {
+	result := make([]*field, 0, max(len(a), len(b)))
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case j == len(b) || (i < len(a) && a[i].name < b[j].name):
+			result = append(result, &field{name: a[i].name, schema: a[i].schema, optional: true})
+			i++
+		case i == len(a) || b[j].name < a[i].name:
+			result = append(result, &field{name: b[j].name, schema: b[j].schema, optional: true})
+			j++
+		default:
+			result = append(result, &field{
+				name:     a[i].name,
+				schema:   mergeSchemas(a[i].schema, b[j].schema),
+				optional: a[i].optional || b[j].optional,
+			})
+			i++
+			j++
+		}
+	}
+	return result
+}
+
+// limitDepth returns a copy of the schema with arrays and objects nested
+// deeper than maxDepth replaced by kindMixed, so that formatters fall back to
+// their catch-all type. It reports whether anything was cut. A maxDepth of
+// zero or less leaves the schema unchanged.
+func limitDepth(s *schema, maxDepth int) (*schema, bool) {
+	if maxDepth <= 0 || s == nil {
+		return s, false
+	}
+	return limitDepthAt(s, maxDepth)
+}
+
+func limitDepthAt(s *schema, remaining int) (*schema, bool) {
+	if s.kind != kindArray && s.kind != kindObject {
+		return s, false
+	}
+	if remaining == 0 {
+		return &schema{kind: kindMixed, nullable: s.nullable}, true
+	}
+
+	limited := *s
+	var cut bool
+	if s.elem != nil {
+		var elemCut bool
+		limited.elem, elemCut = limitDepthAt(s.elem, remaining-1)
+		cut = cut || elemCut
+	}
+	if s.fields != nil {
+		limited.fields = make([]*field, len(s.fields))
+		for i, f := range s.fields {
+			fieldSchema, fieldCut := limitDepthAt(f.schema, remaining-1)
+			limited.fields[i] = &field{name: f.name, schema: fieldSchema, optional: f.optional}
+			cut = cut || fieldCut
+		}
+	}
+	return &limited, cut
+}
+
+// exceedsFields reports whether the objects of a schema have more than
+// maxFields fields between them, stopping the walk as soon as they do. A
+// maxFields of zero or less is never exceeded.
+func exceedsFields(s *schema, maxFields int) bool {
+	if maxFields <= 0 || s == nil {
+		return false
+	}
+	remaining := maxFields
+	return exceedsFieldsAt(s, &remaining)
+}
+
+func exceedsFieldsAt(s *schema, remaining *int) bool {
+	if s.elem != nil && exceedsFieldsAt(s.elem, remaining) {
+		return true
+	}
+	for _, f := range s.fields {
+		if *remaining--; *remaining < 0 {
+			return true
+		}
+		if exceedsFieldsAt(f.schema, remaining) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameShape reports whether two schemas describe the same structure: the same
+// kinds, nullability, type overrides and fields, ignoring the observed
+// samples.
+func sameShape(a, b *schema) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.kind != b.kind || a.nullable != b.nullable || a.override != b.override || len(a.fields) != len(b.fields) {
+		return false
+	}
+	for i, f := range a.fields {
+		other := b.fields[i]
+		if f.name != other.name || f.optional != other.optional || !sameShape(f.schema, other.schema) {
+			return false
+		}
+	}
+	return sameShape(a.elem, b.elem)
+}
+
+// sortedKeys returns the keys of m in lexical order.
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
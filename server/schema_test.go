@@ -0,0 +1,184 @@
+package server
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+)
+
+func TestInferRecords(t *testing.T) {
+	records := []interface{}{
+		map[string]interface{}{"id": 1.0, "name": "first", "tags": []interface{}{"a"}},
+		map[string]interface{}{"id": 2.0, "email": nil, "tags": []interface{}{}},
+		map[string]interface{}{"id": "three", "email": "x@example.com"},
+	}
+
+	sch := inferRecords(records)
+	if sch.kind != kindObject {
+		t.Fatalf("inferRecords() kind = %v, want object", sch.kind)
+	}
+
+	tests := []struct {
+		name         string
+		expectKind   kind
+		expectOpt    bool
+		expectNull   bool
+		expectSample int
+	}{
+		{name: "email", expectKind: kindString, expectOpt: true, expectNull: true, expectSample: 1},
+		{name: "id", expectKind: kindMixed},
+		{name: "name", expectKind: kindString, expectOpt: true, expectSample: 1},
+		{name: "tags", expectKind: kindArray, expectOpt: true},
+	}
+
+	if len(sch.fields) != len(tests) {
+		t.Fatalf("inferRecords() got %d fields, want %d", len(sch.fields), len(tests))
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := sch.fields[i]
+			if f.name != tt.name {
+				t.Fatalf("field %d name = %s, want %s", i, f.name, tt.name)
+			}
+			if f.schema.kind != tt.expectKind {
+				t.Errorf("kind = %v, want %v", f.schema.kind, tt.expectKind)
+			}
+			if f.optional != tt.expectOpt {
+				t.Errorf("optional = %v, want %v", f.optional, tt.expectOpt)
+			}
+			if f.schema.nullable != tt.expectNull {
+				t.Errorf("nullable = %v, want %v", f.schema.nullable, tt.expectNull)
+			}
+			if len(f.schema.samples) != tt.expectSample {
+				t.Errorf("samples = %d, want %d", len(f.schema.samples), tt.expectSample)
+			}
+		})
+	}
+}
+
+func TestLimitDepth(t *testing.T) {
+	// Build {"child":{"child":...}} far deeper than any real payload
+	var data interface{} = "leaf"
+	for i := 0; i < 1000; i++ {
+		data = map[string]interface{}{"child": data}
+	}
+
+	sch, cut := limitDepth(inferSchema(data), 3)
+	if !cut {
+		t.Fatal("limitDepth() did not report a cut")
+	}
+	depth := 0
+	for sch.kind == kindObject {
+		depth++
+		sch = sch.fields[0].schema
+	}
+	if depth != 3 || sch.kind != kindMixed {
+		t.Errorf("limitDepth() kept %d levels ending in kind %v, want 3 ending in %v", depth, sch.kind, kindMixed)
+	}
+
+	if _, cut := limitDepth(inferSchema(data), 0); cut {
+		t.Error("limitDepth() with no limit reported a cut")
+	}
+
+	var logs bytes.Buffer
+	srv := New(8080, "go", false, false, WithMaxDepth(3))
+	result, err := srv.generate(log.New(&logs, "", 0), inferSchema(data), "/api/data")
+	if err != nil {
+		t.Fatalf("generate() error = %v", err)
+	}
+	if !strings.Contains(result, "child interface{} `json:\"child\"`") {
+		t.Errorf("generate() result does not fall back to interface{}\nGot: %s", result)
+	}
+	if !strings.Contains(logs.String(), "nested deeper than 3 levels") {
+		t.Errorf("generate() did not log a warning\nGot: %s", logs.String())
+	}
+}
+
+func TestFormatSchema_RaggedArrays(t *testing.T) {
+	ragged := []interface{}{
+		map[string]interface{}{"id": 1.0, "note": "x"},
+		map[string]interface{}{"id": 2.0},
+	}
+	fixtures := map[string]interface{}{
+		"nested":    map[string]interface{}{"items": ragged},
+		"top-level": ragged,
+	}
+
+	// Each format must mark note optional, as one element lacks it, while
+	// keeping id required
+	tests := []struct {
+		format         string
+		expectContains []string
+	}{
+		{format: "go", expectContains: []string{"id float64 `json:\"id\"`", "note *string `json:\"note,omitempty\"`"}},
+		{format: "rust", expectContains: []string{"id: f64,", "note: Option<String>,"}},
+		{format: "typeddict", expectContains: []string{"id: float\n", "note: NotRequired[str]\n"}},
+		{format: "scala", expectContains: []string{"id: Double,", "note: Option[String]"}},
+		{format: "haskell", expectContains: []string{"Id :: Double", "Note :: Maybe Text"}},
+		{format: "zod", expectContains: []string{"id: z.number(),", "note: z.string().optional(),"}},
+		{format: "openapi", expectContains: []string{"required:\n    - id\n  properties:"}},
+		{format: "avro", expectContains: []string{"\"name\": \"note\",", "\"default\": null"}},
+		{format: "dart", expectContains: []string{"final double id;", "final String? note;", "{required this.id, this.note}"}},
+		{format: "c", expectContains: []string{"long id;", "char **note;"}},
+		{format: "elm", expectContains: []string{"id : Float", "note : Maybe String", "Decode.maybe (Decode.field \"note\" Decode.string)"}},
+		{format: "php", expectContains: []string{"public float $id;", "public ?string $note = null;"}},
+		{format: "ocaml", expectContains: []string{"id : float;", "note : string option [@default None];"}},
+		{format: "fsharp", expectContains: []string{"Id: float", "Note: string option"}},
+		{format: "ruby", expectContains: []string{"const :id, Float", "const :note, T.nilable(String)"}},
+		{format: "crystal", expectContains: []string{"property id : Int64\n", "property note : String?"}},
+		{format: "objc", expectContains: []string{"double idValue;", "(nonatomic, copy, nullable) NSString *note;"}},
+		{format: "mermaid", expectContains: []string{"+Number id\n", "+String? note"}},
+		{format: "plantuml", expectContains: []string{"    id : Number\n", "    note : String?\n"}},
+		{format: "clojure", expectContains: []string{"/id number?)", " :opt-un [:"}},
+		{format: "flatbuffers", expectContains: []string{"  id:long;\n", "  note:string;\n"}},
+		{format: "xsd", expectContains: []string{"<xs:element name=\"id\" type=\"xs:integer\"/>\n", "<xs:element name=\"note\" minOccurs=\"0\" type=\"xs:string\"/>\n"}},
+		{format: "rbi", expectContains: []string{"  sig { returns(Float) }\n  def id; end\n", "  sig { returns(T.nilable(String)) }\n  def note; end\n"}},
+		{format: "graphql", expectContains: []string{"  id: Int!\n", "  note: String\n"}},
+		{format: "thrift", expectContains: []string{"1: i64 id;", "2: optional string note;"}},
+		{format: "kotlin", expectContains: []string{"val id: Long,", "val note: String? = null,"}},
+	}
+
+	for _, tt := range tests {
+		for fixture, data := range fixtures {
+			t.Run(tt.format+"/"+fixture, func(t *testing.T) {
+				srv := New(8080, tt.format, false, false, WithRubySorbet(true))
+				result, err := srv.formatSchema(inferSchema(data))
+				if err != nil {
+					t.Fatalf("formatSchema() error = %v", err)
+				}
+
+				for _, expect := range tt.expectContains {
+					if !strings.Contains(result, expect) {
+						t.Errorf("formatSchema() result does not contain expected string: %s\nGot: %s", expect, result)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestExceedsFields(t *testing.T) {
+	// Four fields: a, a.b, items and items[].c
+	sch := inferSchema(map[string]interface{}{
+		"a":     map[string]interface{}{"b": 1.0},
+		"items": []interface{}{map[string]interface{}{"c": 1.0}},
+	})
+
+	tests := []struct {
+		maxFields int
+		want      bool
+	}{
+		{0, false},
+		{3, true},
+		{4, false},
+		{5, false},
+	}
+
+	for _, tt := range tests {
+		if got := exceedsFields(sch, tt.maxFields); got != tt.want {
+			t.Errorf("exceedsFields(%d) = %v, want %v", tt.maxFields, got, tt.want)
+		}
+	}
+}
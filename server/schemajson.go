@@ -0,0 +1,98 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// kindNames are the kind values of the schema-json format.
+var kindNames = map[kind]string{
+	kindNull:   "null",
+	kindBool:   "boolean",
+	kindNumber: "number",
+	kindString: "string",
+	kindArray:  "array",
+	kindObject: "object",
+	kindMixed:  "mixed",
+}
+
+// schemaDocument is the language neutral JSON form of a schema, exposing the
+// model every formatter works from.
+type schemaDocument struct {
+	Kind string `json:"kind"`
+	// Name is the type name formatters give an object.
+	Name     string `json:"name,omitempty"`
+	Nullable bool   `json:"nullable,omitempty"`
+	// Integer marks numbers whose samples were all whole.
+	Integer bool `json:"integer,omitempty"`
+	// Format names a detected string encoding: uuid, base64 or duration.
+	Format string `json:"format,omitempty"`
+	// Enum lists the values of a detected enum.
+	Enum   []string              `json:"enum,omitempty"`
+	Fields []schemaFieldDocument `json:"fields,omitempty"`
+	// Items describes array elements, and is missing for arrays that were
+	// always empty.
+	Items *schemaDocument `json:"items,omitempty"`
+}
+
+type schemaFieldDocument struct {
+	Name     string          `json:"name"`
+	Optional bool            `json:"optional,omitempty"`
+	Schema   *schemaDocument `json:"schema"`
+}
+
+func (s *Server) formatAsSchemaJSON(sch *schema) (string, error) {
+	root := sch
+	if sch.kind != kindObject {
+		root = &schema{kind: kindObject, fields: []*field{{name: "data", schema: sch}}}
+	}
+
+	types := s.nestedObjects(s.structName, root)
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(s.generateSchemaDocument(root, types)); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+func (s *Server) generateSchemaDocument(sch *schema, types *objectTypes) *schemaDocument {
+	doc := &schemaDocument{
+		Kind:     kindNames[sch.kind],
+		Nullable: sch.nullable,
+		Integer:  sch.integral(),
+	}
+	switch {
+	case s.isUUID(sch):
+		doc.Format = "uuid"
+	case s.isIP(sch):
+		doc.Format = "ip"
+	case s.isCIDR(sch):
+		doc.Format = "cidr"
+	case s.isBase64(sch):
+		doc.Format = "base64"
+	case s.isDuration(sch):
+		doc.Format = "duration"
+	}
+
+	switch sch.kind {
+	case kindArray:
+		if sch.elem != nil {
+			doc.Items = s.generateSchemaDocument(sch.elem, types)
+		}
+	case kindObject:
+		doc.Name = types.name(sch)
+		for _, f := range sch.fields {
+			fieldDoc := s.generateSchemaDocument(f.schema, types)
+			if s.isEnumField(f) {
+				fieldDoc.Enum = enumValues(f.schema)
+			}
+			doc.Fields = append(doc.Fields, schemaFieldDocument{Name: f.name, Optional: f.optional, Schema: fieldDoc})
+		}
+	}
+	return doc
+}
@@ -0,0 +1,93 @@
+package server
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestFormatAsSchemaJSON(t *testing.T) {
+	testFormatCases(t, "schema-json", []formatCase{
+		{
+			name: "Kinds, names and integers",
+			data: map[string]interface{}{
+				"name":   "test",
+				"count":  3.0,
+				"ratio":  0.5,
+				"active": true,
+				"owner":  map[string]interface{}{"id": nil},
+				"tags":   []interface{}{},
+				"mixed":  []interface{}{1.0, "a"},
+			},
+			expectContains: []string{
+				"{\n  \"kind\": \"object\",\n  \"name\": \"GeneratedStruct\",\n  \"fields\": [\n",
+				"\"name\": \"active\",\n      \"schema\": {\n        \"kind\": \"boolean\"\n      }",
+				"\"name\": \"count\",\n      \"schema\": {\n        \"kind\": \"number\",\n        \"integer\": true\n      }",
+				"\"name\": \"mixed\",\n      \"schema\": {\n        \"kind\": \"array\",\n        \"items\": {\n          \"kind\": \"mixed\"\n        }\n      }",
+				"\"name\": \"owner\",\n      \"schema\": {\n        \"kind\": \"object\",\n        \"name\": \"Owner\",\n",
+				"\"kind\": \"null\"",
+				"\"name\": \"ratio\",\n      \"schema\": {\n        \"kind\": \"number\"\n      }",
+				"\"name\": \"tags\",\n      \"schema\": {\n        \"kind\": \"array\"\n      }",
+			},
+		},
+		{
+			name: "Optional and nullable fields",
+			records: []interface{}{
+				map[string]interface{}{"id": 1.0, "email": nil, "note": "x"},
+				map[string]interface{}{"id": 2.0, "email": "x@example.com"},
+			},
+			expectContains: []string{
+				"\"name\": \"email\",\n      \"schema\": {\n        \"kind\": \"string\",\n        \"nullable\": true\n      }",
+				"\"name\": \"note\",\n      \"optional\": true,\n      \"schema\": {\n        \"kind\": \"string\"\n      }",
+			},
+		},
+		{
+			name: "Detected formats and enums",
+			opts: []Option{WithDetectUUID(true), WithDetectEnums(true)},
+			records: []interface{}{
+				map[string]interface{}{"id": "123e4567-e89b-12d3-a456-426614174000", "status": "active"},
+				map[string]interface{}{"id": "123e4567-e89b-12d3-a456-426614174001", "status": "inactive"},
+				map[string]interface{}{"id": "123e4567-e89b-12d3-a456-426614174002", "status": "active"},
+			},
+			expectContains: []string{
+				"\"kind\": \"string\",\n        \"format\": \"uuid\"",
+				"\"enum\": [\n          \"active\",\n          \"inactive\"\n        ]",
+			},
+		},
+		{
+			name:           "Non-object root",
+			data:           []interface{}{"a"},
+			expectContains: []string{"\"name\": \"data\",\n      \"schema\": {\n        \"kind\": \"array\",\n        \"items\": {\n          \"kind\": \"string\"\n        }"},
+		},
+	})
+}
+
+func TestFormatAsSchemaJSON_RoundTrip(t *testing.T) {
+	srv := New(8080, "schema-json", false, false)
+	result, err := srv.formatSchema(inferSchema(map[string]interface{}{
+		"items": []interface{}{map[string]interface{}{"id": 1.0}},
+	}))
+	if err != nil {
+		t.Fatalf("formatSchema() error = %v", err)
+	}
+
+	var doc schemaDocument
+	if err := json.Unmarshal([]byte(result), &doc); err != nil {
+		t.Fatalf("schema-json output is not valid JSON: %v\nGot: %s", err, result)
+	}
+	expect := schemaDocument{
+		Kind: "object",
+		Name: "GeneratedStruct",
+		Fields: []schemaFieldDocument{{
+			Name: "items",
+			Schema: &schemaDocument{Kind: "array", Items: &schemaDocument{
+				Kind:   "object",
+				Name:   "Items",
+				Fields: []schemaFieldDocument{{Name: "id", Schema: &schemaDocument{Kind: "number", Integer: true}}},
+			}},
+		}},
+	}
+	if !reflect.DeepEqual(doc, expect) {
+		t.Errorf("schema-json output decoded to %+v, want %+v", doc, expect)
+	}
+}
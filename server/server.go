@@ -1,58 +1,270 @@
 package server
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"mime"
 	"net/http"
 	"net/http/httputil"
+	"slices"
 	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"golang.org/x/sync/errgroup"
 )
 
 type Server struct {
 	port       int
+	extraPorts []int
 	formatType string
 	pretty     bool
 	headers    bool
+	// structName names the root generated type
+	structName string
+
+	detectEnums bool
+	indent      string
+	color       bool
+	detectUUID  bool
+	quiet       bool
+
+	detectBase64         bool
+	detectDurations      bool
+	coerceNumericStrings bool
+	pointers             bool
+	goTags               []string
+	narrowInts           bool
+	validate             bool
+	gzip                 bool
+	meta                 bool
+	verbose              bool
+	numberType           string
+	strictKeys           bool
+	paths                []string
+	websocket            bool
+	annotateTypes        bool
+	typeOverrides        map[string]string
+	goAnyAlias           bool
+	goPackage            string
+	graphqlKind          string
+	graphqlScalars       map[string]string
+	detectNet            bool
+	rustDerives          []string
+	rustPub              bool
+	maxDepth             int
+	maxFields            int
+	strictDepth          bool
+	nestedNaming         string
+	rubySorbet           bool
+	flatten              bool
+	maxBodySize          int64
+	kotlinStyle          string
+	comments             bool
+
+	formatHeaders   bool
+	generatedHeader bool
+
+	readTimeout  time.Duration
+	writeTimeout time.Duration
+	h2c          bool
+
+	rateLimit      float64
+	rateLimitPerIP bool
+
+	middleware []Middleware
+
+	// pool bounds concurrent formatting when enabled
+	pool *workerPool
+	// cache memoizes generated code for repeated bodies when enabled
+	cache *outputCache
+	// output saves generated code to a file when enabled
+	output *outputFile
+	// capture appends received bodies as NDJSON for Replay when enabled
+	capture *captureWriter
+	// reservedNames are type names declared elsewhere that generated types
+	// must not reuse
+	reservedNames map[string]bool
+	// goDurationName names a Go duration wrapper declared elsewhere, which
+	// Go output uses instead of declaring its own
+	goDurationName string
+
+	// spec accumulates an OpenAPI document when enabled
+	spec *specStore
+
+	version string
+
+	// once stops the server after the first successful request, by calling
+	// stop from the handler
+	once bool
+	stop context.CancelFunc
+
+	tracer trace.Tracer
 }
 
-func New(port int, formatType string, pretty bool, headers bool) *Server {
-	return &Server{
+// defaultTimeout bounds how long a connection may take to send a request or
+// receive the response, so slow clients cannot hold connections open.
+const defaultTimeout = 30 * time.Second
+
+// defaultStructName names the root generated type unless a request picks
+// another name with the structNameHeader.
+const (
+	defaultStructName = "GeneratedStruct"
+	structNameHeader  = "X-Struct-Name"
+)
+
+// defaultMaxDepth bounds how deeply nested values are typed, which keeps the
+// recursive generators safe from adversarial payloads.
+const defaultMaxDepth = 64
+
+// defaultMaxFields bounds the fields generated for one body, well above what
+// hand-written APIs use but below what chokes compilers.
+const defaultMaxFields = 10000
+
+func New(port int, formatType string, pretty bool, headers bool, opts ...Option) *Server {
+	s := &Server{
 		port:       port,
 		formatType: formatType,
 		pretty:     pretty,
 		headers:    headers,
+		structName: defaultStructName,
+		indent:     "    ",
+
+		readTimeout:  defaultTimeout,
+		writeTimeout: defaultTimeout,
+		maxDepth:     defaultMaxDepth,
+		maxFields:    defaultMaxFields,
+		nestedNaming: nestedNamingKey,
+		kotlinStyle:  kotlinStyleKotlinx,
+
+		version: "unknown",
+		tracer:  noopTracer,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
+// infof logs an informational message unless quiet mode is enabled.
+func (s *Server) infof(logger *log.Logger, format string, args ...interface{}) {
+	if !s.quiet {
+		logger.Printf(format, args...)
+	}
+}
+
+// Start serves requests on every port until ctx is cancelled, or until the
+// first successful request when once is enabled. A port failing to listen
+// shuts the others down. It returns nil after a graceful shutdown.
 func (s *Server) Start(ctx context.Context) error {
-	mux := http.NewServeMux()
-	mux.HandleFunc("/", s.handleRequest)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	s.stop = cancel
+	servers := s.httpServers()
 
-	server := &http.Server{
-		Addr:    fmt.Sprintf(":%d", s.port),
-		Handler: mux,
+	group, ctx := errgroup.WithContext(ctx)
+	for _, server := range servers {
+		group.Go(func() error {
+			if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			return nil
+		})
 	}
-
-	go func() {
+	// ListenAndServe returns as soon as Shutdown starts, so waiting on the
+	// group also waits for in-flight requests to finish
+	group.Go(func() error {
 		<-ctx.Done()
-		if err := server.Shutdown(context.Background()); err != nil {
-			log.Printf("Error shutting down server: %v", err)
+		for _, server := range servers {
+			if err := server.Shutdown(context.Background()); err != nil {
+				log.Printf("Error shutting down server on %s: %v", server.Addr, err)
+			}
 		}
-	}()
+		return nil
+	})
+	return group.Wait()
+}
 
-	return server.ListenAndServe()
+// httpServer builds the http.Server for the main port.
+func (s *Server) httpServer() *http.Server {
+	return s.httpServers()[0]
+}
+
+// httpServers builds one http.Server per port, sharing a handler so rate
+// limits apply across all ports.
+func (s *Server) httpServers() []*http.Server {
+	var limiter *rateLimiter
+	if s.rateLimit > 0 {
+		limiter = newRateLimiter(s.rateLimit, s.rateLimitPerIP)
+	}
+	var request, format, ws http.Handler = http.HandlerFunc(s.handleRequest), http.HandlerFunc(s.handleFormat), s.websocketHandler(limiter)
+	var openapi, reset http.Handler = http.HandlerFunc(s.handleOpenAPI), http.HandlerFunc(s.handleReset)
+	if limiter != nil {
+		// Only /version and /formats stay unlimited, so health probes and
+		// clients listing formats keep working
+		request, format, ws = limiter.middleware(request), limiter.middleware(format), limiter.middleware(ws)
+		openapi, reset = limiter.middleware(openapi), limiter.middleware(reset)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/", request)
+	mux.HandleFunc("/version", s.handleVersion)
+	mux.HandleFunc("/formats", s.handleFormats)
+	mux.Handle("/format", format)
+	if s.spec != nil {
+		mux.Handle("/openapi.json", openapi)
+	}
+	if s.websocket {
+		mux.Handle("/ws", ws)
+	}
+	// Without accumulated state /reset is echoed like any other path
+	if s.spec != nil || s.cache != nil {
+		mux.Handle("/reset", reset)
+	}
+
+	var handler http.Handler = mux
+	if s.gzip {
+		handler = gzipMiddleware(handler)
+	}
+	handler = s.wrap(handler)
+	servers := make([]*http.Server, 0, 1+len(s.extraPorts))
+	for _, port := range append([]int{s.port}, s.extraPorts...) {
+		server := &http.Server{
+			Addr:         fmt.Sprintf(":%d", port),
+			Handler:      handler,
+			ReadTimeout:  s.readTimeout,
+			WriteTimeout: s.writeTimeout,
+		}
+		if s.h2c {
+			// Registering the HTTP/2 server sends GOAWAY to h2c connections on
+			// Shutdown, which otherwise does not track them
+			h2s := &http2.Server{}
+			server.Handler = h2c.NewHandler(handler, h2s)
+			if err := http2.ConfigureServer(server, h2s); err != nil {
+				log.Printf("Error configuring HTTP/2: %v", err)
+			}
+		}
+		servers = append(servers, server)
+	}
+	return servers
 }
 
 func (s *Server) formatJSON(data interface{}) string {
 	if s.pretty {
-		jsonBytes, err := json.MarshalIndent(data, "", "    ")
+		jsonBytes, err := marshalUnescaped(data)
 		if err != nil {
 			return fmt.Sprintf("Error formatting JSON: %v", err)
 		}
-		jsonStr := string(jsonBytes)
+		var indented bytes.Buffer
+		json.Indent(&indented, jsonBytes, "", s.indent)
+		jsonStr := indented.String()
 
 		// Find the longest line in the JSON
 		maxWidth := 0
@@ -75,61 +287,117 @@ func (s *Server) formatJSON(data interface{}) string {
 			delimiter, delimiter, jsonStr, delimiter, delimiter)
 	}
 
-	jsonBytes, err := json.Marshal(data)
+	jsonBytes, err := marshalUnescaped(data)
 	if err != nil {
 		return fmt.Sprintf("Error formatting JSON: %v", err)
 	}
 	return fmt.Sprintf("JSON-Body: %s", string(jsonBytes))
 }
 
+// marshalUnescaped encodes data as compact JSON. Unlike json.Marshal, it
+// leaves <, > and & in strings as they are, so that logged bodies match what
+// was sent.
+func marshalUnescaped(data interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(data); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
 func (s *Server) handleRequest(w http.ResponseWriter, r *http.Request) {
-	// Always log the method
-	log.Printf("Received %s request to %s", r.Method, r.URL.Path)
+	id := requestID(r)
+	logger := requestLogger(id)
 
-	// Parse JSON body if present
-	var bodyData interface{}
-	if r.Header.Get("Content-Type") == "application/json" {
-		body, err := io.ReadAll(r.Body)
-		if err != nil {
-			http.Error(w, "Error reading request body", http.StatusBadRequest)
-			return
-		}
-		defer r.Body.Close()
+	ctx, span := s.startRequestSpan(r)
+	r = r.WithContext(ctx)
 
-		if len(body) > 0 {
-			if err := json.Unmarshal(body, &bodyData); err != nil {
-				http.Error(w, "Error parsing JSON", http.StatusBadRequest)
-				return
-			}
+	// Acknowledge requests outside the -paths allowlist without reading them
+	if !matchesPath(s.paths, r.URL.Path) {
+		endRequestSpan(span, http.StatusOK, nil)
+		w.Header().Set("X-Request-ID", id)
+		writeAcknowledgement(w, r, id, nil)
+		return
+	}
 
-			// Show headers if requested
-			if s.headers {
-				if rawRequest, err := httputil.DumpRequest(r, true); err == nil {
-					log.Printf("Headers:\n%s", string(rawRequest))
-				}
-			}
+	if s.pool != nil {
+		if !s.pool.acquire(ctx) {
+			endRequestSpan(span, http.StatusServiceUnavailable, errBusy)
+			busy(w)
+			return
+		}
+		defer s.pool.release()
+	}
 
-			// Always show JSON body
-			log.Print(s.formatJSON(bodyData))
-
-			// Show struct format if specified
-			if s.formatType != "" {
-				formatted, err := s.formatData(bodyData)
-				if err != nil {
-					http.Error(w, fmt.Sprintf("Error formatting data: %v", err), http.StatusInternalServerError)
-					return
-				}
-				log.Printf("Struct format:\n%s", formatted)
-			}
+	// Requests may name the generated type themselves, and ask for the
+	// generated code in place of the acknowledgement through Accept
+	name := r.Header.Get(structNameHeader)
+	if name != "" && !isIdentifier(name) {
+		err := invalidStructName(name)
+		endRequestSpan(span, err.status, err)
+		writeJSONError(w, err)
+		return
+	}
+	mediaType, format := negotiateFormat(r.Header.Get("Accept"))
+	srv := s
+	if name != "" || format != "" {
+		custom := *s
+		if name != "" {
+			custom.structName = name
+		}
+		if format != "" {
+			// Keep the output file in the configured format
+			custom.formatType = format
+			custom.output = nil
 		}
+		srv = &custom
+	}
+
+	formatted, meta, err := srv.processRequest(logger, r, r.URL.Path)
+	// Code was asked for, but there was no body to generate it from. Schema
+	// reports replace code when validating, so those are acknowledged
+	if err == nil && format != "" && formatted == "" && !s.validate {
+		w.Header().Add("Vary", "Accept")
+		err = notAcceptable(mediaType)
+	}
+	if err != nil {
+		endRequestSpan(span, err.status, err)
+		writeJSONError(w, err)
+		return
 	}
+	endRequestSpan(span, http.StatusOK, nil)
 
 	// Send response
+	w.Header().Add("Vary", "Accept")
+	w.Header().Set("X-Request-ID", id)
+	if format != "" && formatted != "" {
+		w.Header().Set("Content-Type", negotiatedContentType(mediaType))
+		io.WriteString(w, formatted+"\n")
+	} else {
+		writeAcknowledgement(w, r, id, meta)
+	}
+
+	// Shutdown waits for this handler to return, so the response is sent in
+	// full before the server stops
+	if s.once && s.stop != nil {
+		s.stop()
+	}
+}
+
+// writeAcknowledgement replies with the JSON confirming a processed request,
+// including the body metadata when there is any.
+func writeAcknowledgement(w http.ResponseWriter, r *http.Request, id string, meta *bodyMeta) {
 	w.Header().Set("Content-Type", "application/json")
 	response := map[string]interface{}{
-		"message": "Request processed successfully",
-		"method":  r.Method,
-		"path":    r.URL.Path,
+		"message":    "Request processed successfully",
+		"method":     r.Method,
+		"path":       r.URL.Path,
+		"request_id": id,
+	}
+	if meta != nil {
+		response["meta"] = meta
 	}
 	encoder := json.NewEncoder(w)
 	encoder.SetEscapeHTML(false)
@@ -137,89 +405,914 @@ func (s *Server) handleRequest(w http.ResponseWriter, r *http.Request) {
 	encoder.Encode(response)
 }
 
+// handleVersion reports the version of the running instance.
+func (s *Server) handleVersion(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"version": s.version})
+}
+
+// handleReset clears the state accumulated across requests: the OpenAPI
+// spec store and the output cache.
+func (s *Server) handleReset(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSONError(w, errMethodNotAllowed)
+		return
+	}
+	if s.spec != nil {
+		s.spec.reset()
+	}
+	if s.cache != nil {
+		s.cache.reset()
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleFormat replies with only the code generated for the posted JSON, in
+// the format named by the lang query parameter (the configured format when
+// omitted). Newline-delimited bodies are merged into one struct.
+func (s *Server) handleFormat(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSONError(w, errMethodNotAllowed)
+		return
+	}
+
+	formatter := *s
+	if lang := r.URL.Query().Get("lang"); lang != "" {
+		formatter.formatType = lang
+	}
+	if formatter.formatType == "" {
+		writeJSONError(w, &requestError{http.StatusBadRequest, codeMissingLang, "Missing lang parameter", ""})
+		return
+	}
+	if name := r.Header.Get(structNameHeader); name != "" {
+		if !isIdentifier(name) {
+			writeJSONError(w, invalidStructName(name))
+			return
+		}
+		formatter.structName = name
+	}
+
+	defer r.Body.Close()
+	body, reqErr := s.readRequestBody(r)
+	if reqErr != nil {
+		writeJSONError(w, reqErr)
+		return
+	}
+	records, err := decodeNDJSON(bytes.NewReader(body))
+	if err != nil {
+		reqErr := &requestError{http.StatusBadRequest, codeParseError, "Error parsing JSON", describeJSONError(body, err)}
+		requestLogger(requestID(r)).Print(reqErr)
+		writeJSONError(w, reqErr)
+		return
+	}
+	if len(records) == 0 {
+		writeJSONError(w, &requestError{http.StatusBadRequest, codeEmptyBody, "Empty request body", ""})
+		return
+	}
+
+	if s.pool != nil {
+		if !s.pool.acquire(r.Context()) {
+			busy(w)
+			return
+		}
+		defer s.pool.release()
+	}
+
+	formatted, err := formatter.generateRecords(requestLogger(requestID(r)), "application/x-ndjson", body, records, r.URL.Path)
+	if err != nil {
+		writeJSONError(w, &requestError{http.StatusBadRequest, codeFormatError, "Error formatting data", err.Error()})
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	io.WriteString(w, formatted+"\n")
+}
+
+// Format generates code for a JSON document, or for a stream of documents
+// merged into one struct, without logging it. The source names where the JSON
+// came from in generated code comments.
+func (s *Server) Format(data []byte, source string) (string, error) {
+	if s.formatType == "" {
+		return "", errors.New("no format type configured")
+	}
+	records, err := decodeNDJSON(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("invalid JSON: %w", err)
+	}
+	if len(records) == 0 {
+		return "", errors.New("invalid JSON: no value found")
+	}
+	return s.generateRecords(log.Default(), "application/x-ndjson", data, records, source)
+}
+
+// ProcessRequest runs a request through the same parsing and formatting
+// pipeline as the HTTP handler, logging the output. It lets callers process
+// requests that did not arrive over the network, such as saved .http files.
+// The source names where the request came from in generated code comments.
+func (s *Server) ProcessRequest(r *http.Request, source string) error {
+	// Return an untyped nil so callers can compare against nil
+	if _, _, err := s.processRequest(requestLogger(requestID(r)), r, source); err != nil {
+		return err
+	}
+	return nil
+}
+
+// processRequest parses the request body according to its content type and
+// logs the headers, JSON and generated struct. It returns the generated
+// struct, without colors, or an empty string when nothing was generated.
+// With metadata enabled it also returns statistics about a JSON body.
+func (s *Server) processRequest(logger *log.Logger, r *http.Request, source string) (string, *bodyMeta, *requestError) {
+	// Log the method unless running quietly
+	s.infof(logger, "Received %s request to %s", r.Method, r.URL.Path)
+
+	// Generate a struct for the request headers if requested
+	if s.formatHeaders && s.formatType != "" {
+		formatted, err := s.generateHeaders(logger, r.Header, source)
+		if err != nil {
+			return "", nil, &requestError{http.StatusInternalServerError, codeFormatError, "Error formatting headers", err.Error()}
+		}
+		if s.color {
+			formatted = colorize(s.formatType, formatted)
+		}
+		logger.Printf("Header struct format:\n%s", formatted)
+	}
+
+	// Parse JSON body if present
+	var records []interface{}
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if !isJSONMediaType(mediaType) && mediaType != "application/x-ndjson" && mediaType != "application/json-seq" && mediaType != "text/csv" {
+		s.recordOperation(r, nil)
+		return "", nil, nil
+	}
+
+	defer r.Body.Close()
+	body, reqErr := s.readRequestBody(r)
+	if reqErr != nil {
+		return "", nil, reqErr
+	}
+	var err error
+
+	switch {
+	case isJSONMediaType(mediaType):
+		if len(body) > 0 {
+			var bodyData interface{}
+			if err := json.Unmarshal(body, &bodyData); err != nil {
+				reqErr := &requestError{http.StatusBadRequest, codeParseError, "Error parsing JSON", describeJSONError(body, err)}
+				logger.Print(reqErr)
+				return "", nil, reqErr
+			}
+			records = append(records, bodyData)
+		}
+	case mediaType == "application/x-ndjson":
+		records, err = decodeNDJSON(bytes.NewReader(body))
+		if err != nil {
+			reqErr := &requestError{http.StatusBadRequest, codeParseError, "Error parsing NDJSON", describeJSONError(body, err)}
+			logger.Print(reqErr)
+			return "", nil, reqErr
+		}
+	case mediaType == "application/json-seq":
+		records, err = decodeJSONSeq(body)
+		if err != nil {
+			reqErr := &requestError{http.StatusBadRequest, codeParseError, "Error parsing JSON text sequence", err.Error()}
+			logger.Print(reqErr)
+			return "", nil, reqErr
+		}
+	case mediaType == "text/csv":
+		records, err = decodeCSV(bytes.NewReader(body))
+		if err != nil {
+			return "", nil, &requestError{http.StatusBadRequest, codeParseError, "Error parsing CSV", err.Error()}
+		}
+	}
+
+	if len(records) == 0 {
+		s.recordOperation(r, nil)
+		return "", nil, nil
+	}
+	if mediaType != "text/csv" {
+		if duplicates, err := duplicateKeys(body); err == nil && len(duplicates) > 0 {
+			reqErr := &requestError{http.StatusBadRequest, codeDuplicateKeys, "Duplicate keys in JSON", strings.Join(duplicates, ", ")}
+			if s.strictKeys {
+				logger.Print(reqErr)
+				return "", nil, reqErr
+			}
+			logger.Printf("Warning: %s, only the last value of each is kept", reqErr)
+		}
+	}
+	bodySchema := inferRecords(records)
+	s.recordOperation(r, bodySchema)
+	var meta *bodyMeta
+	if s.meta {
+		meta = newBodyMeta(bodySchema, len(body))
+	}
+
+	// Show headers if requested
+	if s.headers {
+		if rawRequest, err := httputil.DumpRequest(r, true); err == nil {
+			logger.Printf("Headers:\n%s", string(rawRequest))
+		}
+	}
+
+	if s.capture != nil {
+		if err := s.capture.write(records); err != nil {
+			logger.Printf("Warning: error capturing body: %v", err)
+		}
+	}
+
+	// Always show JSON body
+	for _, record := range records {
+		logger.Print(s.formatJSON(record))
+		if s.annotateTypes {
+			logger.Printf("Annotated types:\n%s", s.annotate(record))
+		}
+	}
+
+	// Describe the schema instead of generating code when validating
+	if s.validate {
+		logger.Printf("Schema report:\n%s", s.schemaReport(bodySchema))
+		return "", meta, nil
+	}
+
+	// Show struct format if specified
+	if s.formatType != "" {
+		formatted, err := s.generateRecords(logger, mediaType, body, records, source)
+		var limitErr *fieldLimitError
+		var depthErr *depthLimitError
+		if errors.As(err, &limitErr) {
+			return "", nil, &requestError{http.StatusBadRequest, codeTooManyFields, "Error formatting data", err.Error()}
+		} else if errors.As(err, &depthErr) {
+			return "", nil, &requestError{http.StatusBadRequest, codeTooDeep, "Error formatting data", err.Error()}
+		} else if err != nil {
+			return "", nil, &requestError{http.StatusInternalServerError, codeFormatError, "Error formatting data", err.Error()}
+		}
+		// Rows of a CSV body are merged into one struct, used as a slice
+		if alias := s.rowsAlias("Rows", s.structName); mediaType == "text/csv" && alias != "" {
+			formatted += "\n\n" + alias
+		}
+		logged := formatted
+		if s.color {
+			logged = colorize(s.formatType, formatted)
+		}
+		logger.Printf("Struct format:\n%s", logged)
+
+		if s.output != nil {
+			if err := s.writeOutput(logger, bodySchema, formatted, source, mediaType == "text/csv"); err != nil {
+				return "", nil, &requestError{http.StatusInternalServerError, codeOutputError, "Error writing output file", err.Error()}
+			}
+		}
+		return formatted, meta, nil
+	}
+	return "", meta, nil
+}
+
+// generateHeaders formats the request headers, converting them directly in
+// the data transform formats.
+func (s *Server) generateHeaders(logger *log.Logger, header http.Header, source string) (string, error) {
+	if s.formatType == "toml" {
+		formatted, err := formatAsTOML([]interface{}{headerData(header)})
+		if err != nil {
+			return "", err
+		}
+		return s.withGeneratedComment(formatted, source), nil
+	}
+	return s.generate(logger, inferSchema(headerData(header)), source)
+}
+
+// recordOperation adds a request to the accumulated OpenAPI document, when
+// enabled, warning once the document is full.
+func (s *Server) recordOperation(r *http.Request, body *schema) {
+	if s.spec != nil && s.spec.record(r.Method, r.URL.Path, body) {
+		log.Printf("Warning: the OpenAPI spec holds %d operations, further methods and paths are not recorded until POST /reset", maxSpecOperations)
+	}
+}
+
+// headerData converts request headers into decoded JSON form so they can be
+// run through the struct generators. Headers with several values become
+// arrays.
+func headerData(header http.Header) map[string]interface{} {
+	data := make(map[string]interface{}, len(header))
+	for name, values := range header {
+		if len(values) == 1 {
+			data[name] = values[0]
+			continue
+		}
+		items := make([]interface{}, len(values))
+		for i, value := range values {
+			items[i] = value
+		}
+		data[name] = items
+	}
+	return data
+}
+
+// isJSONMediaType reports whether a media type carries a single JSON document,
+// either application/json or a structured syntax suffix such as
+// application/vnd.api+json.
+func isJSONMediaType(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// errBodyTooLarge reports a request body over the maximum body size.
+var errBodyTooLarge = errors.New("request body too large")
+
+// readBody reads a whole request body, failing once it grows past the
+// maximum body size. Bytes are counted as they are read rather than trusting
+// Content-Length, so chunked bodies of unknown length are capped too, as are
+// compressed bodies once decoded.
+func (s *Server) readBody(body io.Reader) ([]byte, error) {
+	if s.maxBodySize <= 0 {
+		return io.ReadAll(body)
+	}
+	data, err := io.ReadAll(io.LimitReader(body, s.maxBodySize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > s.maxBodySize {
+		return nil, errBodyTooLarge
+	}
+	return data, nil
+}
+
+// bodyTooLargeMessage explains a rejection by the maximum body size.
+func (s *Server) bodyTooLargeMessage() string {
+	return fmt.Sprintf("Request body exceeds %d bytes", s.maxBodySize)
+}
+
+// decodeNDJSON decodes a stream of newline-delimited JSON values.
+func decodeNDJSON(r io.Reader) ([]interface{}, error) {
+	var records []interface{}
+	decoder := json.NewDecoder(r)
+	for {
+		var record interface{}
+		if err := decoder.Decode(&record); err == io.EOF {
+			return records, nil
+		} else if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+}
+
+// generate formats a schema and prepends the generated code comment when
+// enabled. Values nested deeper than the maximum depth are typed with the
+// format's catch-all type, with a warning logged, unless strict depth refuses
+// them.
+func (s *Server) generate(logger *log.Logger, sch *schema, source string) (string, error) {
+	formatted, err := s.formatLimited(logger, sch)
+	if err != nil {
+		return "", err
+	}
+	return s.withGeneratedComment(formatted, source), nil
+}
+
+// generateRecords is generate for the records decoded from body, reusing the
+// code generated for an identical body of the same media type when caching is
+// enabled.
+func (s *Server) generateRecords(logger *log.Logger, mediaType string, body []byte, records []interface{}, source string) (string, error) {
+	// TOML converts the data rather than its schema
+	if s.formatType == "toml" {
+		formatted, err := formatAsTOML(records)
+		if err != nil {
+			return "", err
+		}
+		return s.withGeneratedComment(formatted, source), nil
+	}
+	if s.cache == nil {
+		return s.generate(logger, inferRecords(records), source)
+	}
+
+	key := cacheKey(s.formatType, s.structName, mediaType, body)
+	formatted, cut, ok := s.cache.get(key)
+	if !ok {
+		var err error
+		formatted, cut, err = s.formatWithinLimits(inferRecords(records))
+		if err != nil {
+			return "", err
+		}
+		s.cache.add(key, formatted, cut)
+	}
+	if cut {
+		s.warnDepthCut(logger)
+	}
+	return s.withGeneratedComment(formatted, source), nil
+}
+
+// formatLimited formats a schema after applying the maximum depth, and
+// refuses schemas with more fields than the maximum, or nested deeper than the
+// maximum with strict depth.
+func (s *Server) formatLimited(logger *log.Logger, sch *schema) (string, error) {
+	formatted, cut, err := s.formatWithinLimits(sch)
+	if err != nil {
+		return "", err
+	}
+	if cut {
+		s.warnDepthCut(logger)
+	}
+	return formatted, nil
+}
+
+// formatWithinLimits is formatLimited without the warning, reporting instead
+// whether values were left untyped at the maximum depth.
+func (s *Server) formatWithinLimits(sch *schema) (string, bool, error) {
+	sch, cut := limitDepth(sch, s.maxDepth)
+	if cut && s.strictDepth {
+		return "", false, &depthLimitError{s.maxDepth}
+	}
+	if exceedsFields(sch, s.maxFields) {
+		return "", false, &fieldLimitError{s.maxFields}
+	}
+	formatted, err := s.formatSchema(sch)
+	return formatted, cut, err
+}
+
+// warnDepthCut logs that values nested deeper than the maximum depth were
+// typed with the format's catch-all type.
+func (s *Server) warnDepthCut(logger *log.Logger) {
+	logger.Printf("Warning: values nested deeper than %d levels are left untyped", s.maxDepth)
+}
+
+// fieldLimitError reports a schema with more fields than the maximum.
+type fieldLimitError struct {
+	maxFields int
+}
+
+func (e *fieldLimitError) Error() string {
+	return fmt.Sprintf("body has more than %d fields", e.maxFields)
+}
+
+// depthLimitError reports a schema nested deeper than the maximum when strict
+// depth refuses it.
+type depthLimitError struct {
+	maxDepth int
+}
+
+func (e *depthLimitError) Error() string {
+	return fmt.Sprintf("body is nested deeper than %d levels", e.maxDepth)
+}
+
+// withGeneratedComment prepends the generated code comment, when enabled.
+func (s *Server) withGeneratedComment(formatted, source string) string {
+	// PHP treats anything before the opening tag as output
+	if rest, ok := strings.CutPrefix(formatted, phpOpenTag); ok {
+		return phpOpenTag + s.generatedComment(source) + rest
+	}
+	return s.generatedComment(source) + formatted
+}
+
 func (s *Server) formatData(data interface{}) (string, error) {
+	return s.formatSchema(inferSchema(data))
+}
+
+func (s *Server) formatSchema(sch *schema) (string, error) {
+	if typeOverrideFormats[s.formatType] {
+		sch = withTypeOverrides(sch, s.typeOverrides)
+	}
 	switch s.formatType {
 	case "go":
-		return s.formatAsGo(data)
+		return s.formatAsGo(sch)
 	case "rust":
-		return s.formatAsRust(data)
+		return s.formatAsRust(sch)
+	case "typeddict":
+		return s.formatAsTypedDict(sch)
+	case "scala":
+		return s.formatAsScala(sch)
+	case "haskell":
+		return s.formatAsHaskell(sch)
+	case "zod":
+		return s.formatAsZod(sch)
+	case "openapi":
+		return s.formatAsOpenAPI(sch)
+	case "avro":
+		return s.formatAsAvro(sch)
+	case "dart":
+		return s.formatAsDart(sch)
+	case "c":
+		return s.formatAsC(sch)
+	case "elm":
+		return s.formatAsElm(sch)
+	case "php":
+		return s.formatAsPHP(sch)
+	case "ocaml":
+		return s.formatAsOCaml(sch)
+	case "fsharp":
+		return s.formatAsFSharp(sch)
+	case "ruby":
+		return s.formatAsRuby(sch)
+	case "crystal":
+		return s.formatAsCrystal(sch)
+	case "objc":
+		return s.formatAsObjC(sch)
+	case "mermaid":
+		return s.formatAsMermaid(sch)
+	case "thrift":
+		return s.formatAsThrift(sch)
+	case "kotlin":
+		return s.formatAsKotlin(sch)
+	case "schema-json":
+		return s.formatAsSchemaJSON(sch)
+	case "plantuml":
+		return s.formatAsPlantUML(sch)
+	case "clojure":
+		return s.formatAsClojure(sch)
+	case "flatbuffers":
+		return s.formatAsFlatbuffers(sch)
+	case "rbi":
+		return s.formatAsRBI(sch)
+	case "xsd":
+		return s.formatAsXSD(sch)
+	case "graphql":
+		return s.formatAsGraphQL(sch)
+	case "toml":
+		return "", errors.New("toml converts JSON values and cannot describe a schema")
 	default:
 		return "", fmt.Errorf("unsupported format type: %s", s.formatType)
 	}
 }
 
-func (s *Server) formatAsGo(data interface{}) (string, error) {
-	// Create Go struct representation
-	return fmt.Sprintf("type GeneratedStruct struct {\n%s}", s.generateGoFields(data)), nil
+func (s *Server) formatAsGo(sch *schema) (string, error) {
+	root := sch
+	if sch.kind != kindObject {
+		root = &schema{kind: kindObject, fields: []*field{{name: "data", schema: sch}}}
+	}
+
+	types := s.nestedObjects(s.structName, root)
+
+	// Emit any detected enums ahead of the structs that use them
+	var enums string
+	enumTypes, enumNames := s.enumTypes(types)
+	for _, enum := range enumTypes {
+		enums += formatGoEnum(enum.name, enum.values)
+	}
+	if s.usesGoDuration(types) && s.goDurationName == "" {
+		enums += formatGoDuration(s.goDurationType(types, enumNames))
+	}
+
+	// Flattened output inlines every nested object into the root struct
+	if s.flatten {
+		return s.goFile(types, enumNames) + enums + fmt.Sprintf("type %s struct {\n%s}", s.structName, s.generateGoFields(root, types, enumNames, "")), nil
+	}
+
+	// Create Go struct representation, one struct per nested object
+	structs := make([]string, 0, len(types.objects))
+	for _, obj := range types.objects {
+		structs = append(structs, fmt.Sprintf("type %s struct {\n%s}", obj.name, s.generateGoFields(obj.schema, types, enumNames, "")))
+	}
+	return s.goFile(types, enumNames) + enums + strings.Join(structs, "\n\n"), nil
 }
 
-func (s *Server) generateGoFields(data interface{}) string {
-	switch v := data.(type) {
-	case map[string]interface{}:
-		var result string
-		for key, val := range v {
-			fieldType := s.getGoType(val)
-			result += fmt.Sprintf("    %s %s `json:\"%s\"`\n", key, fieldType, key)
+// generateGoFields writes the fields of a struct, with each line prefixed by
+// indent for structs inlined into a parent.
+func (s *Server) generateGoFields(sch *schema, types *objectTypes, enumNames map[*field]string, indent string) string {
+	var result strings.Builder
+	used := make(map[string]bool, len(sch.fields))
+	for _, f := range sch.fields {
+		fieldType, asString := s.goFieldType(f, types, enumNames, indent)
+		omitEmpty := f.optional || s.pointers
+		// Keys such as "e" and "é" sanitize to the same name
+		name := uniqueName(goFieldName(f.name), used)
+		fmt.Fprintf(&result, "%s    %s %s `%s`%s\n", indent, name, fieldType, s.goStructTag(f.name, omitEmpty, asString), s.exampleComment(f.schema))
+	}
+	return result.String()
+}
+
+// goFieldType returns the type of a struct field, and whether the field is a
+// number quoted as a string, decoded with the ,string option.
+func (s *Server) goFieldType(f *field, types *objectTypes, enumNames map[*field]string, indent string) (string, bool) {
+	fieldType := s.getGoType(f.schema, types, enumNames, indent)
+	if s.isEnumField(f) {
+		fieldType = enumNames[f]
+		if f.schema.nullable {
+			fieldType = goPointer(fieldType)
+		}
+	}
+	asString := false
+	if s.isNumericString(f.schema) {
+		fieldType = s.goNumericStringType(f.schema)
+		// The ,string option decodes numbers quoted as strings, which
+		// json.Number accepts by itself
+		asString = fieldType != "json.Number"
+		if f.schema.nullable {
+			fieldType = goPointer(fieldType)
 		}
-		return result
-	default:
-		return "    Data interface{} `json:\"data\"`\n"
 	}
+	if s.isDuration(f.schema) {
+		fieldType = s.goDurationType(types, enumNames)
+		if f.schema.nullable {
+			fieldType = goPointer(fieldType)
+		}
+	}
+	if f.optional || s.pointers {
+		fieldType = goPointer(fieldType)
+	}
+	return fieldType, asString
 }
 
-func (s *Server) getGoType(v interface{}) string {
-	switch v.(type) {
-	case bool:
-		return "bool"
-	case float64:
+// goNumericStringType types a numeric string like a plain number: as the
+// -number-type when set, and otherwise as int when all samples are whole
+// numbers, sized with -narrow-ints, or float64.
+func (s *Server) goNumericStringType(sch *schema) string {
+	if numberType := s.numberTypeFor("go"); numberType != "" {
+		return numberType
+	}
+	switch bits := numericStringNumbers(sch).intBits(); {
+	case bits == 0:
 		return "float64"
-	case string:
-		return "string"
-	case []interface{}:
-		return "[]interface{}"
-	case map[string]interface{}:
-		return "map[string]interface{}"
-	case nil:
-		return "interface{}"
+	case !s.narrowInts:
+		return "int"
+	case bits == 32:
+		return "int32"
 	default:
-		return "interface{}"
+		return "int64"
+	}
+}
+
+// goStructTag writes the struct tag of a field, with one key per configured
+// tag. The ,string option only exists in encoding/json, so it is left out of
+// the other keys.
+func (s *Server) goStructTag(key string, omitEmpty, asString bool) string {
+	tags := s.goTags
+	if len(tags) == 0 {
+		tags = []string{"json"}
+	}
+
+	parts := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		value := key
+		if tag == "json" && asString {
+			value += ",string"
+		}
+		if omitEmpty {
+			value += ",omitempty"
+		}
+		parts = append(parts, fmt.Sprintf("%s:%q", tag, value))
+	}
+	return strings.Join(parts, " ")
+}
+
+func (s *Server) getGoType(sch *schema, types *objectTypes, enumNames map[*field]string, indent string) string {
+	if sch.override != "" {
+		return sch.override
+	}
+	var goType string
+	switch sch.kind {
+	case kindBool:
+		goType = "bool"
+	case kindNumber:
+		goType = "float64"
+		if numberType := s.numberTypeFor("go"); numberType != "" {
+			goType = numberType
+		} else if s.narrowInts {
+			switch sch.intBits() {
+			case 32:
+				goType = "int32"
+			case 64:
+				goType = "int64"
+			}
+		}
+	case kindString:
+		goType = "string"
+		if s.isUUID(sch) {
+			goType = "uuid.UUID"
+		} else if s.isIP(sch) {
+			goType = "net.IP"
+		} else if s.isCIDR(sch) {
+			goType = "*net.IPNet"
+		} else if s.isBase64(sch) {
+			// encoding/json decodes base64 into byte slices
+			goType = "[]byte"
+		}
+	case kindArray:
+		elemType := s.goAny()
+		if sch.elem != nil {
+			elemType = s.getGoType(sch.elem, types, enumNames, indent)
+		}
+		goType = "[]" + elemType
+	case kindObject:
+		goType = types.name(sch)
+		if s.flatten {
+			goType = "struct {\n" + s.generateGoFields(sch, types, enumNames, indent+"    ") + indent + "    }"
+		}
+	default:
+		goType = s.goAny()
+	}
+	if sch.nullable {
+		return goPointer(goType)
+	}
+	return goType
+}
+
+// goAny returns the Go type holding any value, spelled any with -go-any.
+func (s *Server) goAny() string {
+	if s.goAnyAlias {
+		return "any"
+	}
+	return "interface{}"
+}
+
+// goPointer makes a Go type nilable. Slices, maps and interfaces already are.
+func goPointer(goType string) string {
+	if goType == "any" {
+		return goType
+	}
+	for _, prefix := range []string{"*", "[]", "map[", "interface{}"} {
+		if strings.HasPrefix(goType, prefix) {
+			return goType
+		}
+	}
+	return "*" + goType
+}
+
+func (s *Server) formatAsRust(sch *schema) (string, error) {
+	root := sch
+	if sch.kind != kindObject {
+		root = &schema{kind: kindObject, fields: []*field{{name: "data", schema: sch}}}
+	}
+
+	types := s.nestedObjects(s.structName, root)
+
+	// Emit any detected enums ahead of the structs that use them
+	var enums string
+	enumTypes, enumNames := s.enumTypes(types)
+	for _, enum := range enumTypes {
+		enums += formatRustEnum(s.rustDerive(), s.rustVisibility(), enum.name, enum.values)
+	}
+
+	// Create Rust struct representation, one struct per nested object
+	structs := make([]string, 0, len(types.objects))
+	for _, obj := range types.objects {
+		// Base64 and numeric string fields rely on serde_with, which must wrap
+		// the derive
+		var serdeAs string
+		for _, f := range obj.schema.fields {
+			if s.rustSerdeAs(f) != "" {
+				serdeAs = "#[serde_with::serde_as]\n"
+				break
+			}
+		}
+		structs = append(structs, fmt.Sprintf("%s%s\n%sstruct %s {\n%s}", serdeAs, s.rustDerive(), s.rustVisibility(), obj.name, s.generateRustFields(obj.schema, types, enumNames)))
 	}
+	return enums + strings.Join(structs, "\n\n"), nil
 }
 
-func (s *Server) formatAsRust(data interface{}) (string, error) {
-	// Create Rust struct representation
-	return fmt.Sprintf("#[derive(Debug, Serialize, Deserialize)]\nstruct GeneratedStruct {\n%s}", s.generateRustFields(data)), nil
+func (s *Server) generateRustFields(sch *schema, types *objectTypes, enumNames map[*field]string) string {
+	var result strings.Builder
+	used := make(map[string]bool, len(sch.fields))
+	for _, f := range sch.fields {
+		fieldType := s.getRustType(f.schema, types)
+		if s.isEnumField(f) {
+			fieldType = enumNames[f]
+			if f.schema.nullable {
+				fieldType = rustOption(fieldType)
+			}
+		}
+		if s.isNumericString(f.schema) {
+			fieldType = s.rustNumericStringType(f.schema)
+			if f.schema.nullable {
+				fieldType = rustOption(fieldType)
+			}
+		}
+		if s.isDuration(f.schema) {
+			fieldType = "std::time::Duration"
+			if f.schema.nullable {
+				fieldType = rustOption(fieldType)
+			}
+		}
+		if f.optional {
+			fieldType = rustOption(fieldType)
+		}
+		name := uniqueName(rustFieldName(f.name), used)
+		if name != f.name {
+			fmt.Fprintf(&result, "    #[serde(rename = %q)]\n", f.name)
+		}
+		if s.isDuration(f.schema) {
+			// humantime_serde also handles Option<Duration>
+			result.WriteString("    #[serde(with = \"humantime_serde\")]\n")
+		}
+		if serdeAs := s.rustSerdeAs(f); serdeAs != "" {
+			fmt.Fprintf(&result, "    #[serde_as(as = \"%s\")]\n", serdeAs)
+		}
+		if strings.HasPrefix(fieldType, "Option<") {
+			// Absent fields stay absent when serialized again, and default
+			// keeps them decodable under with and serde_as adapters
+			result.WriteString("    #[serde(skip_serializing_if = \"Option::is_none\", default)]\n")
+		}
+		fmt.Fprintf(&result, "    %s%s: %s,%s\n", s.rustVisibility(), name, fieldType, s.exampleComment(f.schema))
+	}
+	return result.String()
 }
 
-func (s *Server) generateRustFields(data interface{}) string {
-	switch v := data.(type) {
-	case map[string]interface{}:
-		var result string
-		for key, val := range v {
-			fieldType := s.getRustType(val)
-			result += fmt.Sprintf("    #[serde(rename = \"%s\")]\n    %s: %s,\n", key, key, fieldType)
+func (s *Server) getRustType(sch *schema, types *objectTypes) string {
+	if sch.override != "" {
+		return sch.override
+	}
+	var rustType string
+	switch sch.kind {
+	case kindBool:
+		rustType = "bool"
+	case kindNumber:
+		rustType = "f64"
+		if numberType := s.numberTypeFor("rust"); numberType != "" {
+			rustType = numberType
+		} else if s.narrowInts {
+			switch sch.intBits() {
+			case 32:
+				rustType = "i32"
+			case 64:
+				rustType = "i64"
+			}
 		}
-		return result
+	case kindString:
+		rustType = "String"
+		if s.isUUID(sch) {
+			rustType = "uuid::Uuid"
+		} else if s.isIP(sch) {
+			rustType = "std::net::IpAddr"
+		} else if s.isCIDR(sch) {
+			rustType = "ipnet::IpNet"
+		} else if s.isBase64(sch) {
+			rustType = "Vec<u8>"
+		}
+	case kindArray:
+		elemType := "serde_json::Value"
+		if sch.elem != nil {
+			elemType = s.getRustType(sch.elem, types)
+		}
+		rustType = fmt.Sprintf("Vec<%s>", elemType)
+	case kindObject:
+		rustType = types.name(sch)
+	case kindNull:
+		rustType = "Option<serde_json::Value>"
 	default:
-		return "    data: serde_json::Value,\n"
+		rustType = "serde_json::Value"
+	}
+	if sch.nullable {
+		return rustOption(rustType)
 	}
+	return rustType
 }
 
-func (s *Server) getRustType(v interface{}) string {
-	switch v.(type) {
-	case bool:
-		return "bool"
-	case float64:
+// rustNumericStringType types a numeric string like a plain number: as the
+// -number-type when set, and otherwise as i64 when all samples are whole
+// numbers, narrowed to i32 with -narrow-ints, or f64.
+func (s *Server) rustNumericStringType(sch *schema) string {
+	if numberType := s.numberTypeFor("rust"); numberType != "" {
+		return numberType
+	}
+	switch bits := numericStringNumbers(sch).intBits(); {
+	case bits == 0:
 		return "f64"
-	case string:
-		return "String"
-	case []interface{}:
-		return "Vec<serde_json::Value>"
-	case map[string]interface{}:
-		return "serde_json::Map<String, serde_json::Value>"
-	case nil:
-		return "Option<serde_json::Value>"
+	case bits == 32 && s.narrowInts:
+		return "i32"
+	default:
+		return "i64"
+	}
+}
+
+// rustSerdeAs returns the serde_with adapter a field is converted with, or an
+// empty string when it needs none. Detectors are checked in the order the
+// field type is chosen in, so the adapter always matches the type.
+func (s *Server) rustSerdeAs(f *field) string {
+	var as string
+	switch {
+	case s.isNumericString(f.schema):
+		as = "serde_with::DisplayFromStr"
+	case s.isDuration(f.schema), s.isIP(f.schema), s.isCIDR(f.schema), s.isEnumField(f), s.isUUID(f.schema):
+		return ""
+	case s.isBase64(f.schema):
+		as = "serde_with::base64::Base64"
 	default:
-		return "serde_json::Value"
+		return ""
+	}
+	if f.optional || f.schema.nullable {
+		return "Option<" + as + ">"
+	}
+	return as
+}
+
+// rustDerive returns the derive attribute for generated Rust structs, with any
+// extra derives appended after the serde ones.
+func (s *Server) rustDerive() string {
+	derives := []string{"Debug", "Serialize", "Deserialize"}
+	for _, derive := range s.rustDerives {
+		if !slices.Contains(derives, derive) {
+			derives = append(derives, derive)
+		}
+	}
+	return fmt.Sprintf("#[derive(%s)]", strings.Join(derives, ", "))
+}
+
+// rustVisibility returns the visibility prefix for generated Rust items.
+func (s *Server) rustVisibility() string {
+	if s.rustPub {
+		return "pub "
+	}
+	return ""
+}
+
+// rustOption wraps a Rust type in Option unless it already is one.
+func rustOption(rustType string) string {
+	if strings.HasPrefix(rustType, "Option<") {
+		return rustType
 	}
+	return "Option<" + rustType + ">"
 }
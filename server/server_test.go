@@ -3,14 +3,20 @@ package server
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
+	"io"
 	"log"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"strings"
 	"testing"
+	"testing/iotest"
 	"time"
+
+	"golang.org/x/net/http2"
 )
 
 func TestServer_HandleRequest(t *testing.T) {
@@ -19,13 +25,19 @@ func TestServer_HandleRequest(t *testing.T) {
 		method         string
 		path           string
 		body           interface{}
+		rawBody        string
+		contentType    string
 		formatType     string
 		pretty         bool
 		headers        bool
+		quiet          bool
+		formatHeaders  bool
+		requestHeaders map[string][]string
 		expectedCode   int
 		expectJSON     bool
 		expectContains []string
 		expectLogs     []string
+		expectNoLogs   []string
 	}{
 		{
 			name:           "GET request without body",
@@ -83,11 +95,10 @@ func TestServer_HandleRequest(t *testing.T) {
 				`"value":123`,
 				"#[derive(Debug, Serialize, Deserialize)]",
 				"struct GeneratedStruct",
-				"#[serde(rename = \"name\")]",
 				"name: String",
-				"#[serde(rename = \"value\")]",
 				"value: f64",
 			},
+			expectNoLogs: []string{"#[serde(rename"},
 		},
 		{
 			name:         "POST request with JSON body - Pretty Print",
@@ -131,6 +142,187 @@ func TestServer_HandleRequest(t *testing.T) {
 				"Host: example.com",
 			},
 		},
+		{
+			name:         "POST request in quiet mode",
+			method:       "POST",
+			path:         "/api/data",
+			body:         map[string]interface{}{"name": "test"},
+			formatType:   "go",
+			quiet:        true,
+			expectedCode: http.StatusOK,
+			expectJSON:   true,
+			expectLogs: []string{
+				`JSON-Body: {"name":"test"}`,
+				"type GeneratedStruct struct {",
+			},
+			expectNoLogs: []string{"Received POST request"},
+		},
+		{
+			name:          "GET request with header struct",
+			method:        "GET",
+			path:          "/api/data",
+			formatType:    "go",
+			formatHeaders: true,
+			requestHeaders: map[string][]string{
+				"X-Api-Key": {"secret"},
+				"Accept":    {"application/json", "text/plain"},
+			},
+			expectedCode: http.StatusOK,
+			expectJSON:   true,
+			expectLogs: []string{
+				"Header struct format:",
+				"Accept []string `json:\"Accept\"`",
+				"X_Api_Key string `json:\"X-Api-Key\"`",
+			},
+		},
+		{
+			name:         "POST request with NDJSON body - Go format",
+			method:       "POST",
+			path:         "/api/logs",
+			rawBody:      "{\"level\":\"info\",\"msg\":\"started\"}\n{\"level\":\"error\",\"code\":500}\n",
+			contentType:  "application/x-ndjson",
+			formatType:   "go",
+			expectedCode: http.StatusOK,
+			expectJSON:   true,
+			expectLogs: []string{
+				`JSON-Body: {"level":"info","msg":"started"}`,
+				`JSON-Body: {"code":500,"level":"error"}`,
+				"code *float64 `json:\"code,omitempty\"`",
+				"level string `json:\"level\"`",
+				"msg *string `json:\"msg,omitempty\"`",
+			},
+		},
+		{
+			name:         "POST request with JSON:API media type",
+			method:       "POST",
+			path:         "/api/articles",
+			rawBody:      `{"data":{"type":"articles"}}`,
+			contentType:  "application/vnd.api+json",
+			expectedCode: http.StatusOK,
+			expectJSON:   true,
+			expectLogs:   []string{`JSON-Body: {"data":{"type":"articles"}}`},
+		},
+		{
+			name:         "PATCH request with merge patch media type",
+			method:       "PATCH",
+			path:         "/api/articles/1",
+			rawBody:      `{"title":"updated"}`,
+			contentType:  "application/merge-patch+json",
+			formatType:   "go",
+			expectedCode: http.StatusOK,
+			expectJSON:   true,
+			expectContains: []string{
+				`"method": "PATCH"`,
+			},
+			expectLogs: []string{
+				`JSON-Body: {"title":"updated"}`,
+				"title string `json:\"title\"`",
+			},
+		},
+		{
+			name:         "PUT request with charset parameter",
+			method:       "PUT",
+			path:         "/api/articles/1",
+			rawBody:      `{"title":"replaced"}`,
+			contentType:  "application/json; charset=utf-8",
+			expectedCode: http.StatusOK,
+			expectJSON:   true,
+			expectLogs:   []string{`JSON-Body: {"title":"replaced"}`},
+		},
+		{
+			name:         "POST request with malformed NDJSON body",
+			method:       "POST",
+			path:         "/api/logs",
+			rawBody:      "{\"level\":\"info\"}\n{\"level\":",
+			contentType:  "application/x-ndjson",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "POST request with JSON text sequence body - Go format",
+			method:       "POST",
+			path:         "/api/events",
+			rawBody:      "\x1e{\"type\":\"start\",\"at\":1}\n\x1e{\"type\":\"stop\",\"reason\":\"done\"}\n\x1e\n",
+			contentType:  "application/json-seq",
+			formatType:   "go",
+			expectedCode: http.StatusOK,
+			expectJSON:   true,
+			expectLogs: []string{
+				`JSON-Body: {"at":1,"type":"start"}`,
+				`JSON-Body: {"reason":"done","type":"stop"}`,
+				"at *float64 `json:\"at,omitempty\"`",
+				"reason *string `json:\"reason,omitempty\"`",
+				"type_ string `json:\"type\"`",
+			},
+		},
+		{
+			name:           "POST request with malformed JSON text sequence body",
+			method:         "POST",
+			path:           "/api/events",
+			rawBody:        "\x1e{\"type\":\"start\"}\n\x1e{\"type\" \"stop\"}\n",
+			contentType:    "application/json-seq",
+			expectedCode:   http.StatusBadRequest,
+			expectContains: []string{`Error parsing JSON text sequence: record 2: invalid character '"' after object key at offset 9 near "{\"type\" \"stop\"}\n"`},
+		},
+		{
+			name:         "POST request with CSV body - Go format",
+			method:       "POST",
+			path:         "/api/import",
+			rawBody:      "id,name,active,score\n1,alice,true,1.5\n2,bob,false,\n",
+			contentType:  "text/csv",
+			formatType:   "go",
+			expectedCode: http.StatusOK,
+			expectJSON:   true,
+			expectLogs: []string{
+				`JSON-Body: {"active":true,"id":1,"name":"alice","score":1.5}`,
+				"active bool `json:\"active\"`",
+				"id float64 `json:\"id\"`",
+				"name string `json:\"name\"`",
+				"score *float64 `json:\"score\"`",
+				"type Rows []GeneratedStruct",
+			},
+		},
+		{
+			name:           "POST request with struct name header",
+			method:         "POST",
+			path:           "/api/orders",
+			rawBody:        `{"id":1}`,
+			contentType:    "application/json",
+			formatType:     "rust",
+			requestHeaders: map[string][]string{"X-Struct-Name": {"Order"}},
+			expectedCode:   http.StatusOK,
+			expectJSON:     true,
+			expectLogs:     []string{"struct Order {"},
+			expectNoLogs:   []string{"GeneratedStruct"},
+		},
+		{
+			name:           "POST request with invalid struct name header",
+			method:         "POST",
+			path:           "/api/orders",
+			rawBody:        `{"id":1}`,
+			contentType:    "application/json",
+			formatType:     "go",
+			requestHeaders: map[string][]string{"X-Struct-Name": {"Order Item"}},
+			expectedCode:   http.StatusBadRequest,
+			expectContains: []string{`Invalid X-Struct-Name: "Order Item" is not an identifier`},
+		},
+		{
+			name:           "POST request with malformed JSON body",
+			method:         "POST",
+			path:           "/api/users",
+			rawBody:        `{"name": "test", "age": thirty}`,
+			contentType:    "application/json",
+			expectedCode:   http.StatusBadRequest,
+			expectContains: []string{`Error parsing JSON: invalid character 'h' in literal true (expecting 'r') at offset 26 near "\"test\", \"age\": thirty}"`},
+			expectLogs:     []string{"Error parsing JSON: invalid character 'h' in literal true (expecting 'r') at offset 26"},
+		},
+		{
+			name:         "POST request with ragged CSV body",
+			method:       "POST",
+			path:         "/api/import",
+			rawBody:      "id,name\n1\n",
+			contentType:  "text/csv",
+			expectedCode: http.StatusBadRequest,
+		},
 	}
 
 	for _, tt := range tests {
@@ -142,7 +334,7 @@ func TestServer_HandleRequest(t *testing.T) {
 			defer log.SetOutput(os.Stderr)
 
 			// Create a new server instance for each test
-			srv := New(8080, tt.formatType, tt.pretty, tt.headers)
+			srv := New(8080, tt.formatType, tt.pretty, tt.headers, WithQuiet(tt.quiet), WithFormatHeaders(tt.formatHeaders))
 
 			// Create a request
 			var bodyReader *bytes.Reader
@@ -153,13 +345,21 @@ func TestServer_HandleRequest(t *testing.T) {
 				}
 				bodyReader = bytes.NewReader(bodyBytes)
 			} else {
-				bodyReader = bytes.NewReader([]byte{})
+				bodyReader = bytes.NewReader([]byte(tt.rawBody))
 			}
 
 			req := httptest.NewRequest(tt.method, tt.path, bodyReader)
 			if tt.body != nil {
 				req.Header.Set("Content-Type", "application/json")
 			}
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			for name, values := range tt.requestHeaders {
+				for _, value := range values {
+					req.Header.Add(name, value)
+				}
+			}
 
 			// Create a ResponseRecorder to record the response
 			rr := httptest.NewRecorder()
@@ -181,7 +381,7 @@ func TestServer_HandleRequest(t *testing.T) {
 			}
 
 			// Check response body contains expected strings
-			responseBody := rr.Body.String()
+			responseBody := responseText(rr.Body.String())
 			for _, expect := range tt.expectContains {
 				if !strings.Contains(responseBody, expect) {
 					t.Errorf("Response body does not contain expected string: %s\nGot: %s", expect, responseBody)
@@ -195,6 +395,11 @@ func TestServer_HandleRequest(t *testing.T) {
 					t.Errorf("Log output does not contain expected string: %s\nGot: %s", expect, logOutput)
 				}
 			}
+			for _, unexpected := range tt.expectNoLogs {
+				if strings.Contains(logOutput, unexpected) {
+					t.Errorf("Log output contains unexpected string: %s\nGot: %s", unexpected, logOutput)
+				}
+			}
 		})
 	}
 }
@@ -222,15 +427,231 @@ func TestServer_Start(t *testing.T) {
 	}
 }
 
+func TestServer_Version(t *testing.T) {
+	srv := New(8080, "", false, false, WithVersion("1.2.3"))
+
+	req := httptest.NewRequest("GET", "/version", nil)
+	rr := httptest.NewRecorder()
+	srv.httpServer().Handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("Handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+	if contentType := rr.Header().Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("Handler returned wrong content type: got %v want application/json", contentType)
+	}
+	if body := strings.TrimSpace(rr.Body.String()); body != `{"version":"1.2.3"}` {
+		t.Errorf("Handler returned wrong body: got %s", body)
+	}
+}
+
+func TestServer_Format(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		target         string
+		body           string
+		formatType     string
+		structName     string
+		expectedCode   int
+		expectContains []string
+	}{
+		{
+			name:           "Go via lang parameter",
+			method:         "POST",
+			target:         "/format?lang=go",
+			body:           `{"name":"test","value":123}`,
+			expectedCode:   http.StatusOK,
+			expectContains: []string{"type GeneratedStruct struct {", "name string `json:\"name\"`"},
+		},
+		{
+			name:           "Server format as default",
+			method:         "POST",
+			target:         "/format",
+			body:           `{"name":"test"}`,
+			formatType:     "rust",
+			expectedCode:   http.StatusOK,
+			expectContains: []string{"struct GeneratedStruct {", "name: String,"},
+		},
+		{
+			name:         "Missing lang",
+			method:       "POST",
+			target:       "/format",
+			body:         `{"name":"test"}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:           "Unsupported lang",
+			method:         "POST",
+			target:         "/format?lang=cobol",
+			body:           `{"name":"test"}`,
+			expectedCode:   http.StatusBadRequest,
+			expectContains: []string{"unsupported format type: cobol"},
+		},
+		{
+			name:         "Invalid JSON",
+			method:       "POST",
+			target:       "/format?lang=go",
+			body:         `{"name":`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:           "JSON syntax error",
+			method:         "POST",
+			target:         "/format?lang=go",
+			body:           `{"name": 'test'}`,
+			expectedCode:   http.StatusBadRequest,
+			expectContains: []string{`Error parsing JSON: invalid character '\'' looking for beginning of value at offset 10 near "{\"name\": 'test'}"`},
+		},
+		{
+			name:         "GET not allowed",
+			method:       "GET",
+			target:       "/format?lang=go",
+			expectedCode: http.StatusMethodNotAllowed,
+		},
+		{
+			name:           "Struct name header",
+			method:         "POST",
+			target:         "/format?lang=go",
+			body:           `{"name":"test"}`,
+			structName:     "Order",
+			expectedCode:   http.StatusOK,
+			expectContains: []string{"type Order struct {"},
+		},
+		{
+			name:         "Invalid struct name header",
+			method:       "POST",
+			target:       "/format?lang=go",
+			body:         `{"name":"test"}`,
+			structName:   "1Order",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := New(8080, tt.formatType, false, false)
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.structName != "" {
+				req.Header.Set("X-Struct-Name", tt.structName)
+			}
+			rr := httptest.NewRecorder()
+			srv.httpServer().Handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectedCode {
+				t.Errorf("Handler returned wrong status code: got %v want %v", rr.Code, tt.expectedCode)
+			}
+			if tt.expectedCode == http.StatusOK {
+				if contentType := rr.Header().Get("Content-Type"); contentType != "text/plain; charset=utf-8" {
+					t.Errorf("Handler returned wrong content type: got %v want text/plain", contentType)
+				}
+			}
+			for _, expect := range tt.expectContains {
+				if !strings.Contains(responseText(rr.Body.String()), expect) {
+					t.Errorf("Response does not contain expected string: %s\nGot: %s", expect, rr.Body.String())
+				}
+			}
+		})
+	}
+}
+
+func TestServer_FormatLiteral(t *testing.T) {
+	tests := []struct {
+		name          string
+		formatType    string
+		data          string
+		expectErr     string
+		expectContain string
+	}{
+		{
+			name:          "Valid JSON",
+			formatType:    "go",
+			data:          `{"a":1}`,
+			expectContain: "a float64 `json:\"a\"`",
+		},
+		{
+			name:       "Invalid JSON",
+			formatType: "go",
+			data:       `{"a":`,
+			expectErr:  "invalid JSON: unexpected EOF",
+		},
+		{
+			name:       "Empty input",
+			formatType: "go",
+			data:       "  ",
+			expectErr:  "invalid JSON: no value found",
+		},
+		{
+			name:      "No format",
+			data:      `{"a":1}`,
+			expectErr: "no format type configured",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := New(8080, tt.formatType, false, false)
+			result, err := srv.Format([]byte(tt.data), "-json")
+			if tt.expectErr != "" {
+				if err == nil || err.Error() != tt.expectErr {
+					t.Fatalf("Format() error = %v, want %s", err, tt.expectErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Format() error = %v", err)
+			}
+			if !strings.Contains(result, tt.expectContain) {
+				t.Errorf("Format() result does not contain expected string: %s\nGot: %s", tt.expectContain, result)
+			}
+		})
+	}
+}
+
+func TestServer_Timeouts(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []Option
+		expectRead  time.Duration
+		expectWrite time.Duration
+	}{
+		{
+			name:        "Defaults",
+			expectRead:  30 * time.Second,
+			expectWrite: 30 * time.Second,
+		},
+		{
+			name:        "Custom timeouts",
+			opts:        []Option{WithReadTimeout(5 * time.Second), WithWriteTimeout(time.Minute)},
+			expectRead:  5 * time.Second,
+			expectWrite: time.Minute,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := New(8080, "", false, false, tt.opts...).httpServer()
+			if server.ReadTimeout != tt.expectRead {
+				t.Errorf("ReadTimeout = %v, want %v", server.ReadTimeout, tt.expectRead)
+			}
+			if server.WriteTimeout != tt.expectWrite {
+				t.Errorf("WriteTimeout = %v, want %v", server.WriteTimeout, tt.expectWrite)
+			}
+		})
+	}
+}
+
 func TestFormatJSON(t *testing.T) {
 	testData := map[string]interface{}{
-		"name":  "test",
-		"value": 123,
+		"name":    "test",
+		"value":   123,
+		"snippet": "<b>Tom & Jerry</b>",
 	}
 
 	tests := []struct {
 		name           string
 		pretty         bool
+		indent         string
 		expectContains []string
 	}{
 		{
@@ -240,6 +661,7 @@ func TestFormatJSON(t *testing.T) {
 				"JSON-Body:",
 				`"name":"test"`,
 				`"value":123`,
+				`"snippet":"<b>Tom & Jerry</b>"`,
 			},
 		},
 		{
@@ -250,13 +672,27 @@ func TestFormatJSON(t *testing.T) {
 				"JSON END",
 				`"name": "test"`,
 				`"value": 123`,
+				`"snippet": "<b>Tom & Jerry</b>"`,
+			},
+		},
+		{
+			name:   "Pretty JSON with tab indent",
+			pretty: true,
+			indent: "\t",
+			expectContains: []string{
+				"{\n\t\"name\": \"test\"",
+				"\n\t\"value\": 123",
 			},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			srv := New(8080, "", tt.pretty, false)
+			var opts []Option
+			if tt.indent != "" {
+				opts = append(opts, WithIndent(tt.indent))
+			}
+			srv := New(8080, "", tt.pretty, false, opts...)
 			result := srv.formatJSON(testData)
 
 			for _, expect := range tt.expectContains {
@@ -282,6 +718,7 @@ func TestFormatData(t *testing.T) {
 	tests := []struct {
 		name           string
 		formatType     string
+		opts           []Option
 		expectContains []string
 	}{
 		{
@@ -292,6 +729,21 @@ func TestFormatData(t *testing.T) {
 				"string_field string",
 				"number_field float64",
 				"bool_field bool",
+				"array_field []float64",
+				"object_field ObjectField `json:\"object_field\"`",
+				"type ObjectField struct {\n    nested string `json:\"nested\"`\n}",
+			},
+		},
+		{
+			name:       "Go format with pointers",
+			formatType: "go",
+			opts:       []Option{WithPointers(true)},
+			expectContains: []string{
+				"string_field *string `json:\"string_field,omitempty\"`",
+				"number_field *float64 `json:\"number_field,omitempty\"`",
+				"array_field []float64 `json:\"array_field,omitempty\"`",
+				"object_field *ObjectField `json:\"object_field,omitempty\"`",
+				"nested *string `json:\"nested,omitempty\"`",
 			},
 		},
 		{
@@ -303,13 +755,26 @@ func TestFormatData(t *testing.T) {
 				"string_field: String",
 				"number_field: f64",
 				"bool_field: bool",
+				"array_field: Vec<f64>",
+				"object_field: ObjectField",
+				"struct ObjectField {",
+			},
+		},
+		{
+			name:       "Rust format with derives and pub",
+			formatType: "rust",
+			opts:       []Option{WithRustDerives([]string{"Clone", "Debug", "PartialEq"}), WithRustPub(true)},
+			expectContains: []string{
+				"#[derive(Debug, Serialize, Deserialize, Clone, PartialEq)]\npub struct GeneratedStruct {",
+				"pub string_field: String",
+				"pub struct ObjectField {",
 			},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			srv := New(8080, tt.formatType, false, false)
+			srv := New(8080, tt.formatType, false, false, tt.opts...)
 			result, err := srv.formatData(testData)
 			if err != nil {
 				t.Errorf("formatData() error = %v", err)
@@ -324,3 +789,703 @@ func TestFormatData(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatData_Flatten(t *testing.T) {
+	data := map[string]interface{}{
+		"id":       1.0,
+		"billing":  map[string]interface{}{"city": "Paris"},
+		"shipping": map[string]interface{}{"city": "Lyon"},
+		"items": []interface{}{
+			map[string]interface{}{"sku": "a", "meta": map[string]interface{}{"gift": true}},
+			map[string]interface{}{"sku": "b"},
+		},
+	}
+
+	srv := New(8080, "go", false, false, WithFlatten(true))
+	result, err := srv.formatData(data)
+	if err != nil {
+		t.Fatalf("formatData() error = %v", err)
+	}
+
+	// Identically shaped objects are inlined each time rather than shared
+	expect := "type GeneratedStruct struct {\n" +
+		"    billing struct {\n" +
+		"        city string `json:\"city\"`\n" +
+		"    } `json:\"billing\"`\n" +
+		"    id float64 `json:\"id\"`\n" +
+		"    items []struct {\n" +
+		"        meta *struct {\n" +
+		"            gift bool `json:\"gift\"`\n" +
+		"        } `json:\"meta,omitempty\"`\n" +
+		"        sku string `json:\"sku\"`\n" +
+		"    } `json:\"items\"`\n" +
+		"    shipping struct {\n" +
+		"        city string `json:\"city\"`\n" +
+		"    } `json:\"shipping\"`\n" +
+		"}"
+	if result != expect {
+		t.Errorf("formatData() = %s\nwant %s", result, expect)
+	}
+}
+
+func TestFormatData_GoTags(t *testing.T) {
+	tests := []struct {
+		name           string
+		opts           []Option
+		records        []interface{}
+		expectContains []string
+	}{
+		{
+			name:           "json by default",
+			records:        []interface{}{map[string]interface{}{"id": 1.0}},
+			expectContains: []string{"    id float64 `json:\"id\"`\n"},
+		},
+		{
+			name:           "One key per tag",
+			opts:           []Option{WithGoTags([]string{"json", "bson", "yaml"})},
+			records:        []interface{}{map[string]interface{}{"id": 1.0}},
+			expectContains: []string{"    id float64 `json:\"id\" bson:\"id\" yaml:\"id\"`\n"},
+		},
+		{
+			name: "Options on every key, except json only ,string",
+			opts: []Option{WithGoTags([]string{"json", "bson"}), WithCoerceNumericStrings(true)},
+			records: []interface{}{
+				map[string]interface{}{"id": 1.0, "price": "1.5", "note": "x"},
+				map[string]interface{}{"id": 2.0, "price": "2"},
+			},
+			expectContains: []string{
+				"    note *string `json:\"note,omitempty\" bson:\"note,omitempty\"`\n",
+				"    price float64 `json:\"price,string\" bson:\"price\"`\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := New(8080, "go", false, false, tt.opts...)
+			result, err := srv.formatSchema(inferRecords(tt.records))
+			if err != nil {
+				t.Fatalf("formatSchema() error = %v", err)
+			}
+
+			for _, expect := range tt.expectContains {
+				if !strings.Contains(result, expect) {
+					t.Errorf("formatSchema() result does not contain expected string: %s\nGot: %s", expect, result)
+				}
+			}
+		})
+	}
+}
+
+func TestFormatData_NarrowInts(t *testing.T) {
+	records := []interface{}{
+		map[string]interface{}{
+			"count": 1.0,
+			"total": 1.0,
+			"ratio": 1.0,
+			"huge":  1.0,
+			"codes": []interface{}{1.0, -2147483648.0},
+			"ids":   []interface{}{1.0, 2147483648.0},
+		},
+		map[string]interface{}{
+			"count": 2147483647.0,
+			"total": 5000000000.0,
+			"ratio": 0.5,
+			"huge":  1e20,
+		},
+	}
+
+	tests := []struct {
+		name           string
+		formatType     string
+		opts           []Option
+		expectContains []string
+	}{
+		{
+			name:       "Go",
+			formatType: "go",
+			opts:       []Option{WithNarrowInts(true)},
+			expectContains: []string{
+				"    codes []int32 `json:\"codes,omitempty\"`\n",
+				"    count int32 `json:\"count\"`\n",
+				"    huge float64 `json:\"huge\"`\n",
+				"    ids []int64 `json:\"ids,omitempty\"`\n",
+				"    ratio float64 `json:\"ratio\"`\n",
+				"    total int64 `json:\"total\"`\n",
+			},
+		},
+		{
+			name:       "Rust",
+			formatType: "rust",
+			opts:       []Option{WithNarrowInts(true)},
+			expectContains: []string{
+				"    codes: Option<Vec<i32>>,\n",
+				"    count: i32,\n",
+				"    huge: f64,\n",
+				"    ids: Option<Vec<i64>>,\n",
+				"    ratio: f64,\n",
+				"    total: i64,\n",
+			},
+		},
+		{
+			name:           "Float64 without the option",
+			formatType:     "go",
+			expectContains: []string{"    count float64 `json:\"count\"`\n", "    total float64 `json:\"total\"`\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := New(8080, tt.formatType, false, false, tt.opts...)
+			result, err := srv.formatSchema(inferRecords(records))
+			if err != nil {
+				t.Fatalf("formatSchema() error = %v", err)
+			}
+
+			for _, expect := range tt.expectContains {
+				if !strings.Contains(result, expect) {
+					t.Errorf("formatSchema() result does not contain expected string: %s\nGot: %s", expect, result)
+				}
+			}
+		})
+	}
+}
+
+func TestFormatData_GoAny(t *testing.T) {
+	data := map[string]interface{}{
+		"empty": []interface{}{},
+		"mixed": []interface{}{1.0, "a"},
+		"note":  nil,
+	}
+
+	tests := []struct {
+		name           string
+		data           interface{}
+		opts           []Option
+		expectContains []string
+		expectMissing  []string
+	}{
+		{
+			name: "any",
+			data: data,
+			opts: []Option{WithGoAny(true), WithPointers(true)},
+			expectContains: []string{
+				"    empty []any `json:\"empty,omitempty\"`\n",
+				"    mixed []any `json:\"mixed,omitempty\"`\n",
+				"    note any `json:\"note,omitempty\"`\n",
+			},
+			expectMissing: []string{"interface{}", "*any"},
+		},
+		{
+			name:           "Untyped root",
+			data:           nil,
+			opts:           []Option{WithGoAny(true)},
+			expectContains: []string{"    data any `json:\"data\"`\n"},
+		},
+		{
+			name:           "interface{} without the option",
+			data:           data,
+			expectContains: []string{"    empty []interface{} `json:\"empty\"`\n", "    note interface{} `json:\"note\"`\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := New(8080, "go", false, false, tt.opts...)
+			result, err := srv.formatData(tt.data)
+			if err != nil {
+				t.Fatalf("formatData() error = %v", err)
+			}
+
+			for _, expect := range tt.expectContains {
+				if !strings.Contains(result, expect) {
+					t.Errorf("formatData() result does not contain expected string: %s\nGot: %s", expect, result)
+				}
+			}
+			for _, missing := range tt.expectMissing {
+				if strings.Contains(result, missing) {
+					t.Errorf("formatData() result contains unexpected string: %s\nGot: %s", missing, result)
+				}
+			}
+		})
+	}
+}
+
+func TestFormatData_NumberType(t *testing.T) {
+	data := map[string]interface{}{
+		"count":  1.0,
+		"ratio":  0.5,
+		"scores": []interface{}{1.0, 2.5},
+	}
+
+	tests := []struct {
+		name           string
+		formatType     string
+		opts           []Option
+		expectContains []string
+	}{
+		{
+			name:       "Go",
+			formatType: "go",
+			opts:       []Option{WithNumberType("json.Number")},
+			expectContains: []string{
+				"    count json.Number `json:\"count\"`\n",
+				"    ratio json.Number `json:\"ratio\"`\n",
+				"    scores []json.Number `json:\"scores\"`\n",
+			},
+		},
+		{
+			name:       "Go overrides narrow ints",
+			formatType: "go",
+			opts:       []Option{WithNumberType("float32"), WithNarrowInts(true)},
+			expectContains: []string{
+				"    count float32 `json:\"count\"`\n",
+				"    ratio float32 `json:\"ratio\"`\n",
+			},
+		},
+		{
+			name:       "Rust",
+			formatType: "rust",
+			opts:       []Option{WithNumberType("i64")},
+			expectContains: []string{
+				"    count: i64,\n",
+				"    ratio: i64,\n",
+				"    scores: Vec<i64>,\n",
+			},
+		},
+		{
+			name:           "Type invalid for the format is ignored",
+			formatType:     "rust",
+			opts:           []Option{WithNumberType("int64")},
+			expectContains: []string{"    count: f64,\n", "    ratio: f64,\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := New(8080, tt.formatType, false, false, tt.opts...)
+			result, err := srv.formatData(data)
+			if err != nil {
+				t.Fatalf("formatData() error = %v", err)
+			}
+
+			for _, expect := range tt.expectContains {
+				if !strings.Contains(result, expect) {
+					t.Errorf("formatData() result does not contain expected string: %s\nGot: %s", expect, result)
+				}
+			}
+		})
+	}
+}
+
+func TestServer_MaxFields(t *testing.T) {
+	// Three fields: id, owner and owner.name
+	body := `{"id":1,"owner":{"name":"alice"}}`
+	tests := []struct {
+		name           string
+		maxFields      int
+		target         string
+		expectedCode   int
+		expectContains string
+	}{
+		{name: "At the limit", maxFields: 3, target: "/api/data", expectedCode: http.StatusOK},
+		{
+			name:           "Over the limit",
+			maxFields:      2,
+			target:         "/api/data",
+			expectedCode:   http.StatusBadRequest,
+			expectContains: "Error formatting data: body has more than 2 fields",
+		},
+		{name: "Format endpoint at the limit", maxFields: 3, target: "/format", expectedCode: http.StatusOK},
+		{
+			name:           "Format endpoint over the limit",
+			maxFields:      2,
+			target:         "/format",
+			expectedCode:   http.StatusBadRequest,
+			expectContains: "body has more than 2 fields",
+		},
+		{name: "No limit", maxFields: 0, target: "/api/data", expectedCode: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := New(8080, "go", false, false, WithQuiet(true), WithMaxFields(tt.maxFields))
+			req := httptest.NewRequest("POST", tt.target, strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			rr := httptest.NewRecorder()
+			srv.httpServer().Handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectedCode {
+				t.Errorf("Handler returned wrong status code: got %v want %v", rr.Code, tt.expectedCode)
+			}
+			if !strings.Contains(responseText(rr.Body.String()), tt.expectContains) {
+				t.Errorf("Response body does not contain expected string: %s\nGot: %s", tt.expectContains, rr.Body.String())
+			}
+		})
+	}
+
+	srv := New(8080, "go", false, false, WithMaxFields(2))
+	if _, err := srv.Format([]byte(body), "-json"); err == nil || err.Error() != "body has more than 2 fields" {
+		t.Errorf("Format() error = %v, want body has more than 2 fields", err)
+	}
+}
+
+func TestServer_StrictDepth(t *testing.T) {
+	// Three levels: the body, owner and owner.address
+	body := `{"owner":{"address":{"city":"Paris"}}}`
+	tests := []struct {
+		name           string
+		maxDepth       int
+		strict         bool
+		target         string
+		expectedCode   int
+		expectContains string
+	}{
+		{name: "At the limit", maxDepth: 3, strict: true, target: "/api/data", expectedCode: http.StatusOK},
+		{
+			name:           "Over the limit",
+			maxDepth:       2,
+			strict:         true,
+			target:         "/api/data",
+			expectedCode:   http.StatusBadRequest,
+			expectContains: "Error formatting data: body is nested deeper than 2 levels",
+		},
+		{
+			name:           "Format endpoint over the limit",
+			maxDepth:       2,
+			strict:         true,
+			target:         "/format",
+			expectedCode:   http.StatusBadRequest,
+			expectContains: "body is nested deeper than 2 levels",
+		},
+		{name: "Over the limit without strict depth", maxDepth: 2, target: "/api/data", expectedCode: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := New(8080, "go", false, false, WithQuiet(true), WithMaxDepth(tt.maxDepth), WithStrictDepth(tt.strict))
+			req := httptest.NewRequest("POST", tt.target, strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			rr := httptest.NewRecorder()
+			srv.httpServer().Handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectedCode {
+				t.Errorf("Handler returned wrong status code: got %v want %v", rr.Code, tt.expectedCode)
+			}
+			if !strings.Contains(responseText(rr.Body.String()), tt.expectContains) {
+				t.Errorf("Response body does not contain expected string: %s\nGot: %s", tt.expectContains, rr.Body.String())
+			}
+		})
+	}
+
+	srv := New(8080, "go", false, false, WithMaxDepth(2), WithStrictDepth(true))
+	if _, err := srv.Format([]byte(body), "-json"); err == nil || err.Error() != "body is nested deeper than 2 levels" {
+		t.Errorf("Format() error = %v, want body is nested deeper than 2 levels", err)
+	}
+}
+
+func TestRustRename(t *testing.T) {
+	srv := New(8080, "rust", false, false)
+	result, err := srv.formatData(map[string]interface{}{
+		"user_id":   "42",
+		"user-name": "alice",
+	})
+	if err != nil {
+		t.Fatalf("formatData() error = %v", err)
+	}
+
+	if strings.Contains(result, `#[serde(rename = "user_id")]`) {
+		t.Errorf("formatData() renamed a snake_case key\nGot: %s", result)
+	}
+	expect := "    #[serde(rename = \"user-name\")]\n    user_name: String,"
+	if !strings.Contains(result, expect) {
+		t.Errorf("formatData() result does not contain expected string: %s\nGot: %s", expect, result)
+	}
+
+	// Quotes in keys are escaped in the attribute
+	result, err = srv.formatData(map[string]interface{}{`na"me`: "alice"})
+	if err != nil {
+		t.Fatalf("formatData() error = %v", err)
+	}
+	expect = "    #[serde(rename = \"na\\\"me\")]\n    na_me: String,"
+	if !strings.Contains(result, expect) {
+		t.Errorf("formatData() result does not contain expected string: %s\nGot: %s", expect, result)
+	}
+}
+
+func TestRustOptionalFields(t *testing.T) {
+	srv := New(8080, "rust", false, false)
+	result, err := srv.formatSchema(inferRecords([]interface{}{
+		map[string]interface{}{"id": 1.0, "email": nil, "note": "x", "tags": []interface{}{"a"}},
+		map[string]interface{}{"id": 2.0, "email": "x@example.com", "tags": []interface{}{}},
+	}))
+	if err != nil {
+		t.Fatalf("formatSchema() error = %v", err)
+	}
+
+	expect := "#[derive(Debug, Serialize, Deserialize)]\n" +
+		"struct GeneratedStruct {\n" +
+		"    #[serde(skip_serializing_if = \"Option::is_none\", default)]\n" +
+		"    email: Option<String>,\n" +
+		"    id: f64,\n" +
+		"    #[serde(skip_serializing_if = \"Option::is_none\", default)]\n" +
+		"    note: Option<String>,\n" +
+		"    tags: Vec<String>,\n" +
+		"}"
+	if result != expect {
+		t.Errorf("formatSchema() = %s\nwant %s", result, expect)
+	}
+}
+
+func TestServer_RequestID(t *testing.T) {
+	tests := []struct {
+		name      string
+		requestID string
+		expectID  string
+	}{
+		{
+			name:      "Incoming header is honored",
+			requestID: "abc-123",
+			expectID:  "abc-123",
+		},
+		{
+			name: "Missing header generates an ID",
+		},
+		{
+			name:      "Unsafe header is replaced",
+			requestID: "bad\nid",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logBuf bytes.Buffer
+			log.SetOutput(&logBuf)
+			defer log.SetOutput(os.Stderr)
+
+			srv := New(8080, "go", false, false)
+
+			req := httptest.NewRequest("POST", "/api/data", strings.NewReader(`{"name":"test"}`))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.requestID != "" {
+				req.Header.Set("X-Request-ID", tt.requestID)
+			}
+			rr := httptest.NewRecorder()
+			srv.handleRequest(rr, req)
+
+			var response map[string]interface{}
+			if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to parse response: %v", err)
+			}
+			id, _ := response["request_id"].(string)
+			if id == "" {
+				t.Fatalf("Response does not contain a request_id: %s", rr.Body.String())
+			}
+			if tt.expectID != "" && id != tt.expectID {
+				t.Errorf("request_id = %q, want %q", id, tt.expectID)
+			}
+			if tt.expectID == "" && id == tt.requestID {
+				t.Errorf("request_id = %q, want a generated ID", id)
+			}
+			if got := rr.Header().Get("X-Request-ID"); got != id {
+				t.Errorf("X-Request-ID header = %q, want %q", got, id)
+			}
+
+			prefix := "[" + id + "] "
+			for _, line := range []string{prefix + "Received POST request to /api/data", prefix + `JSON-Body: {"name":"test"}`, prefix + "Struct format:"} {
+				if !strings.Contains(logBuf.String(), line) {
+					t.Errorf("Log output does not contain expected string: %s\nGot: %s", line, logBuf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestServer_H2C(t *testing.T) {
+	srv := New(8080, "", false, false, WithQuiet(true), WithH2C(true))
+	ts := httptest.NewServer(srv.httpServer().Handler)
+	defer ts.Close()
+
+	// Prior knowledge HTTP/2 over a plain TCP connection
+	h2Client := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, addr)
+		},
+	}}
+
+	for name, client := range map[string]*http.Client{"HTTP/1.1": ts.Client(), "HTTP/2.0": h2Client} {
+		t.Run(name, func(t *testing.T) {
+			resp, err := client.Get(ts.URL + "/version")
+			if err != nil {
+				t.Fatalf("GET /version error = %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("handler returned wrong status code: got %v want %v", resp.StatusCode, http.StatusOK)
+			}
+			if resp.Proto != name {
+				t.Errorf("response protocol = %s want %s", resp.Proto, name)
+			}
+		})
+	}
+}
+
+func TestServer_ChunkedBody(t *testing.T) {
+	body := `{"name":"test","tags":["a","b"]}`
+
+	tests := []struct {
+		name           string
+		path           string
+		maxBodySize    int64
+		expectedCode   int
+		expectContains string
+	}{
+		{
+			name:           "Echo handler without a limit",
+			path:           "/api/data",
+			expectedCode:   http.StatusOK,
+			expectContains: "Request processed successfully",
+		},
+		{
+			name:           "Echo handler under the limit",
+			path:           "/api/data",
+			maxBodySize:    int64(len(body)),
+			expectedCode:   http.StatusOK,
+			expectContains: "Request processed successfully",
+		},
+		{
+			name:           "Echo handler over the limit",
+			path:           "/api/data",
+			maxBodySize:    int64(len(body)) - 1,
+			expectedCode:   http.StatusRequestEntityTooLarge,
+			expectContains: "Request body exceeds 31 bytes",
+		},
+		{
+			name:           "Format handler under the limit",
+			path:           "/format?lang=go",
+			maxBodySize:    1024,
+			expectedCode:   http.StatusOK,
+			expectContains: "tags []string `json:\"tags\"`",
+		},
+		{
+			name:           "Format handler over the limit",
+			path:           "/format?lang=go",
+			maxBodySize:    8,
+			expectedCode:   http.StatusRequestEntityTooLarge,
+			expectContains: "Request body exceeds 8 bytes",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Record how the body arrived, to be sure it really was chunked
+			var transferEncoding []string
+			var contentLength int64
+			record := func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					transferEncoding, contentLength = r.TransferEncoding, r.ContentLength
+					next.ServeHTTP(w, r)
+				})
+			}
+
+			srv := New(8080, "go", false, false, WithQuiet(true), WithMaxBodySize(tt.maxBodySize), WithMiddleware(record))
+			ts := httptest.NewServer(srv.httpServer().Handler)
+			defer ts.Close()
+
+			// A reader of unknown length makes the client send the body chunked,
+			// one byte at a time
+			req, err := http.NewRequest("POST", ts.URL+tt.path, iotest.OneByteReader(strings.NewReader(body)))
+			if err != nil {
+				t.Fatalf("NewRequest() error = %v", err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := ts.Client().Do(req)
+			if err != nil {
+				t.Fatalf("POST %s error = %v", tt.path, err)
+			}
+			defer resp.Body.Close()
+			respBody, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("reading response error = %v", err)
+			}
+
+			if len(transferEncoding) != 1 || transferEncoding[0] != "chunked" || contentLength != -1 {
+				t.Errorf("request arrived with Transfer-Encoding %v and Content-Length %d, want chunked and unknown", transferEncoding, contentLength)
+			}
+			if resp.StatusCode != tt.expectedCode {
+				t.Errorf("handler returned wrong status code: got %v want %v", resp.StatusCode, tt.expectedCode)
+			}
+			if !strings.Contains(string(respBody), tt.expectContains) {
+				t.Errorf("Response body does not contain expected string: %s\nGot: %s", tt.expectContains, respBody)
+			}
+		})
+	}
+}
+
+func TestServer_Once(t *testing.T) {
+	srv := New(8080, "", false, false, WithQuiet(true), WithOnce(true))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	srv.stop = cancel
+
+	// Failed requests keep the server running
+	req := httptest.NewRequest("POST", "/api/data", strings.NewReader("{"))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	srv.handleRequest(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusBadRequest)
+	}
+	if ctx.Err() != nil {
+		t.Fatal("failed request stopped the server")
+	}
+
+	req = httptest.NewRequest("POST", "/api/data", strings.NewReader(`{"a":1}`))
+	req.Header.Set("Content-Type", "application/json")
+	rr = httptest.NewRecorder()
+	srv.handleRequest(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+	if !strings.Contains(rr.Body.String(), "Request processed successfully") {
+		t.Errorf("response was not written before stopping\nGot: %s", rr.Body.String())
+	}
+	if ctx.Err() == nil {
+		t.Error("successful request did not stop the server")
+	}
+}
+
+func TestServer_StartMultiplePorts(t *testing.T) {
+	// Hold one port so that listening on it fails
+	busy, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer busy.Close()
+	free, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	freePort := free.Addr().(*net.TCPAddr).Port
+	free.Close()
+
+	srv := New(freePort, "", false, false, WithQuiet(true), WithExtraPorts(busy.Addr().(*net.TCPAddr).Port))
+	if servers := srv.httpServers(); len(servers) != 2 {
+		t.Fatalf("httpServers() returned %d servers want 2", len(servers))
+	}
+
+	errChan := make(chan error, 1)
+	go func() { errChan <- srv.Start(context.Background()) }()
+
+	// The failing port shuts the other one down
+	select {
+	case err := <-errChan:
+		if err == nil {
+			t.Error("Start() error = nil, want the listen error")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Start() did not return after a port failed to listen")
+	}
+}
@@ -0,0 +1,140 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// maxSpecSamples bounds the samples kept per schema in the spec store, so a
+// long running server does not grow without limit.
+const maxSpecSamples = 100
+
+// maxSpecOperations bounds the distinct methods and paths the spec store
+// remembers. Paths holding identifiers, such as /users/42, are distinct
+// operations, so a long running server would otherwise grow without limit.
+const maxSpecOperations = 1000
+
+// specKey identifies an operation in the accumulated spec.
+type specKey struct {
+	method string
+	path   string
+}
+
+// specStore accumulates the merged request body schema of every method and
+// path the server has seen.
+type specStore struct {
+	mu         sync.Mutex
+	operations map[specKey]*schema
+	// full is set once an operation was dropped for lack of room
+	full bool
+}
+
+func newSpecStore() *specStore {
+	return &specStore{operations: make(map[specKey]*schema)}
+}
+
+// record remembers an operation, merging its body schema, if any, into the
+// schemas seen before. Fields missing from some requests become optional.
+// Once maxSpecOperations are remembered, new operations are dropped, and
+// record reports true for the first one dropped.
+func (st *specStore) record(method, path string, body *schema) bool {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	key := specKey{method: strings.ToLower(method), path: path}
+	if _, ok := st.operations[key]; !ok && len(st.operations) >= maxSpecOperations {
+		first := !st.full
+		st.full = true
+		return first
+	}
+	merged := mergeSchemas(st.operations[key], body)
+	if merged != nil {
+		merged = trimSamples(merged)
+	}
+	st.operations[key] = merged
+	return false
+}
+
+// reset forgets every recorded operation.
+func (st *specStore) reset() {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.operations = make(map[specKey]*schema)
+	st.full = false
+}
+
+// document builds an OpenAPI 3.0 document describing the recorded operations.
+func (st *specStore) document(version string) map[string]interface{} {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	paths := make(map[string]interface{})
+	for key, body := range st.operations {
+		operation := map[string]interface{}{
+			"responses": map[string]interface{}{
+				"200": map[string]interface{}{"description": "Request processed successfully"},
+			},
+		}
+		if body != nil {
+			operation["requestBody"] = map[string]interface{}{
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{"schema": openAPISchema(body, nil)},
+				},
+			}
+		}
+		item, ok := paths[key.path].(map[string]interface{})
+		if !ok {
+			item = make(map[string]interface{})
+			paths[key.path] = item
+		}
+		item[key.method] = operation
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info":    map[string]interface{}{"title": "reqparser", "version": version},
+		"paths":   paths,
+	}
+}
+
+// trimSamples returns a copy of the schema keeping at most maxSpecSamples
+// samples per value. A non-integral number is kept so that number types
+// survive the trimming.
+func trimSamples(sch *schema) *schema {
+	trimmed := *sch
+	if len(sch.samples) > maxSpecSamples {
+		trimmed.samples = sch.samples[len(sch.samples)-maxSpecSamples:]
+		if sch.kind == kindNumber && !sch.integral() && trimmed.integral() {
+			var fractional interface{}
+			for _, sample := range sch.samples {
+				if !(&schema{kind: kindNumber, samples: []interface{}{sample}}).integral() {
+					fractional = sample
+					break
+				}
+			}
+			trimmed.samples = append([]interface{}{fractional}, trimmed.samples[1:]...)
+		}
+	}
+	if sch.elem != nil {
+		trimmed.elem = trimSamples(sch.elem)
+	}
+	if sch.fields != nil {
+		trimmed.fields = make([]*field, len(sch.fields))
+		for i, f := range sch.fields {
+			trimmed.fields[i] = &field{name: f.name, schema: trimSamples(f.schema), optional: f.optional}
+		}
+	}
+	return &trimmed
+}
+
+// handleOpenAPI serves the OpenAPI document accumulated from the requests
+// seen so far.
+func (s *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(s.spec.document(s.version))
+}
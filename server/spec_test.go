@@ -0,0 +1,162 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestServer_OpenAPISpec(t *testing.T) {
+	srv := New(8080, "", false, false, WithQuiet(true), WithOpenAPISpec(true), WithVersion("1.2.3"))
+	handler := srv.httpServer().Handler
+
+	requests := []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{method: "POST", path: "/api/users", body: `{"name":"alice","age":30}`},
+		{method: "POST", path: "/api/users", body: `{"name":"bob","email":null}`},
+		{method: "GET", path: "/api/users"},
+	}
+	for _, req := range requests {
+		r := httptest.NewRequest(req.method, req.path, strings.NewReader(req.body))
+		if req.body != "" {
+			r.Header.Set("Content-Type", "application/json")
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/openapi.json", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+
+	var doc struct {
+		OpenAPI string `json:"openapi"`
+		Info    struct {
+			Version string `json:"version"`
+		} `json:"info"`
+		Paths map[string]map[string]struct {
+			RequestBody *struct {
+				Content map[string]struct {
+					Schema struct {
+						Required   []string                          `json:"required"`
+						Properties map[string]map[string]interface{} `json:"properties"`
+					} `json:"schema"`
+				} `json:"content"`
+			} `json:"requestBody"`
+		} `json:"paths"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("Invalid OpenAPI JSON: %v\nGot: %s", err, rr.Body.String())
+	}
+
+	if doc.OpenAPI != "3.0.3" || doc.Info.Version != "1.2.3" {
+		t.Errorf("Unexpected document header: openapi %q, version %q", doc.OpenAPI, doc.Info.Version)
+	}
+	if doc.Paths["/openapi.json"] != nil {
+		t.Errorf("Spec requests were recorded as operations")
+	}
+	if get, ok := doc.Paths["/api/users"]["get"]; !ok || get.RequestBody != nil {
+		t.Errorf("GET /api/users missing or has a request body: %+v", get)
+	}
+
+	post, ok := doc.Paths["/api/users"]["post"]
+	if !ok || post.RequestBody == nil {
+		t.Fatalf("POST /api/users missing its request body\nGot: %s", rr.Body.String())
+	}
+	body := post.RequestBody.Content["application/json"].Schema
+	if strings.Join(body.Required, ",") != "name" {
+		t.Errorf("required = %v, want [name]", body.Required)
+	}
+	if got := body.Properties["age"]["type"]; got != "integer" {
+		t.Errorf("age type = %v, want integer", got)
+	}
+	// Only ever null, which OpenAPI 3.0 cannot type
+	if got, ok := body.Properties["email"]; !ok || len(got) != 0 {
+		t.Errorf("email schema = %v, want the empty schema", got)
+	}
+}
+
+func TestTrimSamples(t *testing.T) {
+	samples := []interface{}{1.5}
+	for i := 0; i < 2*maxSpecSamples; i++ {
+		samples = append(samples, float64(i))
+	}
+
+	trimmed := trimSamples(&schema{kind: kindNumber, samples: samples})
+	if len(trimmed.samples) != maxSpecSamples {
+		t.Errorf("trimSamples() kept %d samples, want %d", len(trimmed.samples), maxSpecSamples)
+	}
+	if trimmed.integral() {
+		t.Error("trimSamples() dropped the only fractional sample")
+	}
+}
+
+func TestSpecStore_MaxOperations(t *testing.T) {
+	st := newSpecStore()
+	for i := 0; i < maxSpecOperations; i++ {
+		if st.record("GET", fmt.Sprintf("/users/%d", i), nil) {
+			t.Fatalf("record() reported a drop after %d operations", i)
+		}
+	}
+	if !st.record("GET", "/extra", nil) {
+		t.Error("record() did not report the first dropped operation")
+	}
+	if st.record("GET", "/another", nil) {
+		t.Error("record() reported a second dropped operation")
+	}
+	if st.record("GET", "/users/0", inferSchema(map[string]interface{}{"id": 1.0})) {
+		t.Error("record() dropped a known operation")
+	}
+	if len(st.operations) != maxSpecOperations || st.operations[specKey{method: "get", path: "/users/0"}] == nil {
+		t.Errorf("store holds %d operations, want %d with /users/0 merged", len(st.operations), maxSpecOperations)
+	}
+
+	st.reset()
+	if st.record("GET", "/extra", nil) || len(st.operations) != 1 {
+		t.Error("record() did not accept operations after reset()")
+	}
+}
+
+func TestServer_Reset(t *testing.T) {
+	srv := New(8080, "go", false, false, WithQuiet(true), WithOpenAPISpec(true), WithCacheSize(8))
+	handler := srv.httpServer().Handler
+
+	r := httptest.NewRequest("POST", "/api/users", strings.NewReader(`{"name":"alice"}`))
+	r.Header.Set("Content-Type", "application/json")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/reset", nil))
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET /reset returned status %v want %v", rr.Code, http.StatusMethodNotAllowed)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("POST", "/reset", nil))
+	if rr.Code != http.StatusNoContent {
+		t.Fatalf("POST /reset returned status %v want %v", rr.Code, http.StatusNoContent)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/openapi.json", nil))
+	if strings.Contains(rr.Body.String(), "/api/users") {
+		t.Errorf("spec still holds operations after reset\nGot: %s", rr.Body.String())
+	}
+	if _, _, ok := srv.cache.get(cacheKey("go", defaultStructName, "application/json", []byte(`{"name":"alice"}`))); ok {
+		t.Error("cache still holds output after reset")
+	}
+
+	// Without state to clear, /reset is an ordinary echoed path
+	rr = httptest.NewRecorder()
+	New(8080, "", false, false, WithQuiet(true)).httpServer().Handler.ServeHTTP(rr, httptest.NewRequest("POST", "/reset", nil))
+	if rr.Code != http.StatusOK {
+		t.Errorf("POST /reset without state returned status %v want %v", rr.Code, http.StatusOK)
+	}
+}
@@ -0,0 +1,155 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+)
+
+// thriftKeywords lists the Thrift IDL keywords, plus the reserved words the
+// compiler rejects because they are keywords in generated languages.
+var thriftKeywords = map[string]bool{
+	"binary": true, "bool": true, "byte": true, "const": true, "double": true,
+	"enum": true, "exception": true, "extends": true, "false": true,
+	"i16": true, "i32": true, "i64": true, "i8": true, "include": true,
+	"list": true, "map": true, "namespace": true, "oneway": true,
+	"optional": true, "required": true, "service": true, "set": true,
+	"string": true, "struct": true, "throws": true, "true": true,
+	"typedef": true, "union": true, "void": true,
+	"abstract": true, "and": true, "as": true, "break": true, "case": true,
+	"catch": true, "class": true, "continue": true, "def": true,
+	"default": true, "del": true, "do": true, "else": true, "end": true,
+	"except": true, "final": true, "finally": true, "for": true, "from": true,
+	"global": true, "goto": true, "if": true, "implements": true,
+	"import": true, "in": true, "interface": true, "is": true, "lambda": true,
+	"native": true, "new": true, "not": true, "or": true, "package": true,
+	"pass": true, "private": true, "protected": true, "public": true,
+	"raise": true, "return": true, "static": true, "super": true,
+	"switch": true, "this": true, "throw": true, "try": true, "while": true,
+	"with": true, "yield": true,
+}
+
+// thriftBuiltinTypes lists the names a struct must not take: the keywords,
+// base types included, both as written, which the IDL rejects, and
+// capitalized, as generated languages would shadow their String or List
+// types.
+var thriftBuiltinTypes = func() map[string]bool {
+	names := make(map[string]bool, 2*len(thriftKeywords))
+	for keyword := range thriftKeywords {
+		names[keyword] = true
+		names[typeNameFor(keyword)] = true
+	}
+	return names
+}()
+
+func (s *Server) formatAsThrift(sch *schema) (string, error) {
+	root := sch
+	if sch.kind != kindObject {
+		root = &schema{kind: kindObject, fields: []*field{{name: "data", schema: sch}}}
+	}
+
+	// Thrift needs types defined before use, so emit children first
+	types := s.nestedObjects(s.structName, root)
+	structs := make([]string, 0, len(types.objects))
+	for _, obj := range childrenFirst(types) {
+		structs = append(structs, s.generateThriftStruct(obj, types))
+	}
+	return strings.Join(structs, "\n\n"), nil
+}
+
+// generateThriftStruct numbers fields from 1 in key order. The IDs are only
+// deterministic for a given set of keys: a new key renumbers the keys sorted
+// after it, so IDs must be pinned by hand once the IDL is in use.
+func (s *Server) generateThriftStruct(obj *objectType, types *objectTypes) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "struct %s {\n", obj.name)
+
+	used := make(map[string]bool)
+	for i, f := range obj.schema.fields {
+		name := thriftIdentifier(f.name)
+		base := name
+		for n := 2; used[name]; n++ {
+			name = fmt.Sprintf("%s_%d", base, n)
+		}
+		used[name] = true
+
+		var requiredness string
+		if f.optional || f.schema.nullable || f.schema.kind == kindNull {
+			requiredness = "optional "
+		}
+		var notes []string
+		if name != f.name {
+			notes = append(notes, "JSON key: "+f.name)
+		}
+		if note := thriftFallbackNote(f.schema); note != "" {
+			notes = append(notes, note)
+		}
+		var comment string
+		if len(notes) > 0 {
+			comment = " // " + strings.Join(notes, "; ")
+		}
+		fmt.Fprintf(&b, "  %d: %s%s %s;%s\n", i+1, requiredness, s.getThriftType(f.schema, types), name, comment)
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// getThriftType maps a schema to a Thrift type. Thrift has no dynamic type,
+// so values of mixed or unknown type, and values only ever null, are carried
+// as JSON encoded strings.
+func (s *Server) getThriftType(sch *schema, types *objectTypes) string {
+	switch sch.kind {
+	case kindBool:
+		return "bool"
+	case kindNumber:
+		if sch.integral() {
+			return "i64"
+		}
+		return "double"
+	case kindString:
+		return "string"
+	case kindArray:
+		elemType := "string"
+		if sch.elem != nil {
+			elemType = s.getThriftType(sch.elem, types)
+		}
+		return fmt.Sprintf("list<%s>", elemType)
+	case kindObject:
+		return types.name(sch)
+	default:
+		return "string"
+	}
+}
+
+// thriftFallbackNote explains a field that getThriftType carries as JSON
+// encoded strings, or returns an empty string for a field typed as it is.
+func thriftFallbackNote(sch *schema) string {
+	items := ""
+	for sch.kind == kindArray {
+		if sch.elem == nil {
+			return "always empty, items typed as JSON encoded strings"
+		}
+		sch, items = sch.elem, "items "
+	}
+	switch sch.kind {
+	case kindNull:
+		if items != "" {
+			return "items always null, typed as JSON encoded strings"
+		}
+		return "always null, typed as a JSON encoded string"
+	case kindMixed:
+		if items != "" {
+			return "items of mixed types, typed as JSON encoded strings"
+		}
+		return "mixed types, typed as a JSON encoded string"
+	}
+	return ""
+}
+
+// thriftIdentifier sanitizes a JSON key into a Thrift field name.
+func thriftIdentifier(key string) string {
+	name := sanitizeIdentifier(key)
+	if thriftKeywords[name] {
+		name += "_"
+	}
+	return name
+}
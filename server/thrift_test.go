@@ -0,0 +1,76 @@
+package server
+
+import "testing"
+
+func TestFormatAsThrift(t *testing.T) {
+	testFormatCases(t, "thrift", []formatCase{
+		{
+			name: "Field numbering in key order",
+			data: map[string]interface{}{"name": "test", "value": 123.0, "ratio": 0.5, "active": true},
+			expectContains: []string{
+				"struct GeneratedStruct {\n" +
+					"  1: bool active;\n" +
+					"  2: string name;\n" +
+					"  3: double ratio;\n" +
+					"  4: i64 value;\n" +
+					"}",
+			},
+		},
+		{
+			name: "Nested objects, arrays and renamed keys",
+			data: map[string]interface{}{
+				"user-info": map[string]interface{}{"id": 1.0},
+				"tags":      []interface{}{"a"},
+				"list":      []interface{}{[]interface{}{1.5}},
+			},
+			expectContains: []string{
+				"struct UserInfo {\n  1: i64 id;\n}\n\nstruct GeneratedStruct {\n",
+				"  1: list<list<double>> list_; // JSON key: list\n",
+				"  2: list<string> tags;\n",
+				"  3: UserInfo user_info; // JSON key: user-info\n",
+			},
+		},
+		{
+			name: "Optional and null fields",
+			records: []interface{}{
+				map[string]interface{}{"id": 1.0, "email": nil, "note": nil},
+				map[string]interface{}{"id": 2.0, "email": "x@example.com"},
+			},
+			expectContains: []string{
+				"  1: optional string email;\n",
+				"  2: i64 id;\n",
+				"  3: optional string note; // always null, typed as a JSON encoded string\n",
+			},
+		},
+		{
+			name:           "Non-object root",
+			data:           []interface{}{"a"},
+			expectContains: []string{"  1: list<string> data;\n"},
+		},
+		{
+			name:           "Renamed null field",
+			data:           map[string]interface{}{"end": nil},
+			expectContains: []string{"  1: optional string end_; // JSON key: end; always null, typed as a JSON encoded string\n"},
+		},
+		{
+			name: "Fallback types",
+			data: map[string]interface{}{
+				"any":    []interface{}{1.0, "a"},
+				"empty":  []interface{}{},
+				"matrix": []interface{}{[]interface{}{true, 1.0}},
+				"nulls":  []interface{}{nil},
+			},
+			expectContains: []string{
+				"  1: list<string> any; // items of mixed types, typed as JSON encoded strings\n",
+				"  2: list<string> empty; // always empty, items typed as JSON encoded strings\n",
+				"  3: list<list<string>> matrix; // items of mixed types, typed as JSON encoded strings\n",
+				"  4: list<string> nulls; // items always null, typed as JSON encoded strings\n",
+			},
+		},
+		{
+			name:           "Mixed field",
+			records:        []interface{}{map[string]interface{}{"id": 1.0}, map[string]interface{}{"id": "a"}},
+			expectContains: []string{"  1: string id; // mixed types, typed as a JSON encoded string\n"},
+		},
+	})
+}
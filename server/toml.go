@@ -0,0 +1,79 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// formatAsTOML re-serializes decoded JSON records as TOML documents. Unlike
+// the other formats it converts the data itself rather than describing its
+// type. Several records, as from NDJSON, become consecutive documents.
+func formatAsTOML(records []interface{}) (string, error) {
+	documents := make([]string, 0, len(records))
+	for _, record := range records {
+		table, ok := record.(map[string]interface{})
+		if !ok {
+			return "", errors.New("TOML documents must be tables, but the JSON value is not an object")
+		}
+		value, err := tomlValue(table, "")
+		if err != nil {
+			return "", err
+		}
+
+		var buf bytes.Buffer
+		if err := toml.NewEncoder(&buf).Encode(value); err != nil {
+			return "", fmt.Errorf("encoding TOML: %w", err)
+		}
+		documents = append(documents, strings.TrimSuffix(buf.String(), "\n"))
+	}
+	return strings.Join(documents, "\n\n"), nil
+}
+
+// tomlValue prepares a decoded JSON value for the TOML encoder, rejecting
+// nulls, which TOML cannot represent, and turning whole numbers into integers
+// so they are not written as floats.
+func tomlValue(v interface{}, path string) (interface{}, error) {
+	switch val := v.(type) {
+	case nil:
+		if path == "" {
+			return nil, errors.New("TOML cannot represent null")
+		}
+		return nil, fmt.Errorf("TOML cannot represent null at %s", path)
+	case float64:
+		if val == math.Trunc(val) && math.Abs(val) < 1<<53 {
+			return int64(val), nil
+		}
+		return val, nil
+	case []interface{}:
+		items := make([]interface{}, len(val))
+		for i, elem := range val {
+			item, err := tomlValue(elem, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return items, nil
+	case map[string]interface{}:
+		table := make(map[string]interface{}, len(val))
+		for _, key := range sortedKeys(val) {
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+			item, err := tomlValue(val[key], childPath)
+			if err != nil {
+				return nil, err
+			}
+			table[key] = item
+		}
+		return table, nil
+	default:
+		return val, nil
+	}
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatAsTOML(t *testing.T) {
+	tests := []struct {
+		name           string
+		records        []interface{}
+		expectContains []string
+		expectError    string
+	}{
+		{
+			name: "Scalars, arrays and tables",
+			records: []interface{}{map[string]interface{}{
+				"name":   "test",
+				"port":   8080.0,
+				"ratio":  0.5,
+				"debug":  true,
+				"tags":   []interface{}{"a", "b"},
+				"server": map[string]interface{}{"host": "localhost"},
+				"users":  []interface{}{map[string]interface{}{"id": 1.0}, map[string]interface{}{"id": 2.0}},
+			}},
+			expectContains: []string{
+				"debug = true\n",
+				"name = \"test\"\n",
+				"port = 8080\n",
+				"ratio = 0.5\n",
+				"tags = [\"a\", \"b\"]\n",
+				"[server]\n  host = \"localhost\"",
+				"[[users]]\n  id = 1",
+			},
+		},
+		{
+			name: "Several records",
+			records: []interface{}{
+				map[string]interface{}{"id": 1.0},
+				map[string]interface{}{"id": 2.0},
+			},
+			expectContains: []string{"id = 1\n\nid = 2"},
+		},
+		{
+			name:        "Null value",
+			records:     []interface{}{map[string]interface{}{"server": map[string]interface{}{"hosts": []interface{}{"a", nil}}}},
+			expectError: "TOML cannot represent null at server.hosts[1]",
+		},
+		{
+			name:        "Non-object root",
+			records:     []interface{}{[]interface{}{1.0}},
+			expectError: "TOML documents must be tables",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := formatAsTOML(tt.records)
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("formatAsTOML() error = %v, want %q", err, tt.expectError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("formatAsTOML() error = %v", err)
+			}
+
+			for _, expect := range tt.expectContains {
+				if !strings.Contains(result, expect) {
+					t.Errorf("formatAsTOML() result does not contain expected string: %s\nGot: %s", expect, result)
+				}
+			}
+		})
+	}
+}
+
+func TestServer_FormatTOML(t *testing.T) {
+	srv := New(8080, "toml", false, false)
+	result, err := srv.Format([]byte(`{"title":"example","owner":{"name":"tom"}}`), "test")
+	if err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	if !strings.Contains(result, "title = \"example\"\n\n[owner]\n  name = \"tom\"") {
+		t.Errorf("Format() result is not the TOML document\nGot: %s", result)
+	}
+}
@@ -0,0 +1,49 @@
+package server
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// tracerName identifies the instrumentation that created reqparser spans.
+const tracerName = "github.com/stackloklabs/reqparser/server"
+
+// noopTracer is used until a tracer provider is configured, so tracing costs
+// nothing when disabled.
+var noopTracer = noop.NewTracerProvider().Tracer(tracerName)
+
+// startRequestSpan starts the server span for an incoming request, continuing
+// any trace context propagated in its headers.
+func (s *Server) startRequestSpan(r *http.Request) (context.Context, trace.Span) {
+	ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+	attrs := []attribute.KeyValue{
+		semconv.HTTPRequestMethodKey.String(r.Method),
+		semconv.URLPath(r.URL.Path),
+		attribute.String("reqparser.format", s.formatType),
+	}
+	if r.ContentLength >= 0 {
+		attrs = append(attrs, semconv.HTTPRequestBodySize(int(r.ContentLength)))
+	}
+	return s.tracer.Start(ctx, r.Method+" "+r.URL.Path,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// endRequestSpan records the response status on the span and ends it.
+func endRequestSpan(span trace.Span, status int, err error) {
+	span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestServer_Tracing(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+
+	tests := []struct {
+		name         string
+		body         string
+		expectStatus codes.Code
+		expectCode   int64
+	}{
+		{
+			name:       "Successful request",
+			body:       `{"name":"test"}`,
+			expectCode: http.StatusOK,
+		},
+		{
+			name:         "Invalid JSON",
+			body:         `{"name":`,
+			expectStatus: codes.Error,
+			expectCode:   http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+			srv := New(8080, "go", false, false, WithQuiet(true), WithTracerProvider(provider))
+
+			req := httptest.NewRequest("POST", "/api/data", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+			rr := httptest.NewRecorder()
+			srv.handleRequest(rr, req)
+
+			spans := recorder.Ended()
+			if len(spans) != 1 {
+				t.Fatalf("Recorded %d spans, want 1", len(spans))
+			}
+			span := spans[0]
+			if got := span.Parent().TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+				t.Errorf("Span parent trace ID = %s, want the propagated one", got)
+			}
+			if span.Status().Code != tt.expectStatus {
+				t.Errorf("Span status = %v, want %v", span.Status().Code, tt.expectStatus)
+			}
+
+			attrs := make(map[attribute.Key]attribute.Value)
+			for _, kv := range span.Attributes() {
+				attrs[kv.Key] = kv.Value
+			}
+			if got := attrs["http.request.method"].AsString(); got != "POST" {
+				t.Errorf("http.request.method = %q, want POST", got)
+			}
+			if got := attrs["url.path"].AsString(); got != "/api/data" {
+				t.Errorf("url.path = %q, want /api/data", got)
+			}
+			if got := attrs["reqparser.format"].AsString(); got != "go" {
+				t.Errorf("reqparser.format = %q, want go", got)
+			}
+			if got := attrs["http.request.body.size"].AsInt64(); got != int64(len(tt.body)) {
+				t.Errorf("http.request.body.size = %d, want %d", got, len(tt.body))
+			}
+			if got := attrs["http.response.status_code"].AsInt64(); got != tt.expectCode {
+				t.Errorf("http.response.status_code = %d, want %d", got, tt.expectCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// pythonKeywords lists the reserved words that cannot be used as attribute
+// names in the class-based TypedDict syntax.
+var pythonKeywords = map[string]bool{
+	"False": true, "None": true, "True": true, "and": true, "as": true,
+	"assert": true, "async": true, "await": true, "break": true, "class": true,
+	"continue": true, "def": true, "del": true, "elif": true, "else": true,
+	"except": true, "finally": true, "for": true, "from": true, "global": true,
+	"if": true, "import": true, "in": true, "is": true, "lambda": true,
+	"nonlocal": true, "not": true, "or": true, "pass": true, "raise": true,
+	"return": true, "try": true, "while": true, "with": true, "yield": true,
+}
+
+// typedDictBuiltinTypes lists the names imported from typing, which a class
+// of the same name would shadow.
+var typedDictBuiltinTypes = map[string]bool{
+	"Any": true, "List": true, "NotRequired": true, "Optional": true,
+	"TypedDict": true,
+}
+
+func (s *Server) formatAsTypedDict(sch *schema) (string, error) {
+	imports := map[string]bool{"TypedDict": true}
+
+	root := sch
+	if sch.kind != kindObject {
+		root = &schema{kind: kindObject, fields: []*field{{name: "data", schema: sch}}}
+	}
+
+	// Python needs classes defined before use, so emit children first
+	types := s.nestedObjects(s.structName, root)
+	var classes []string
+	for _, obj := range childrenFirst(types) {
+		classes = append(classes, s.generateTypedDictClass(obj, types, imports))
+	}
+
+	return fmt.Sprintf("%s\n\n\n%s", pythonImports(imports), strings.Join(classes, "\n\n")), nil
+}
+
+func (s *Server) generateTypedDictClass(obj *objectType, types *objectTypes, imports map[string]bool) string {
+	fieldTypes := make([]string, len(obj.schema.fields))
+	classSyntax := true
+	for i, f := range obj.schema.fields {
+		fieldType := s.getTypedDictType(f.schema, types, imports)
+		if f.optional || f.schema.nullable || f.schema.kind == kindNull {
+			imports["NotRequired"] = true
+			fieldType = fmt.Sprintf("NotRequired[%s]", fieldType)
+		}
+		fieldTypes[i] = fieldType
+		if !isPythonIdentifier(f.name) {
+			classSyntax = false
+		}
+	}
+
+	// Keys that are not valid identifiers need the functional syntax
+	if !classSyntax {
+		var result string
+		for i, f := range obj.schema.fields {
+			result += fmt.Sprintf("    %q: %s,\n", f.name, fieldTypes[i])
+		}
+		return fmt.Sprintf("%s = TypedDict(%q, {\n%s})\n", obj.name, obj.name, result)
+	}
+
+	var result string
+	for i, f := range obj.schema.fields {
+		result += fmt.Sprintf("    %s: %s\n", f.name, fieldTypes[i])
+	}
+	if result == "" {
+		result = "    pass\n"
+	}
+	return fmt.Sprintf("class %s(TypedDict):\n%s", obj.name, result)
+}
+
+func (s *Server) getTypedDictType(sch *schema, types *objectTypes, imports map[string]bool) string {
+	var pyType string
+	switch sch.kind {
+	case kindBool:
+		pyType = "bool"
+	case kindNumber:
+		pyType = "float"
+	case kindString:
+		pyType = "str"
+	case kindArray:
+		imports["List"] = true
+		elemType := "Any"
+		if sch.elem != nil {
+			elemType = s.getTypedDictType(sch.elem, types, imports)
+		} else {
+			imports["Any"] = true
+		}
+		pyType = fmt.Sprintf("List[%s]", elemType)
+	case kindObject:
+		pyType = types.name(sch)
+	case kindNull:
+		imports["Any"] = true
+		imports["Optional"] = true
+		return "Optional[Any]"
+	default:
+		imports["Any"] = true
+		pyType = "Any"
+	}
+	if sch.nullable {
+		imports["Optional"] = true
+		return fmt.Sprintf("Optional[%s]", pyType)
+	}
+	return pyType
+}
+
+// pythonImports renders a sorted "from typing import" line.
+func pythonImports(imports map[string]bool) string {
+	names := make([]string, 0, len(imports))
+	for name := range imports {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return "from typing import " + strings.Join(names, ", ")
+}
+
+// isPythonIdentifier reports whether name can be used as a Python attribute.
+func isPythonIdentifier(name string) bool {
+	return isIdentifier(name) && !pythonKeywords[name]
+}
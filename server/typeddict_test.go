@@ -0,0 +1,55 @@
+package server
+
+import "testing"
+
+func TestFormatAsTypedDict(t *testing.T) {
+	testFormatCases(t, "typeddict", []formatCase{
+		{
+			name: "Flat object",
+			data: map[string]interface{}{"name": "test", "value": 123.0, "active": true},
+			expectContains: []string{
+				"from typing import TypedDict",
+				"class GeneratedStruct(TypedDict):",
+				"    active: bool",
+				"    name: str",
+				"    value: float",
+			},
+		},
+		{
+			name: "Nested objects",
+			data: map[string]interface{}{
+				"user":  map[string]interface{}{"name": "test"},
+				"items": []interface{}{map[string]interface{}{"id": 1.0}},
+			},
+			expectContains: []string{
+				"from typing import List, TypedDict",
+				"class Items(TypedDict):\n    id: float\n\n\nclass GeneratedStruct(TypedDict):",
+				"class User(TypedDict):\n    name: str",
+				"    items: List[Items]",
+				"    user: User",
+			},
+		},
+		{
+			name: "Optional and null fields",
+			records: []interface{}{
+				map[string]interface{}{"id": 1.0, "email": nil, "nickname": "a"},
+				map[string]interface{}{"id": 2.0, "email": "x@example.com"},
+			},
+			expectContains: []string{
+				"from typing import NotRequired, Optional, TypedDict",
+				"    email: NotRequired[Optional[str]]",
+				"    id: float",
+				"    nickname: NotRequired[str]",
+			},
+		},
+		{
+			name: "Keys that are not identifiers",
+			data: map[string]interface{}{"created-at": "2024-01-01", "class": "a"},
+			expectContains: []string{
+				`GeneratedStruct = TypedDict("GeneratedStruct", {`,
+				`    "class": str,`,
+				`    "created-at": str,`,
+			},
+		},
+	})
+}
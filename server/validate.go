@@ -0,0 +1,150 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// maxExactInteger is the largest magnitude below which every whole number is
+// exactly representable as a float64, 2^53.
+const maxExactInteger = 1 << 53
+
+// schemaReport describes the inferred schema in plain text, one line per
+// field with its type, and lists the inference decisions worth checking
+// before generating code, such as arrays whose element type is unknown.
+func (s *Server) schemaReport(sch *schema) string {
+	root := sch
+	if sch.kind != kindObject {
+		root = &schema{kind: kindObject, fields: []*field{{name: "data", schema: sch}}}
+	}
+	types := s.nestedObjects(s.structName, root)
+
+	var b strings.Builder
+	var warnings []string
+	fmt.Fprintf(&b, "%s: object\n", s.structName)
+	s.reportFields(&b, &warnings, root, types, "", 1)
+
+	if _, cut := limitDepth(root, s.maxDepth); cut {
+		warnings = append(warnings, fmt.Sprintf("values nested deeper than %d levels are typed with each format's catch-all type", s.maxDepth))
+	}
+	if len(warnings) == 0 {
+		b.WriteString("No warnings")
+		return b.String()
+	}
+	b.WriteString("Warnings:\n")
+	for _, warning := range warnings {
+		fmt.Fprintf(&b, "  - %s\n", warning)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// reportFields writes a line per field of an object and of the objects nested
+// in it, with paths written as in -nested-naming path.
+func (s *Server) reportFields(b *strings.Builder, warnings *[]string, sch *schema, types *objectTypes, prefix string, depth int) {
+	for _, f := range sch.fields {
+		path := prefix + f.name
+		description := s.reportType(f.schema, types)
+		if s.isEnumField(f) {
+			description = fmt.Sprintf("enum (%s)", strings.Join(enumValues(f.schema), ", "))
+		}
+		if f.optional {
+			description += ", optional"
+		}
+		fmt.Fprintf(b, "%s%s: %s (depth %d)\n", strings.Repeat("  ", depth), path, description, depth)
+
+		*warnings = append(*warnings, reportWarnings(f.schema, path)...)
+
+		// Walk into objects, including those held by arrays
+		nested, elemPath := f.schema, path
+		for nested.kind == kindArray && nested.elem != nil {
+			nested, elemPath = nested.elem, elemPath+"[]"
+		}
+		if nested.kind == kindObject {
+			s.reportFields(b, warnings, nested, types, elemPath+".", depth+1)
+		}
+	}
+}
+
+// reportType describes a schema as it is typed by the formatters.
+func (s *Server) reportType(sch *schema, types *objectTypes) string {
+	var description string
+	switch sch.kind {
+	case kindNumber:
+		description = "number"
+		if sch.integral() {
+			description = "whole number"
+		}
+	case kindString:
+		description = "string"
+		switch {
+		case s.isUUID(sch):
+			description = "string (uuid)"
+		case s.isIP(sch):
+			description = "string (ip)"
+		case s.isCIDR(sch):
+			description = "string (cidr)"
+		case s.isBase64(sch):
+			description = "string (base64)"
+		case s.isDuration(sch):
+			description = "string (duration)"
+		case s.isNumericString(sch):
+			description = "string (numeric)"
+		}
+	case kindArray:
+		description = "array"
+		if sch.elem != nil {
+			description = "array of " + s.reportType(sch.elem, types)
+		}
+	case kindObject:
+		description = "object " + types.name(sch)
+	default:
+		description = kindNames[sch.kind]
+	}
+	if sch.nullable {
+		description += ", nullable"
+	}
+	return description
+}
+
+// reportWarnings lists the inference decisions made for the schema at path
+// that may not match what the payload author intended. Array elements are
+// reported under the path of the array followed by [].
+func reportWarnings(sch *schema, path string) []string {
+	var warnings []string
+	switch sch.kind {
+	case kindNull:
+		warnings = append(warnings, path+": only null values, so the type is unknown")
+	case kindMixed:
+		warnings = append(warnings, path+": values of several types, typed with each format's catch-all type")
+	case kindNumber:
+		for _, sample := range sch.samples {
+			if n, ok := sample.(float64); ok && n == math.Trunc(n) && math.Abs(n) >= maxExactInteger {
+				warnings = append(warnings, fmt.Sprintf("%s: %.0f is at least 2^53, so the value sent may have lost precision as a float64", path, n))
+				break
+			}
+		}
+	case kindArray:
+		if sch.elem == nil {
+			warnings = append(warnings, path+": only empty arrays, so the element type is unknown")
+			break
+		}
+		warnings = reportWarnings(sch.elem, path+"[]")
+	}
+	return warnings
+}
+
+// Validate infers the schema of a JSON document, or of a stream of documents
+// merged into one, and describes it without generating code.
+func (s *Server) Validate(data []byte) (string, error) {
+	records, err := decodeNDJSON(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("invalid JSON: %w", err)
+	}
+	if len(records) == 0 {
+		return "", errors.New("invalid JSON: no value found")
+	}
+	return s.schemaReport(inferRecords(records)), nil
+}
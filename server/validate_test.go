@@ -0,0 +1,121 @@
+package server
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSchemaReport(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []Option
+		records []interface{}
+		expect  string
+	}{
+		{
+			name: "Fields, depth and warnings",
+			records: []interface{}{
+				map[string]interface{}{
+					"id":    9007199254740993.0,
+					"tags":  []interface{}{},
+					"owner": map[string]interface{}{"name": nil, "mixed": []interface{}{1.0, "a"}},
+					"items": []interface{}{map[string]interface{}{"sku": "a", "price": 1.5}, map[string]interface{}{"sku": "b"}},
+				},
+				map[string]interface{}{
+					"id":    1.0,
+					"tags":  []interface{}{},
+					"owner": map[string]interface{}{"name": nil, "mixed": []interface{}{}},
+					"email": nil,
+				},
+			},
+			expect: "GeneratedStruct: object\n" +
+				"  email: null, optional (depth 1)\n" +
+				"  id: whole number (depth 1)\n" +
+				"  items: array of object Items, optional (depth 1)\n" +
+				"    items[].price: number, optional (depth 2)\n" +
+				"    items[].sku: string (depth 2)\n" +
+				"  owner: object Owner (depth 1)\n" +
+				"    owner.mixed: array of mixed (depth 2)\n" +
+				"    owner.name: null (depth 2)\n" +
+				"  tags: array (depth 1)\n" +
+				"Warnings:\n" +
+				"  - email: only null values, so the type is unknown\n" +
+				"  - id: 9007199254740992 is at least 2^53, so the value sent may have lost precision as a float64\n" +
+				"  - owner.mixed[]: values of several types, typed with each format's catch-all type\n" +
+				"  - owner.name: only null values, so the type is unknown\n" +
+				"  - tags: only empty arrays, so the element type is unknown",
+		},
+		{
+			name: "Detected string formats and enums",
+			opts: []Option{WithDetectUUID(true), WithDetectEnums(true)},
+			records: []interface{}{
+				map[string]interface{}{"id": "123e4567-e89b-12d3-a456-426614174000", "status": "open", "total": "1.5"},
+				map[string]interface{}{"id": "123e4567-e89b-12d3-a456-426614174001", "status": "closed", "total": nil},
+				map[string]interface{}{"id": "123e4567-e89b-12d3-a456-426614174002", "status": "open", "total": "2"},
+			},
+			expect: "GeneratedStruct: object\n" +
+				"  id: string (uuid) (depth 1)\n" +
+				"  status: enum (closed, open) (depth 1)\n" +
+				"  total: string, nullable (depth 1)\n" +
+				"No warnings",
+		},
+		{
+			name:    "Values cut by the maximum depth",
+			opts:    []Option{WithMaxDepth(1)},
+			records: []interface{}{map[string]interface{}{"a": map[string]interface{}{"b": 1.0}}},
+			expect: "GeneratedStruct: object\n" +
+				"  a: object A (depth 1)\n" +
+				"    a.b: whole number (depth 2)\n" +
+				"Warnings:\n" +
+				"  - values nested deeper than 1 levels are typed with each format's catch-all type",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := New(8080, "", false, false, tt.opts...)
+			if got := srv.schemaReport(inferRecords(tt.records)); got != tt.expect {
+				t.Errorf("schemaReport() = %s\nwant %s", got, tt.expect)
+			}
+		})
+	}
+}
+
+func TestServer_Validate(t *testing.T) {
+	var logBuf bytes.Buffer
+	log.SetOutput(&logBuf)
+	defer log.SetOutput(os.Stderr)
+
+	srv := New(8080, "go", false, false, WithValidate(true))
+	req := httptest.NewRequest("POST", "/api/data", strings.NewReader(`{"name":"test"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	srv.handleRequest(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("Handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+	logs := logBuf.String()
+	if !strings.Contains(logs, "Schema report:\nGeneratedStruct: object\n  name: string (depth 1)\nNo warnings") {
+		t.Errorf("Logs do not contain the schema report\nGot: %s", logs)
+	}
+	if strings.Contains(logs, "Struct format:") {
+		t.Errorf("Logs contain generated code while validating\nGot: %s", logs)
+	}
+
+	report, err := srv.Validate([]byte("{\"id\":1}\n{\"id\":2,\"note\":\"x\"}"))
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if !strings.Contains(report, "  note: string, optional (depth 1)\n") {
+		t.Errorf("Validate() does not merge records\nGot: %s", report)
+	}
+	if _, err := srv.Validate([]byte(`{"id":`)); err == nil {
+		t.Errorf("Validate() accepted invalid JSON")
+	}
+}
@@ -0,0 +1,135 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce is how long a fixture must stay unchanged before it is
+// regenerated, so that an editor saving in several writes triggers one run.
+const watchDebounce = 100 * time.Millisecond
+
+// watchSuffix ends the base name of the files -watch generates.
+const watchSuffix = "_gen"
+
+// Watch generates code for every JSON fixture of dir, then regenerates a
+// fixture's code whenever it is written, until ctx is done. The code of
+// order.json is written next to it, as order_gen.go for the Go format, with
+// the type named after the file. Fixtures may hold NDJSON, as with -in.
+// Errors in a fixture are logged and do not stop the watch.
+func (s *Server) Watch(ctx context.Context, dir string) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+	if err := watcher.Add(dir); err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if !entry.IsDir() && isFixture(path) {
+			s.regenerate(path)
+		}
+	}
+
+	ready := make(chan string)
+	pending := make(map[string]*time.Timer)
+	defer func() {
+		for _, timer := range pending {
+			timer.Stop()
+		}
+	}()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) || !isFixture(event.Name) {
+				continue
+			}
+			if timer, ok := pending[event.Name]; ok {
+				timer.Reset(watchDebounce)
+				continue
+			}
+			path := event.Name
+			pending[path] = time.AfterFunc(watchDebounce, func() {
+				select {
+				case ready <- path:
+				case <-ctx.Done():
+				}
+			})
+		case path := <-ready:
+			delete(pending, path)
+			s.regenerate(path)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			log.Printf("Error watching %s: %v", dir, err)
+		}
+	}
+}
+
+// isFixture reports whether a file holds JSON to generate code for, leaving
+// out the files -watch generates, such as order_gen.json for schema-json.
+func isFixture(path string) bool {
+	ext := filepath.Ext(path)
+	if ext != ".json" && ext != ".ndjson" {
+		return false
+	}
+	return !strings.HasSuffix(strings.TrimSuffix(filepath.Base(path), ext), watchSuffix)
+}
+
+// watchOutputPath returns the path of the file generated for a fixture.
+func (s *Server) watchOutputPath(path string) string {
+	return strings.TrimSuffix(path, filepath.Ext(path)) + watchSuffix + formatExtensions[s.formatType]
+}
+
+// regenerate writes the code of one fixture, logging the outcome.
+func (s *Server) regenerate(path string) {
+	out := s.watchOutputPath(path)
+	if err := s.generateFixture(path, out); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			// Removed before the debounce ran out
+			return
+		}
+		log.Printf("Error regenerating %s: %v", out, err)
+		return
+	}
+	log.Printf("Regenerated %s from %s", out, path)
+}
+
+// generateFixture generates the code of a fixture into out, naming the type
+// after the fixture file.
+func (s *Server) generateFixture(path, out string) error {
+	gen := *s
+	base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	if name := sanitizeIdentifier(toPascalCase(base)); isIdentifier(name) {
+		gen.structName = name
+	}
+	formatted, err := gen.FormatFiles([]string{path})
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(out, []byte(formatted+"\n"), 0o644); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+	return nil
+}
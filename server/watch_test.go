@@ -0,0 +1,111 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIsFixture(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"fixtures/order.json", true},
+		{"fixtures/events.ndjson", true},
+		{"fixtures/order_gen.json", false},
+		{"fixtures/order_gen.go", false},
+		{"fixtures/notes.txt", false},
+	}
+
+	for _, tt := range tests {
+		if got := isFixture(tt.path); got != tt.want {
+			t.Errorf("isFixture(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestWatch(t *testing.T) {
+	var logBuf bytes.Buffer
+	log.SetOutput(&logBuf)
+	defer log.SetOutput(os.Stderr)
+
+	dir := t.TempDir()
+	fixture := filepath.Join(dir, "order.json")
+	if err := os.WriteFile(fixture, []byte(`{"id": 1}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := New(8080, "go", false, false)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- srv.Watch(ctx, dir) }()
+
+	out := filepath.Join(dir, "order_gen.go")
+	waitForFile(t, out, "type Order struct", "id float64 `json:\"id\"`")
+
+	if err := os.WriteFile(fixture, []byte(`{"id": 1, "total": 9.5}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	waitForFile(t, out, "total float64 `json:\"total\"`")
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+	if !strings.Contains(logBuf.String(), "Regenerated "+out+" from "+fixture) {
+		t.Errorf("expected regeneration to be logged\nGot: %s", logBuf.String())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "order_gen_gen.go")); err == nil {
+		t.Errorf("generated file was treated as a fixture")
+	}
+}
+
+func TestWatch_InvalidFixture(t *testing.T) {
+	var logBuf bytes.Buffer
+	log.SetOutput(&logBuf)
+	defer log.SetOutput(os.Stderr)
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "broken.json"), []byte(`{"id":`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := New(8080, "rust", false, false)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := srv.Watch(ctx, dir); err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+	if !strings.Contains(logBuf.String(), "Error regenerating "+filepath.Join(dir, "broken_gen.rs")) {
+		t.Errorf("expected the invalid fixture to be logged\nGot: %s", logBuf.String())
+	}
+}
+
+// waitForFile waits until the file at path contains every expected string.
+func waitForFile(t *testing.T, path string, expect ...string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		data, _ := os.ReadFile(path)
+		missing := ""
+		for _, e := range expect {
+			if !strings.Contains(string(data), e) {
+				missing = e
+				break
+			}
+		}
+		if missing == "" {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%s does not contain expected string: %s\nGot: %s", path, missing, data)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
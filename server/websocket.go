@@ -0,0 +1,135 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// websocketSource names WebSocket messages in generated code comments.
+const websocketSource = "WebSocket message"
+
+// websocketHandler accepts connections on /ws unless -paths leaves it out.
+// Browsers send the page's origin with the handshake, and it must match the
+// host being dialled, so other sites cannot feed messages into -capture or
+// -out. Clients other than browsers send no origin and are accepted. The
+// rate limiter, when there is one, counts each message as a request.
+func (s *Server) websocketHandler(limiter *rateLimiter) http.Handler {
+	return websocket.Server{
+		Handshake: func(config *websocket.Config, r *http.Request) error {
+			if !matchesPath(s.paths, r.URL.Path) {
+				return fmt.Errorf("path %s is not in -paths", r.URL.Path)
+			}
+			return checkOrigin(r)
+		},
+		Handler: func(ws *websocket.Conn) { s.handleWebSocket(ws, limiter) },
+	}
+}
+
+// checkOrigin rejects a handshake whose Origin header names a host other
+// than the one the request was sent to.
+func checkOrigin(r *http.Request) error {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return fmt.Errorf("invalid origin %q: %v", origin, err)
+	}
+	if !strings.EqualFold(u.Host, r.Host) {
+		return fmt.Errorf("origin %s does not match host %s", origin, r.Host)
+	}
+	return nil
+}
+
+// handleWebSocket replies to every message of a connection with the code
+// generated for the JSON values it holds, or with the error preventing it,
+// until the client closes the connection.
+func (s *Server) handleWebSocket(ws *websocket.Conn, limiter *rateLimiter) {
+	defer ws.Close()
+	id := requestID(ws.Request())
+	logger := requestLogger(id)
+	s.infof(logger, "WebSocket connection from %s", ws.Request().RemoteAddr)
+
+	// The read and write timeouts bound the handshake, not the connection
+	ws.SetDeadline(time.Time{})
+	if s.maxBodySize > 0 {
+		ws.MaxPayloadBytes = int(s.maxBodySize)
+	}
+
+	for {
+		var message []byte
+		err := websocket.Message.Receive(ws, &message)
+		if errors.Is(err, websocket.ErrFrameTooLarge) {
+			// The rest of the message was discarded, so the connection is
+			// still usable
+			if websocket.Message.Send(ws, s.bodyTooLargeMessage()) != nil {
+				return
+			}
+			continue
+		} else if err == io.EOF {
+			s.infof(logger, "WebSocket connection closed")
+			return
+		} else if err != nil {
+			logger.Printf("Error reading WebSocket message: %v", err)
+			return
+		}
+
+		reply := errRateLimited.Error()
+		if limiter == nil || limiter.allow(ws.Request().RemoteAddr) {
+			reply = s.formatMessage(logger, ws.Request(), message)
+		}
+		if err := websocket.Message.Send(ws, reply); err != nil {
+			logger.Printf("Error writing WebSocket message: %v", err)
+			return
+		}
+	}
+}
+
+// formatMessage generates code for a WebSocket message holding one JSON value
+// or several, as in NDJSON. The message goes through the same pipeline as an
+// NDJSON request body, so it is logged, checked, captured and written to the
+// output file alike, and takes a worker from the pool while formatted. It
+// returns the error text instead when the message cannot be formatted.
+func (s *Server) formatMessage(logger *log.Logger, handshake *http.Request, message []byte) string {
+	if len(bytes.TrimSpace(message)) == 0 {
+		reply := "Error parsing NDJSON: no value found"
+		logger.Print(reply)
+		return reply
+	}
+
+	if s.pool != nil {
+		if !s.pool.acquire(handshake.Context()) {
+			return errBusy.Error()
+		}
+		defer s.pool.release()
+	}
+
+	r, err := http.NewRequestWithContext(handshake.Context(), http.MethodPost, handshake.URL.String(), bytes.NewReader(message))
+	if err != nil {
+		return fmt.Sprintf("Error reading message: %v", err)
+	}
+	r.Header.Set("Content-Type", "application/x-ndjson")
+	r.RemoteAddr = handshake.RemoteAddr
+
+	// Messages are not HTTP operations, so they stay out of the OpenAPI spec
+	srv := *s
+	srv.spec = nil
+	formatted, _, reqErr := srv.processRequest(logger, r, websocketSource)
+	if reqErr != nil {
+		return reqErr.Error()
+	}
+	if formatted == "" {
+		return "Message processed successfully"
+	}
+	return formatted
+}
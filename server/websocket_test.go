@@ -0,0 +1,223 @@
+package server
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/websocket"
+)
+
+func dialWebSocket(t *testing.T, ts *httptest.Server) *websocket.Conn {
+	t.Helper()
+	url := "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws"
+	ws, err := websocket.Dial(url, "", ts.URL)
+	if err != nil {
+		t.Fatalf("websocket.Dial() error = %v", err)
+	}
+	return ws
+}
+
+func TestServer_WebSocket(t *testing.T) {
+	var logBuf strings.Builder
+	log.SetOutput(&logBuf)
+	defer log.SetOutput(os.Stderr)
+
+	srv := New(8080, "rust", false, false, WithWebSocket(true), WithMaxBodySize(64), WithGzip(true))
+	ts := httptest.NewServer(srv.httpServer().Handler)
+	defer ts.Close()
+	ws := dialWebSocket(t, ts)
+	defer ws.Close()
+
+	tests := []struct {
+		name           string
+		message        string
+		expectContains string
+	}{
+		{
+			name:           "JSON object",
+			message:        `{"id":1}`,
+			expectContains: "struct GeneratedStruct {\n    id: f64,\n}",
+		},
+		{
+			name:           "Several values",
+			message:        "{\"id\":1}\n{\"id\":2,\"name\":\"a\"}\n",
+			expectContains: "    name: Option<String>,\n",
+		},
+		{
+			name:           "Invalid JSON",
+			message:        `{"id":`,
+			expectContains: "Error parsing NDJSON: ",
+		},
+		{
+			name:           "Message too large",
+			message:        `{"description":"` + strings.Repeat("a", 100) + `"}`,
+			expectContains: "Request body exceeds 64 bytes",
+		},
+		{
+			name:           "Connection still usable",
+			message:        `{"ok":true}`,
+			expectContains: "    ok: bool,\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := websocket.Message.Send(ws, tt.message); err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+			var reply string
+			if err := websocket.Message.Receive(ws, &reply); err != nil {
+				t.Fatalf("Receive() error = %v", err)
+			}
+			if !strings.Contains(reply, tt.expectContains) {
+				t.Errorf("reply does not contain expected string: %s\nGot: %s", tt.expectContains, reply)
+			}
+		})
+	}
+
+	if !strings.Contains(logBuf.String(), `JSON-Body: {"id":1}`) {
+		t.Errorf("log does not contain the message body\nGot: %s", logBuf.String())
+	}
+}
+
+func TestServer_WebSocketChecks(t *testing.T) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	var captured bytes.Buffer
+	srv := New(8080, "go", false, false, WithWebSocket(true), WithStrictKeys(true), WithCapture(&captured))
+	ts := httptest.NewServer(srv.httpServer().Handler)
+	defer ts.Close()
+	ws := dialWebSocket(t, ts)
+	defer ws.Close()
+
+	tests := []struct {
+		name           string
+		message        string
+		expectContains string
+	}{
+		{
+			name:           "Duplicate keys refused",
+			message:        `{"id":1,"id":2}`,
+			expectContains: "Duplicate keys in JSON: id",
+		},
+		{
+			name:           "Empty message",
+			message:        " ",
+			expectContains: "Error parsing NDJSON: no value found",
+		},
+		{
+			name:           "Valid message",
+			message:        `{"name":"a"}`,
+			expectContains: "name string `json:\"name\"`",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := websocket.Message.Send(ws, tt.message); err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+			var reply string
+			if err := websocket.Message.Receive(ws, &reply); err != nil {
+				t.Fatalf("Receive() error = %v", err)
+			}
+			if !strings.Contains(reply, tt.expectContains) {
+				t.Errorf("reply does not contain expected string: %s\nGot: %s", tt.expectContains, reply)
+			}
+		})
+	}
+
+	if got, want := captured.String(), "{\"name\":\"a\"}\n"; got != want {
+		t.Errorf("captured = %q, want %q", got, want)
+	}
+}
+
+func TestServer_WebSocketRateLimit(t *testing.T) {
+	srv := New(8080, "go", false, false, WithQuiet(true), WithWebSocket(true), WithRateLimit(2, false))
+	ts := httptest.NewServer(srv.httpServer().Handler)
+	defer ts.Close()
+	// The handshake takes one request of the burst, leaving one message
+	ws := dialWebSocket(t, ts)
+	defer ws.Close()
+
+	for _, expect := range []string{"type GeneratedStruct struct", "Too many requests"} {
+		if err := websocket.Message.Send(ws, `{"id":1}`); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+		var reply string
+		if err := websocket.Message.Receive(ws, &reply); err != nil {
+			t.Fatalf("Receive() error = %v", err)
+		}
+		if !strings.Contains(reply, expect) {
+			t.Errorf("reply does not contain expected string: %s\nGot: %s", expect, reply)
+		}
+	}
+}
+
+func TestServer_WebSocketHandshake(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []Option
+		origin  func(ts *httptest.Server) string
+		wantErr bool
+	}{
+		{
+			name:   "Same origin",
+			origin: func(ts *httptest.Server) string { return ts.URL },
+		},
+		{
+			name:    "Other origin",
+			origin:  func(*httptest.Server) string { return "http://example.com" },
+			wantErr: true,
+		},
+		{
+			name:    "Outside -paths",
+			opts:    []Option{WithPaths([]string{"/api/*"})},
+			origin:  func(ts *httptest.Server) string { return ts.URL },
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := New(8080, "go", false, false, append([]Option{WithWebSocket(true), WithQuiet(true)}, tt.opts...)...)
+			ts := httptest.NewServer(srv.httpServer().Handler)
+			defer ts.Close()
+
+			url := "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws"
+			ws, err := websocket.Dial(url, "", tt.origin(ts))
+			if err == nil {
+				ws.Close()
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("websocket.Dial() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestServer_WebSocketDisabled(t *testing.T) {
+	srv := New(8080, "go", false, false)
+	ts := httptest.NewServer(srv.httpServer().Handler)
+	defer ts.Close()
+
+	url := "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws"
+	if ws, err := websocket.Dial(url, "", ts.URL); err == nil {
+		ws.Close()
+		t.Fatal("websocket.Dial() succeeded without WithWebSocket")
+	}
+
+	resp, err := http.Post(ts.URL+"/ws", "application/json", strings.NewReader(`{"id":1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("POST /ws returned %v, want %v", resp.StatusCode, http.StatusOK)
+	}
+}
@@ -0,0 +1,141 @@
+package server
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// formatAsXSD describes the payload as an XML Schema, with a complexType per
+// object and the root element typed by the struct name. The XML declaration
+// is left out, as it is optional and must otherwise precede the generated
+// code comment.
+func (s *Server) formatAsXSD(sch *schema) (string, error) {
+	root := sch
+	if sch.kind != kindObject {
+		root = &schema{kind: kindObject, fields: []*field{{name: "data", schema: sch}}}
+	}
+
+	types := s.nestedObjects(s.structName, root)
+	var b strings.Builder
+	b.WriteString("<xs:schema xmlns:xs=\"http://www.w3.org/2001/XMLSchema\" elementFormDefault=\"qualified\">\n")
+	fmt.Fprintf(&b, "  <xs:element name=\"%s\" type=\"%s\"/>\n", s.structName, s.structName)
+	for _, obj := range types.objects {
+		fmt.Fprintf(&b, "\n  <xs:complexType name=\"%s\">\n", obj.name)
+		writeXSDSequence(&b, obj.schema.fields, types, "    ")
+		b.WriteString("  </xs:complexType>\n")
+	}
+	b.WriteString("</xs:schema>")
+	return b.String(), nil
+}
+
+// writeXSDSequence writes the elements of an object's fields, in order.
+func writeXSDSequence(b *strings.Builder, fields []*field, types *objectTypes, indent string) {
+	if len(fields) == 0 {
+		fmt.Fprintf(b, "%s<xs:sequence/>\n", indent)
+		return
+	}
+	fmt.Fprintf(b, "%s<xs:sequence>\n", indent)
+	names := keyNames(fields, xmlName)
+	for i, f := range fields {
+		writeXSDElement(b, names[i], f.name, f.schema, f.optional, types, indent+"  ")
+	}
+	fmt.Fprintf(b, "%s</xs:sequence>\n", indent)
+}
+
+// writeXSDElement writes one element. Arrays become repeated elements, and an
+// array of arrays an anonymous type repeating item elements. Elements named
+// otherwise than their JSON key, such as the empty key or emoji keys, carry
+// the key in an annotation.
+func writeXSDElement(b *strings.Builder, name, key string, sch *schema, optional bool, types *objectTypes, indent string) {
+	attrs := fmt.Sprintf("name=%q", name)
+	elem := sch
+	if sch.kind == kindArray {
+		// Empty arrays have no elements at all
+		attrs += " minOccurs=\"0\" maxOccurs=\"unbounded\""
+		elem = sch.elem
+		if elem == nil {
+			elem = &schema{kind: kindMixed}
+		}
+	} else if optional {
+		attrs += " minOccurs=\"0\""
+	}
+	if elem.nullable || elem.kind == kindNull {
+		attrs += " nillable=\"true\""
+	}
+
+	if elem.kind == kindArray {
+		fmt.Fprintf(b, "%s<xs:element %s>\n", indent, attrs)
+		writeXSDAnnotation(b, name, key, indent+"  ")
+		fmt.Fprintf(b, "%s  <xs:complexType>\n", indent)
+		fmt.Fprintf(b, "%s    <xs:sequence>\n", indent)
+		writeXSDElement(b, "item", "item", elem, false, types, indent+"      ")
+		fmt.Fprintf(b, "%s    </xs:sequence>\n", indent)
+		fmt.Fprintf(b, "%s  </xs:complexType>\n", indent)
+		fmt.Fprintf(b, "%s</xs:element>\n", indent)
+		return
+	}
+	if name != key {
+		fmt.Fprintf(b, "%s<xs:element %s type=\"%s\">\n", indent, attrs, xsdType(elem, types))
+		writeXSDAnnotation(b, name, key, indent+"  ")
+		fmt.Fprintf(b, "%s</xs:element>\n", indent)
+		return
+	}
+	fmt.Fprintf(b, "%s<xs:element %s type=\"%s\"/>\n", indent, attrs, xsdType(elem, types))
+}
+
+// writeXSDAnnotation documents the JSON key of an element renamed from it.
+func writeXSDAnnotation(b *strings.Builder, name, key, indent string) {
+	if name == key {
+		return
+	}
+	fmt.Fprintf(b, "%s<xs:annotation>\n", indent)
+	fmt.Fprintf(b, "%s  <xs:documentation>JSON key: \"", indent)
+	xml.EscapeText(b, []byte(key))
+	b.WriteString("\"</xs:documentation>\n")
+	fmt.Fprintf(b, "%s</xs:annotation>\n", indent)
+}
+
+// xsdType names the type of a scalar or object schema.
+func xsdType(sch *schema, types *objectTypes) string {
+	switch sch.kind {
+	case kindBool:
+		return "xs:boolean"
+	case kindNumber:
+		if sch.integral() {
+			return "xs:integer"
+		}
+		return "xs:decimal"
+	case kindString:
+		return "xs:string"
+	case kindObject:
+		return types.name(sch)
+	default:
+		return "xs:anyType"
+	}
+}
+
+// xmlName turns a JSON key into an XML element name, replacing characters
+// that names cannot hold with underscores. Unlike identifiers, XML names may
+// contain hyphens and dots.
+func xmlName(key string) string {
+	var b strings.Builder
+	for i, r := range key {
+		switch {
+		case r == '_' || unicode.IsLetter(r):
+			b.WriteRune(r)
+		case i > 0 && (r == '-' || r == '.' || unicode.IsDigit(r)):
+			b.WriteRune(r)
+		case i == 0 && unicode.IsDigit(r):
+			b.WriteRune('_')
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	if b.Len() == 0 {
+		return "_"
+	}
+	return b.String()
+}
@@ -0,0 +1,75 @@
+package server
+
+import "testing"
+
+func TestFormatAsXSD(t *testing.T) {
+	testFormatCases(t, "xsd", []formatCase{
+		{
+			name: "Flat object",
+			data: map[string]interface{}{"name": "test", "value": 123.0, "ratio": 0.5, "active": true},
+			expectContains: []string{
+				"<xs:schema xmlns:xs=\"http://www.w3.org/2001/XMLSchema\" elementFormDefault=\"qualified\">\n" +
+					"  <xs:element name=\"GeneratedStruct\" type=\"GeneratedStruct\"/>\n\n" +
+					"  <xs:complexType name=\"GeneratedStruct\">\n" +
+					"    <xs:sequence>\n" +
+					"      <xs:element name=\"active\" type=\"xs:boolean\"/>\n" +
+					"      <xs:element name=\"name\" type=\"xs:string\"/>\n" +
+					"      <xs:element name=\"ratio\" type=\"xs:decimal\"/>\n" +
+					"      <xs:element name=\"value\" type=\"xs:integer\"/>\n" +
+					"    </xs:sequence>\n" +
+					"  </xs:complexType>\n" +
+					"</xs:schema>",
+			},
+		},
+		{
+			name: "Nested objects and arrays",
+			data: map[string]interface{}{
+				"user-info": map[string]interface{}{"id": 1.0},
+				"items":     []interface{}{map[string]interface{}{"sku": "a"}},
+				"tags":      []interface{}{"a"},
+				"grid":      []interface{}{[]interface{}{1.0}},
+			},
+			expectContains: []string{
+				"      <xs:element name=\"items\" minOccurs=\"0\" maxOccurs=\"unbounded\" type=\"Items\"/>\n",
+				"      <xs:element name=\"tags\" minOccurs=\"0\" maxOccurs=\"unbounded\" type=\"xs:string\"/>\n",
+				"      <xs:element name=\"user-info\" type=\"UserInfo\"/>\n",
+				"  <xs:complexType name=\"UserInfo\">\n    <xs:sequence>\n      <xs:element name=\"id\" type=\"xs:integer\"/>\n",
+				"  <xs:complexType name=\"Items\">\n",
+				"      <xs:element name=\"grid\" minOccurs=\"0\" maxOccurs=\"unbounded\">\n" +
+					"        <xs:complexType>\n" +
+					"          <xs:sequence>\n" +
+					"            <xs:element name=\"item\" minOccurs=\"0\" maxOccurs=\"unbounded\" type=\"xs:integer\"/>\n" +
+					"          </xs:sequence>\n" +
+					"        </xs:complexType>\n" +
+					"      </xs:element>\n",
+			},
+		},
+		{
+			name: "Optional and null fields",
+			records: []interface{}{
+				map[string]interface{}{"id": 1.0, "email": nil, "note": nil},
+				map[string]interface{}{"id": 2.0, "email": "x@example.com"},
+			},
+			expectContains: []string{
+				"      <xs:element name=\"email\" nillable=\"true\" type=\"xs:string\"/>\n",
+				"      <xs:element name=\"note\" minOccurs=\"0\" nillable=\"true\" type=\"xs:anyType\"/>\n",
+			},
+		},
+		{
+			name: "Keys that are not XML names",
+			data: map[string]interface{}{"2fa": true, "a b": 1.0, "a_b": "x", "": 3.0, "😀": "y"},
+			expectContains: []string{
+				"      <xs:element name=\"_2fa\" type=\"xs:boolean\">\n        <xs:annotation>\n          <xs:documentation>JSON key: \"2fa\"</xs:documentation>\n        </xs:annotation>\n      </xs:element>\n",
+				"      <xs:element name=\"a_b\" type=\"xs:string\"/>\n",
+				"      <xs:element name=\"a_b2\" type=\"xs:integer\">\n",
+				"      <xs:element name=\"_\" type=\"xs:integer\">\n        <xs:annotation>\n          <xs:documentation>JSON key: \"\"</xs:documentation>\n",
+				"      <xs:element name=\"_2\" type=\"xs:string\">\n        <xs:annotation>\n          <xs:documentation>JSON key: \"😀\"</xs:documentation>\n",
+			},
+		},
+		{
+			name:           "Non-object root",
+			data:           []interface{}{1.5},
+			expectContains: []string{"      <xs:element name=\"data\" minOccurs=\"0\" maxOccurs=\"unbounded\" type=\"xs:decimal\"/>\n"},
+		},
+	})
+}
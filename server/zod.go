@@ -0,0 +1,68 @@
+package server
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+func (s *Server) formatAsZod(sch *schema) (string, error) {
+	return fmt.Sprintf("import { z } from \"zod\";\n\nconst %[1]s = %[2]s;\n\ntype %[1]s = z.infer<typeof %[1]s>;", s.structName, s.getZodType(sch, "")), nil
+}
+
+// getZodType returns the validator for a schema. Nested objects are written
+// inline, indented one level past indent.
+func (s *Server) getZodType(sch *schema, indent string) string {
+	var zodType string
+	switch sch.kind {
+	case kindBool:
+		zodType = "z.boolean()"
+	case kindNumber:
+		zodType = "z.number()"
+	case kindString:
+		zodType = "z.string()"
+	case kindArray:
+		elemType := "z.unknown()"
+		if sch.elem != nil {
+			elemType = s.getZodType(sch.elem, indent)
+		}
+		zodType = fmt.Sprintf("z.array(%s)", elemType)
+	case kindObject:
+		zodType = s.generateZodObject(sch, indent)
+	case kindNull:
+		return "z.null()"
+	default:
+		zodType = "z.unknown()"
+	}
+	if sch.nullable {
+		return zodType + ".nullable()"
+	}
+	return zodType
+}
+
+func (s *Server) generateZodObject(sch *schema, indent string) string {
+	if len(sch.fields) == 0 {
+		return "z.object({})"
+	}
+
+	inner := indent + "  "
+	var result strings.Builder
+	result.WriteString("z.object({\n")
+	for _, f := range sch.fields {
+		fieldType := s.getZodType(f.schema, inner)
+		if f.optional {
+			fieldType += ".optional()"
+		}
+		fmt.Fprintf(&result, "%s%s: %s,\n", inner, zodKey(f.name), fieldType)
+	}
+	result.WriteString(indent + "})")
+	return result.String()
+}
+
+// zodKey quotes object keys that are not plain identifiers.
+func zodKey(name string) string {
+	if isIdentifier(name) {
+		return name
+	}
+	return strconv.Quote(name)
+}
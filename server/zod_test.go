@@ -0,0 +1,54 @@
+package server
+
+import "testing"
+
+func TestFormatAsZod(t *testing.T) {
+	testFormatCases(t, "zod", []formatCase{
+		{
+			name: "Flat object",
+			data: map[string]interface{}{"name": "test", "value": 123.0, "active": true},
+			expectContains: []string{
+				`import { z } from "zod";`,
+				"const GeneratedStruct = z.object({\n",
+				"  active: z.boolean(),\n",
+				"  name: z.string(),\n",
+				"  value: z.number(),\n",
+				"});",
+			},
+		},
+		{
+			name: "Nested objects and arrays",
+			data: map[string]interface{}{
+				"user":       map[string]interface{}{"id": 1.0},
+				"tags":       []interface{}{"a"},
+				"created-at": "2024-01-01",
+			},
+			expectContains: []string{
+				`  "created-at": z.string(),`,
+				"  tags: z.array(z.string()),",
+				"  user: z.object({\n    id: z.number(),\n  }),",
+			},
+		},
+		{
+			name: "Optional and null fields",
+			records: []interface{}{
+				map[string]interface{}{"id": 1.0, "email": nil, "deleted": nil},
+				map[string]interface{}{"id": 2.0, "email": "x@example.com"},
+			},
+			expectContains: []string{
+				"  deleted: z.null().optional(),",
+				"  email: z.string().nullable(),",
+				"  id: z.number(),",
+			},
+		},
+		{
+			name: "Keys that are not identifiers",
+			data: map[string]interface{}{"2fa": "x", "a b": "y", "class": 1.0},
+			expectContains: []string{
+				"  \"2fa\": z.string(),\n",
+				"  \"a b\": z.string(),\n",
+				"  class: z.number(),\n",
+			},
+		},
+	})
+}